@@ -17,13 +17,27 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"fmt"
+	"sort"
+	"strings"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 )
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
+const (
+	HookPhasePreProvision HookPhase = "PreProvision"
+	HookPhasePostReady    HookPhase = "PostReady"
+	HookPhasePreDelete    HookPhase = "PreDelete"
+
+	HookFailurePolicyAbort  HookFailurePolicy = "Abort"
+	HookFailurePolicyIgnore HookFailurePolicy = "Ignore"
+)
+
 // BlueprintSpec defines the desired state of Blueprint
 type BlueprintSpec struct {
 	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
@@ -33,9 +47,46 @@ type BlueprintSpec struct {
 	// +kubebuilder:validation:Required
 	ResourceNamePrefix string `json:"resourceNamePrefix"`
 
+	// Extends contains the name of a Blueprint, in the same namespace, to extend. Resource groups are merged by name and may override the template and parameters of, or remove, resource groups of the extended blueprint. The tenant selector and priority are not inherited
+	// +kubebuilder:validation:Optional
+	Extends string `json:"extends,omitempty"`
+
 	// Resources defines the resources groups used when generating tenant resource sets
-	// +kubebuilder:validation:Required
-	Resources []BlueprintResourceGroup `json:"resources"`
+	// +kubebuilder:validation:Optional
+	Resources []BlueprintResourceGroup `json:"resources,omitempty"`
+
+	// Hooks defines jobs to run at specific phases of the tenant lifecycle
+	// +kubebuilder:validation:Optional
+	Hooks []BlueprintHook `json:"hooks,omitempty"`
+
+	// DriftPolicy defines if resources that have drifted from the desired state are re-applied or only reported
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=AutoCorrect;ReportOnly
+	// +kubebuilder:default=AutoCorrect
+	DriftPolicy DriftPolicy `json:"driftPolicy,omitempty"`
+
+	// ApprovalPolicy defines if new ResourceSets are activated automatically or require manual approval
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Automatic;Manual
+	// +kubebuilder:default=Automatic
+	ApprovalPolicy ApprovalPolicy `json:"approvalPolicy,omitempty"`
+
+	// MaintenanceWindows defines the weekday and time ranges (e.g. MON-FRI 22:00-23:59 Europe/Stockholm) when new ResourceSets may be activated, new ResourceSets are activated immediately when empty
+	// +kubebuilder:validation:Optional
+	MaintenanceWindows []string `json:"maintenanceWindows,omitempty"`
+
+	// TenantSelector selects the tenants that use the blueprint when the tenant does not specify a blueprint
+	// +kubebuilder:validation:Optional
+	TenantSelector *metav1.LabelSelector `json:"tenantSelector,omitempty"`
+
+	// Priority defines the priority of the blueprint when multiple blueprints select the same tenant, highest priority wins
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=0
+	Priority int `json:"priority,omitempty"`
+
+	// OutputsConfigMap defines the name of a ConfigMap in the tenant namespace that the tenant outputs are mirrored to
+	// +kubebuilder:validation:Optional
+	OutputsConfigMap string `json:"outputsConfigMap,omitempty"`
 }
 
 // BlueprintResourceGroup defines a group of resources used when generating tenant resource sets
@@ -44,23 +95,204 @@ type BlueprintResourceGroup struct {
 	// +kubebuilder:validation:Required
 	Name string `json:"name"`
 
-	// Template defines the namespace/name of the template used to generate resources
+	// Template defines the namespace/name of the template used to generate resources, required unless the group overrides a group of an extended blueprint
+	// +kubebuilder:validation:Optional
+	Template string `json:"template,omitempty"`
+
+	// TemplateRevision pins the resource group to the name of a ResourceTemplateRevision of the template, the current spec of the template is used when empty
+	// +kubebuilder:validation:Optional
+	TemplateRevision string `json:"templateRevision,omitempty"`
+
+	// Parameters defines the parameters that applies to the template
+	// +kubebuilder:validation:Optional
+	Parameters []ParameterValue `json:"parameters,omitempty"`
+
+	// RetainPolicy defines if resources in the group are retained (orphaned) or deleted when removed from the tenant, overridden by the aeto.net/retain-policy annotation of templates and resources
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Retain;Delete
+	RetainPolicy RetainPolicy `json:"retainPolicy,omitempty"`
+
+	// Remove removes the resource group with the same name from the extended blueprint
+	// +kubebuilder:validation:Optional
+	Remove bool `json:"remove,omitempty"`
+
+	// Condition defines the tenant labels, annotations and parameter values required for the resource group to be generated
+	// +kubebuilder:validation:Optional
+	Condition *BlueprintResourceGroupCondition `json:"condition,omitempty"`
+
+	// DependsOn contains the names of resource groups that must be Ready before the resource group is generated for the first time. Resources without a ready condition or ready status field are Ready once they exist
+	// +kubebuilder:validation:Optional
+	DependsOn []string `json:"dependsOn,omitempty"`
+}
+
+// BlueprintResourceGroupStatus defines the observed state of a resource group
+type BlueprintResourceGroupStatus struct {
+	// Name is the name of the resource group.
+	Name string `json:"name"`
+
+	// Template is the name of the ResourceTemplate of the resource group.
+	Template string `json:"template"`
+
+	// Parameters is the parameters of the template and the values they resolve to.
+	Parameters []BlueprintParameterStatus `json:"parameters,omitempty"`
+}
+
+const (
+	// ParameterSourceBlueprint is the source of parameters set by the resource group of the blueprint
+	ParameterSourceBlueprint = "Blueprint"
+
+	// ParameterSourceDefault is the source of parameters resolving to the default value of the template
+	ParameterSourceDefault = "Default"
+
+	// ParameterSourceValueFrom is the source of parameters resolved from a value source of the resource group
+	ParameterSourceValueFrom = "ValueFrom"
+
+	// ParameterSourceTenant is the source of parameters that must be set by tenants
+	ParameterSourceTenant = "Tenant"
+)
+
+// BlueprintParameterStatus defines the value a template parameter resolves to
+type BlueprintParameterStatus struct {
+	// Name is the name of the parameter.
+	Name string `json:"name"`
+
+	// Value is the value of the parameter, empty when resolved per tenant.
+	Value string `json:"value,omitempty"`
+
+	// Source is where the value comes from, one of Blueprint, Default, ValueFrom or Tenant.
+	Source string `json:"source"`
+}
+
+// BlueprintResourceGroupCondition defines selectors matched against a tenant, all specified selectors must match
+type BlueprintResourceGroupCondition struct {
+	// Labels is a selector matched against the labels of the tenant
+	// +kubebuilder:validation:Optional
+	Labels *metav1.LabelSelector `json:"labels,omitempty"`
+
+	// Annotations is a selector matched against the annotations of the tenant
+	// +kubebuilder:validation:Optional
+	Annotations *metav1.LabelSelector `json:"annotations,omitempty"`
+
+	// Parameters is a selector matched against the parameter values of the tenant, parameters with an empty value are treated as missing
+	// +kubebuilder:validation:Optional
+	Parameters *metav1.LabelSelector `json:"parameters,omitempty"`
+}
+
+// ParameterNames returns the names of the parameters referenced by the parameters selector of the condition
+func (c BlueprintResourceGroupCondition) ParameterNames() []string {
+	names := make([]string, 0)
+	if c.Parameters == nil {
+		return names
+	}
+
+	seen := make(map[string]bool)
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	for name := range c.Parameters.MatchLabels {
+		add(name)
+	}
+	for _, e := range c.Parameters.MatchExpressions {
+		add(e.Key)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Matches returns true when all of the specified selectors of the condition matches the tenant labels, annotations and parameter values
+func (c BlueprintResourceGroupCondition) Matches(tenantLabels map[string]string, tenantAnnotations map[string]string, tenantParameters map[string]string) (bool, error) {
+	selectors := []struct {
+		selector *metav1.LabelSelector
+		set      map[string]string
+	}{
+		{c.Labels, tenantLabels},
+		{c.Annotations, tenantAnnotations},
+		{c.Parameters, tenantParameters},
+	}
+
+	for _, s := range selectors {
+		if s.selector == nil {
+			continue
+		}
+
+		selector, err := metav1.LabelSelectorAsSelector(s.selector)
+		if err != nil {
+			return false, err
+		}
+
+		if !selector.Matches(labels.Set(s.set)) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// BlueprintHook defines a job that runs at a specific phase of the tenant lifecycle
+type BlueprintHook struct {
+	// Name defines the name of the hook
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Phase defines the phase of the tenant lifecycle when the hook runs
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Enum=PreProvision;PostReady;PreDelete
+	Phase HookPhase `json:"phase"`
+
+	// Template defines the name of the template used to generate the Job of the hook
 	// +kubebuilder:validation:Required
 	Template string `json:"template"`
 
 	// Parameters defines the parameters that applies to the template
 	// +kubebuilder:validation:Optional
 	Parameters []ParameterValue `json:"parameters,omitempty"`
+
+	// FailurePolicy defines what happens when the hook fails, Abort blocks provisioning (PreProvision) or deletion (PreDelete) of the tenant
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Abort;Ignore
+	// +kubebuilder:default=Abort
+	FailurePolicy HookFailurePolicy `json:"failurePolicy,omitempty"`
 }
 
+type HookPhase string
+
+type HookFailurePolicy string
+
 // BlueprintStatus defines the observed state of Blueprint
 type BlueprintStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
+
+	// Revision is the name of the BlueprintRevision of the current spec.
+	Revision string `json:"revision,omitempty"`
+
+	// Ancestors is the names of the Blueprints extended by the Blueprint, nearest first.
+	Ancestors []string `json:"ancestors,omitempty"`
+
+	// Resolved is the spec of the Blueprint with the extended Blueprints merged into it.
+	Resolved *BlueprintSpec `json:"resolved,omitempty"`
+
+	// Tenants is the namespace/name of the Tenants using the Blueprint, directly, as an add-on or through a Blueprint extending it.
+	Tenants []string `json:"tenants,omitempty"`
+
+	// ResourceGroups is the resource groups of the resolved Blueprint and the parameters they resolve to.
+	ResourceGroups []BlueprintResourceGroupStatus `json:"resourceGroups,omitempty"`
+
+	// ValidationErrors is the errors found when validating the resolved Blueprint.
+	ValidationErrors []string `json:"validationErrors,omitempty"`
+
+	// Conditions represent the latest available observations of the Blueprint state.
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Extends",priority=1,type="string",JSONPath=".spec.extends",description="Extended Blueprint"
+//+kubebuilder:printcolumn:name="Revision",priority=1,type="string",JSONPath=".status.revision",description="Current revision"
+//+kubebuilder:printcolumn:name="Ready",priority=0,type="string",JSONPath=`.status.conditions[?(@.type == "Ready")].status`,description="Blueprint ready"
 
 // Blueprint is the Schema for the blueprints API
 type Blueprint struct {
@@ -97,11 +329,15 @@ func (b Blueprint) CommonLabels(tenant Tenant) map[string]string {
 	return labels
 }
 
-// CommonAnnotations merges annotations from tenant and blueprint with default annotations to create a common set
+// CommonAnnotations merges annotations from tenant and blueprint with default annotations to create a common set,
+// control annotations of the tenant are not part of the set
 func (b Blueprint) CommonAnnotations(tenant Tenant) map[string]string {
 	annotations := map[string]string{}
 
 	for k, v := range tenant.Annotations {
+		if IsControlAnnotation(k) {
+			continue
+		}
 		annotations[k] = v
 	}
 
@@ -116,6 +352,311 @@ func (b Blueprint) CommonAnnotations(tenant Tenant) map[string]string {
 	return annotations
 }
 
+// Selects returns true when the tenant selector of the blueprint matches the labels of the tenant
+func (b Blueprint) Selects(tenant Tenant) (bool, error) {
+	if b.Spec.TenantSelector == nil {
+		return false, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(b.Spec.TenantSelector)
+	if err != nil {
+		return false, err
+	}
+
+	return !selector.Empty() && selector.Matches(labels.Set(tenant.Labels)), nil
+}
+
+// OrderedResources returns the resource groups of the blueprint ordered so that resource groups are preceded by the
+// resource groups they depend on, otherwise keeping the order of the blueprint. An error is returned when a dependency
+// is not found or the dependencies contains a cycle.
+func (b Blueprint) OrderedResources() ([]BlueprintResourceGroup, error) {
+	names := make(map[string]bool)
+	for _, rg := range b.Spec.Resources {
+		names[rg.Name] = true
+	}
+	for _, rg := range b.Spec.Resources {
+		for _, d := range rg.DependsOn {
+			if !names[d] {
+				return nil, fmt.Errorf("resource group %s depends on %s which is not found in blueprint %s", rg.Name, d, b.Name)
+			}
+		}
+	}
+
+	ordered := make([]BlueprintResourceGroup, 0)
+	placed := make(map[string]bool)
+	for len(ordered) < len(b.Spec.Resources) {
+		progress := false
+		for _, rg := range b.Spec.Resources {
+			if placed[rg.Name] {
+				continue
+			}
+			ready := true
+			for _, d := range rg.DependsOn {
+				if !placed[d] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				ordered = append(ordered, rg)
+				placed[rg.Name] = true
+				progress = true
+				break
+			}
+		}
+
+		if !progress {
+			cycle := make([]string, 0)
+			for _, rg := range b.Spec.Resources {
+				if !placed[rg.Name] {
+					cycle = append(cycle, rg.Name)
+				}
+			}
+			return nil, fmt.Errorf("dependencies of resource groups %s in blueprint %s contain a cycle", strings.Join(cycle, ", "), b.Name)
+		}
+	}
+
+	return ordered, nil
+}
+
+// Selectable returns true when the blueprint can be selected for tenants without a blueprint specified
+func (b Blueprint) Selectable() bool {
+	return b.Spec.TenantSelector != nil || b.Name == DefaultBlueprint
+}
+
+// Descendants returns the blueprints extending, directly or indirectly, any of the named blueprints
+func (bl BlueprintList) Descendants(names ...string) []Blueprint {
+	descendants := make([]Blueprint, 0)
+	seen := append([]string{}, names...)
+	for i := 0; i < len(seen); i++ {
+		for _, b := range bl.Items {
+			if b.Spec.Extends != seen[i] {
+				continue
+			}
+			found := false
+			for _, s := range seen {
+				found = found || s == b.Name
+			}
+			if !found {
+				seen = append(seen, b.Name)
+				descendants = append(descendants, b)
+			}
+		}
+	}
+	return descendants
+}
+
+// BlueprintReferences returns the tenant blueprint references of the blueprints, including BlueprintReferenceSelector
+// when any of the blueprints can be selected for tenants without a blueprint specified
+func BlueprintReferences(blueprints ...Blueprint) []string {
+	names := make([]string, 0)
+	selectable := false
+	for _, b := range blueprints {
+		names = append(names, b.Name)
+		selectable = selectable || b.Selectable()
+	}
+	if selectable {
+		names = append(names, BlueprintReferenceSelector)
+	}
+	return names
+}
+
+// Templates returns the names of the resource templates used by the resource groups and hooks of the blueprint
+func (b Blueprint) Templates() []string {
+	templates := make([]string, 0)
+	add := func(name string) {
+		for _, t := range templates {
+			if t == name {
+				return
+			}
+		}
+		if name != "" {
+			templates = append(templates, name)
+		}
+	}
+	for _, rg := range b.Spec.Resources {
+		add(rg.Template)
+	}
+	for _, h := range b.Spec.Hooks {
+		add(h.Template)
+	}
+	return templates
+}
+
+// PhaseHooks returns the hooks of the blueprint that runs in the specified phase
+func (b Blueprint) PhaseHooks(phase HookPhase) []BlueprintHook {
+	hooks := make([]BlueprintHook, 0)
+	for _, h := range b.Spec.Hooks {
+		if h.Phase == phase {
+			hooks = append(hooks, h)
+		}
+	}
+	return hooks
+}
+
+// Compose merges the resource groups, hooks, labels and annotations of add-on blueprints, in order, into a copy of the blueprint
+func (b Blueprint) Compose(addOns ...Blueprint) (Blueprint, error) {
+	composed := *b.DeepCopy()
+
+	groups := make(map[string]string)
+	for _, rg := range composed.Spec.Resources {
+		groups[rg.Name] = b.Name
+	}
+	hooks := make(map[string]string)
+	for _, h := range composed.Spec.Hooks {
+		hooks[string(h.Phase)+"/"+h.Name] = b.Name
+	}
+
+	for _, addOn := range addOns {
+		for _, rg := range addOn.Spec.Resources {
+			if owner, ok := groups[rg.Name]; ok {
+				return Blueprint{}, fmt.Errorf("resource group %s of blueprint %s conflicts with resource group of blueprint %s", rg.Name, addOn.Name, owner)
+			}
+			groups[rg.Name] = addOn.Name
+			composed.Spec.Resources = append(composed.Spec.Resources, *rg.DeepCopy())
+		}
+
+		for _, h := range addOn.Spec.Hooks {
+			key := string(h.Phase) + "/" + h.Name
+			if owner, ok := hooks[key]; ok {
+				return Blueprint{}, fmt.Errorf("%s hook %s of blueprint %s conflicts with hook of blueprint %s", h.Phase, h.Name, addOn.Name, owner)
+			}
+			hooks[key] = addOn.Name
+			composed.Spec.Hooks = append(composed.Spec.Hooks, *h.DeepCopy())
+		}
+
+		if len(addOn.Labels) > 0 && composed.Labels == nil {
+			composed.Labels = make(map[string]string)
+		}
+		for k, v := range addOn.Labels {
+			composed.Labels[k] = v
+		}
+
+		if len(addOn.Annotations) > 0 && composed.Annotations == nil {
+			composed.Annotations = make(map[string]string)
+		}
+		for k, v := range addOn.Annotations {
+			composed.Annotations[k] = v
+		}
+	}
+
+	return composed, nil
+}
+
+// Extend merges the blueprint into a copy of the extended (parent) blueprint. Resource groups are merged by name, replacing
+// the template and overriding the parameters of the parent resource group, or removing it. Hooks are replaced by phase and name.
+func (b Blueprint) Extend(parent Blueprint) (Blueprint, error) {
+	extended := *b.DeepCopy()
+	spec := *parent.Spec.DeepCopy()
+
+	spec.ResourceNamePrefix = b.Spec.ResourceNamePrefix
+	spec.Extends = ""
+	spec.TenantSelector = b.Spec.TenantSelector.DeepCopy()
+	spec.Priority = b.Spec.Priority
+	if b.Spec.DriftPolicy != "" {
+		spec.DriftPolicy = b.Spec.DriftPolicy
+	}
+	if b.Spec.ApprovalPolicy != "" {
+		spec.ApprovalPolicy = b.Spec.ApprovalPolicy
+	}
+	if len(b.Spec.MaintenanceWindows) > 0 {
+		spec.MaintenanceWindows = b.Spec.MaintenanceWindows
+	}
+	if b.Spec.OutputsConfigMap != "" {
+		spec.OutputsConfigMap = b.Spec.OutputsConfigMap
+	}
+
+	for _, rg := range b.Spec.Resources {
+		index := -1
+		for i, prg := range spec.Resources {
+			if prg.Name == rg.Name {
+				index = i
+				break
+			}
+		}
+
+		if rg.Remove {
+			if index < 0 {
+				return Blueprint{}, fmt.Errorf("resource group %s of blueprint %s can not be removed, it is not found in blueprint %s", rg.Name, b.Name, parent.Name)
+			}
+			spec.Resources = append(spec.Resources[:index], spec.Resources[index+1:]...)
+			continue
+		}
+
+		if index < 0 {
+			if rg.Template == "" {
+				return Blueprint{}, fmt.Errorf("resource group %s of blueprint %s has no template", rg.Name, b.Name)
+			}
+			spec.Resources = append(spec.Resources, *rg.DeepCopy())
+			continue
+		}
+
+		if rg.Template != "" {
+			spec.Resources[index].Template = rg.Template
+			spec.Resources[index].TemplateRevision = rg.TemplateRevision
+		} else if rg.TemplateRevision != "" {
+			spec.Resources[index].TemplateRevision = rg.TemplateRevision
+		}
+		if rg.RetainPolicy != "" {
+			spec.Resources[index].RetainPolicy = rg.RetainPolicy
+		}
+		if rg.Condition != nil {
+			spec.Resources[index].Condition = rg.Condition.DeepCopy()
+		}
+		if len(rg.DependsOn) > 0 {
+			spec.Resources[index].DependsOn = rg.DependsOn
+		}
+		for _, pv := range rg.Parameters {
+			spec.Resources[index].Parameters = setParameterValue(spec.Resources[index].Parameters, pv)
+		}
+	}
+
+	for _, h := range b.Spec.Hooks {
+		replaced := false
+		for i, ph := range spec.Hooks {
+			if ph.Phase == h.Phase && ph.Name == h.Name {
+				spec.Hooks[i] = *h.DeepCopy()
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			spec.Hooks = append(spec.Hooks, *h.DeepCopy())
+		}
+	}
+
+	extended.Spec = spec
+
+	extended.Labels = make(map[string]string)
+	for k, v := range parent.Labels {
+		extended.Labels[k] = v
+	}
+	for k, v := range b.Labels {
+		extended.Labels[k] = v
+	}
+
+	extended.Annotations = make(map[string]string)
+	for k, v := range parent.Annotations {
+		extended.Annotations[k] = v
+	}
+	for k, v := range b.Annotations {
+		extended.Annotations[k] = v
+	}
+
+	return extended, nil
+}
+
+// setParameterValue replaces the parameter value with the same name or appends it to the list
+func setParameterValue(values []ParameterValue, value ParameterValue) []ParameterValue {
+	for i, v := range values {
+		if v.Name == value.Name {
+			values[i] = *value.DeepCopy()
+			return values
+		}
+	}
+	return append(values, *value.DeepCopy())
+}
+
 // NamespacedName returns a namespaced name for the custom resource
 func (b Blueprint) NamespacedName() types.NamespacedName {
 	return types.NamespacedName{
@@ -0,0 +1,392 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func testBlueprint(name string, groups ...string) Blueprint {
+	b := Blueprint{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+	}
+	for _, g := range groups {
+		b.Spec.Resources = append(b.Spec.Resources, BlueprintResourceGroup{Name: g, Template: g})
+	}
+	return b
+}
+
+func groupNames(groups []BlueprintResourceGroup) []string {
+	names := make([]string, 0)
+	for _, g := range groups {
+		names = append(names, g.Name)
+	}
+	return names
+}
+
+var _ = Describe("Blueprint", func() {
+	Describe("Compose", func() {
+		DescribeTable("resource groups",
+			func(base Blueprint, addOns []Blueprint, expected []string, expectedErr string) {
+				composed, err := base.Compose(addOns...)
+				if expectedErr != "" {
+					Expect(err).To(MatchError(ContainSubstring(expectedErr)))
+					return
+				}
+				Expect(err).NotTo(HaveOccurred())
+				Expect(groupNames(composed.Spec.Resources)).To(Equal(expected))
+			},
+			Entry("without add-ons", testBlueprint("base", "a", "b"), nil, []string{"a", "b"}, ""),
+			Entry("appends add-ons in order", testBlueprint("base", "a"), []Blueprint{testBlueprint("x", "b"), testBlueprint("y", "c")}, []string{"a", "b", "c"}, ""),
+			Entry("conflicting with the base", testBlueprint("base", "a"), []Blueprint{testBlueprint("x", "a")}, nil, "resource group a of blueprint x conflicts with resource group of blueprint base"),
+			Entry("conflicting between add-ons", testBlueprint("base", "a"), []Blueprint{testBlueprint("x", "b"), testBlueprint("y", "b")}, nil, "resource group b of blueprint y conflicts with resource group of blueprint x"),
+		)
+
+		It("should merge labels and annotations with add-ons taking precedence", func() {
+			base := testBlueprint("base")
+			base.Labels = map[string]string{"team": "a", "tier": "base"}
+			addOn := testBlueprint("x")
+			addOn.Labels = map[string]string{"tier": "addon"}
+			addOn.Annotations = map[string]string{"note": "x"}
+
+			composed, err := base.Compose(addOn)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(composed.Labels).To(Equal(map[string]string{"team": "a", "tier": "addon"}))
+			Expect(composed.Annotations).To(Equal(map[string]string{"note": "x"}))
+			Expect(base.Labels).To(Equal(map[string]string{"team": "a", "tier": "base"}))
+		})
+
+		It("should not allow hooks with the same phase and name", func() {
+			base := testBlueprint("base")
+			base.Spec.Hooks = []BlueprintHook{{Name: "migrate", Phase: HookPhasePreProvision}}
+			addOn := testBlueprint("x")
+			addOn.Spec.Hooks = []BlueprintHook{{Name: "migrate", Phase: HookPhasePreProvision}}
+
+			_, err := base.Compose(addOn)
+			Expect(err).To(MatchError(ContainSubstring("PreProvision hook migrate of blueprint x conflicts")))
+
+			addOn.Spec.Hooks[0].Phase = HookPhasePostReady
+			composed, err := base.Compose(addOn)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(composed.Spec.Hooks).To(HaveLen(2))
+		})
+	})
+
+	Describe("OrderedResources", func() {
+		dependsOn := func(b Blueprint, dependencies map[string][]string) Blueprint {
+			for i, rg := range b.Spec.Resources {
+				b.Spec.Resources[i].DependsOn = dependencies[rg.Name]
+			}
+			return b
+		}
+
+		DescribeTable("resource groups",
+			func(b Blueprint, expected []string, expectedErr string) {
+				ordered, err := b.OrderedResources()
+				if expectedErr != "" {
+					Expect(err).To(MatchError(ContainSubstring(expectedErr)))
+					return
+				}
+				Expect(err).NotTo(HaveOccurred())
+				Expect(groupNames(ordered)).To(Equal(expected))
+			},
+			Entry("without dependencies", testBlueprint("base", "a", "b", "c"), []string{"a", "b", "c"}, ""),
+			Entry("dependencies first", dependsOn(testBlueprint("base", "a", "b", "c"), map[string][]string{"a": {"c"}}), []string{"b", "c", "a"}, ""),
+			Entry("transitive dependencies", dependsOn(testBlueprint("base", "a", "b", "c"), map[string][]string{"a": {"b"}, "b": {"c"}}), []string{"c", "b", "a"}, ""),
+			Entry("missing dependency", dependsOn(testBlueprint("base", "a", "b"), map[string][]string{"a": {"x"}}), nil, "resource group a depends on x which is not found in blueprint base"),
+			Entry("cycle", dependsOn(testBlueprint("base", "a", "b", "c"), map[string][]string{"a": {"b"}, "b": {"a"}}), nil, "dependencies of resource groups a, b in blueprint base contain a cycle"),
+			Entry("self dependency", dependsOn(testBlueprint("base", "a"), map[string][]string{"a": {"a"}}), nil, "dependencies of resource groups a in blueprint base contain a cycle"),
+		)
+	})
+
+	Describe("CommonAnnotations", func() {
+		It("should leave out the control annotations of the tenant", func() {
+			tenant := Tenant{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+				"owner":                      "platform@example.com",
+				AnnotationDeletionProtection: "true",
+			}}}
+			blueprint := testBlueprint("base")
+			blueprint.Annotations = map[string]string{"tier": "base"}
+
+			Expect(blueprint.CommonAnnotations(tenant)).To(Equal(map[string]string{
+				"owner":               "platform@example.com",
+				"tier":                "base",
+				"aeto.net/controlled": "true",
+			}))
+		})
+	})
+
+	Describe("Extend", func() {
+		var parent Blueprint
+
+		BeforeEach(func() {
+			parent = testBlueprint("parent", "a", "b")
+			parent.Spec.ResourceNamePrefix = "parent"
+			parent.Spec.Priority = 10
+			parent.Spec.DriftPolicy = DriftPolicyReportOnly
+			parent.Spec.Resources[0].Parameters = []ParameterValue{{Name: "x", Value: "1"}, {Name: "y", Value: "1"}}
+			parent.Spec.Hooks = []BlueprintHook{{Name: "migrate", Phase: HookPhasePreProvision, Template: "parent"}}
+			parent.Labels = map[string]string{"team": "a", "tier": "parent"}
+		})
+
+		DescribeTable("resource groups",
+			func(groups []BlueprintResourceGroup, expected []string, expectedErr string) {
+				child := testBlueprint("child")
+				child.Spec.Resources = groups
+
+				extended, err := child.Extend(parent)
+				if expectedErr != "" {
+					Expect(err).To(MatchError(ContainSubstring(expectedErr)))
+					return
+				}
+				Expect(err).NotTo(HaveOccurred())
+				Expect(groupNames(extended.Spec.Resources)).To(Equal(expected))
+			},
+			Entry("inherits the groups of the parent", nil, []string{"a", "b"}, ""),
+			Entry("appends new groups", []BlueprintResourceGroup{{Name: "c", Template: "c"}}, []string{"a", "b", "c"}, ""),
+			Entry("overrides groups in place", []BlueprintResourceGroup{{Name: "c", Template: "c"}, {Name: "a", Template: "other"}}, []string{"a", "b", "c"}, ""),
+			Entry("removes groups", []BlueprintResourceGroup{{Name: "a", Remove: true}}, []string{"b"}, ""),
+			Entry("removing a group not in the parent", []BlueprintResourceGroup{{Name: "c", Remove: true}}, nil, "resource group c of blueprint child can not be removed, it is not found in blueprint parent"),
+			Entry("new group without template", []BlueprintResourceGroup{{Name: "c"}}, nil, "resource group c of blueprint child has no template"),
+		)
+
+		It("should override the template and parameters of a group", func() {
+			child := testBlueprint("child")
+			child.Spec.Resources = []BlueprintResourceGroup{
+				{Name: "a", Parameters: []ParameterValue{{Name: "y", Value: "2"}, {Name: "z", Value: "2"}}},
+				{Name: "b", Template: "other"},
+			}
+
+			extended, err := child.Extend(parent)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(extended.Spec.Resources[0].Template).To(Equal("a"))
+			Expect(extended.Spec.Resources[0].Parameters).To(Equal([]ParameterValue{{Name: "x", Value: "1"}, {Name: "y", Value: "2"}, {Name: "z", Value: "2"}}))
+			Expect(extended.Spec.Resources[1].Template).To(Equal("other"))
+			Expect(parent.Spec.Resources[0].Parameters).To(HaveLen(2))
+		})
+
+		It("should not inherit the name prefix, tenant selector and priority", func() {
+			child := testBlueprint("child")
+			child.Spec.ResourceNamePrefix = "child"
+
+			extended, err := child.Extend(parent)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(extended.Name).To(Equal("child"))
+			Expect(extended.Spec.ResourceNamePrefix).To(Equal("child"))
+			Expect(extended.Spec.Priority).To(Equal(0))
+			Expect(extended.Spec.DriftPolicy).To(Equal(DriftPolicyReportOnly))
+		})
+
+		It("should override the retain policy of a group", func() {
+			parent.Spec.Resources[0].RetainPolicy = RetainPolicyRetain
+			child := testBlueprint("child")
+			child.Spec.Resources = []BlueprintResourceGroup{
+				{Name: "a", Parameters: []ParameterValue{{Name: "y", Value: "2"}}},
+				{Name: "b", RetainPolicy: RetainPolicyRetain},
+			}
+
+			extended, err := child.Extend(parent)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(extended.Spec.Resources[0].RetainPolicy).To(Equal(RetainPolicyRetain))
+			Expect(extended.Spec.Resources[1].RetainPolicy).To(Equal(RetainPolicyRetain))
+			Expect(parent.Spec.Resources[1].RetainPolicy).To(BeEmpty())
+		})
+
+		It("should replace hooks by phase and name and merge labels", func() {
+			child := testBlueprint("child")
+			child.Spec.Hooks = []BlueprintHook{
+				{Name: "migrate", Phase: HookPhasePreProvision, Template: "child"},
+				{Name: "migrate", Phase: HookPhasePostReady, Template: "child"},
+			}
+			child.Labels = map[string]string{"tier": "child"}
+
+			extended, err := child.Extend(parent)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(extended.Spec.Hooks).To(Equal(child.Spec.Hooks))
+			Expect(extended.Labels).To(Equal(map[string]string{"team": "a", "tier": "child"}))
+		})
+	})
+})
+
+var _ = Describe("BlueprintList", func() {
+	extends := func(b Blueprint, parent string) Blueprint {
+		b.Spec.Extends = parent
+		return b
+	}
+	selecting := func(b Blueprint) Blueprint {
+		b.Spec.TenantSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"tier": b.Name}}
+		return b
+	}
+	names := func(blueprints []Blueprint) []string {
+		n := make([]string, 0)
+		for _, b := range blueprints {
+			n = append(n, b.Name)
+		}
+		return n
+	}
+
+	list := BlueprintList{Items: []Blueprint{
+		testBlueprint("base", "namespace"),
+		extends(testBlueprint("basic", "quota"), "base"),
+		selecting(extends(testBlueprint("premium"), "basic")),
+		extends(testBlueprint("enterprise"), "premium"),
+		testBlueprint("monitoring", "prometheus"),
+		extends(testBlueprint("cycle-a"), "cycle-b"),
+		extends(testBlueprint("cycle-b"), "cycle-a"),
+	}}
+
+	DescribeTable("Descendants",
+		func(blueprints []string, expected []string) {
+			Expect(names(list.Descendants(blueprints...))).To(Equal(expected))
+		},
+		Entry("no descendants", []string{"monitoring"}, []string{}),
+		Entry("direct and indirect descendants", []string{"base"}, []string{"basic", "premium", "enterprise"}),
+		Entry("descendants of several blueprints", []string{"premium", "monitoring"}, []string{"enterprise"}),
+		Entry("named blueprints are not descendants", []string{"basic", "premium"}, []string{"enterprise"}),
+		Entry("cycles", []string{"cycle-a"}, []string{"cycle-b"}),
+	)
+
+	DescribeTable("BlueprintReferences",
+		func(blueprints []Blueprint, expected []string) {
+			Expect(BlueprintReferences(blueprints...)).To(Equal(expected))
+		},
+		Entry("no blueprints", []Blueprint{}, []string{}),
+		Entry("blueprints that can not be selected", []Blueprint{testBlueprint("basic"), testBlueprint("monitoring")}, []string{"basic", "monitoring"}),
+		Entry("default blueprint", []Blueprint{testBlueprint(DefaultBlueprint)}, []string{DefaultBlueprint, BlueprintReferenceSelector}),
+		Entry("blueprint with tenant selector", []Blueprint{testBlueprint("basic"), selecting(testBlueprint("premium"))}, []string{"basic", "premium", BlueprintReferenceSelector}),
+	)
+
+	Describe("tenants affected by changes", func() {
+		tenants := []Tenant{
+			{ObjectMeta: metav1.ObjectMeta{Name: "uses-base"}, Spec: TenantSpec{Blueprint: "base"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "uses-enterprise"}, Spec: TenantSpec{Blueprint: "enterprise"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "uses-monitoring"}, Spec: TenantSpec{Blueprint: "base", AddOns: []string{"monitoring"}}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "selected"}, Status: TenantStatus{Blueprint: "aeto/premium"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "changed"}, Spec: TenantSpec{Blueprint: "monitoring"}, Status: TenantStatus{Blueprint: "aeto/basic"}},
+		}
+
+		// affected returns the tenants indexed by any of the references, like the tenant blueprint field index
+		affected := func(references []string) []string {
+			matches := make([]string, 0)
+			for _, t := range tenants {
+				for _, ref := range t.BlueprintReferences() {
+					found := false
+					for _, r := range references {
+						found = found || r == ref
+					}
+					if found {
+						matches = append(matches, t.Name)
+						break
+					}
+				}
+			}
+			return matches
+		}
+
+		DescribeTable("blueprint changes",
+			func(name string, expected []string) {
+				var changed Blueprint
+				for _, b := range list.Items {
+					if b.Name == name {
+						changed = b
+					}
+				}
+				Expect(affected(BlueprintReferences(append([]Blueprint{changed}, list.Descendants(name)...)...))).To(Equal(expected))
+			},
+			Entry("blueprint extended by others", "base", []string{"uses-base", "uses-enterprise", "uses-monitoring", "selected", "changed"}),
+			Entry("selectable blueprint", "premium", []string{"uses-enterprise", "selected"}),
+			Entry("add-on", "monitoring", []string{"uses-monitoring", "changed"}),
+			Entry("blueprint previously in use", "basic", []string{"uses-enterprise", "selected", "changed"}),
+		)
+
+		It("should include the tenants using blueprints extending a changed template", func() {
+			using := []string{}
+			for _, b := range list.Items {
+				for _, t := range b.Templates() {
+					if t == "quota" {
+						using = append(using, b.Name)
+					}
+				}
+			}
+			references := append(using, names(list.Descendants(using...))...)
+			Expect(affected(references)).To(Equal([]string{"uses-enterprise", "selected", "changed"}))
+		})
+	})
+})
+
+var _ = Describe("BlueprintResourceGroupCondition", func() {
+	tenantLabels := map[string]string{"tier": "premium"}
+	tenantAnnotations := map[string]string{"aeto.net/region": "eu"}
+	tenantParameters := map[string]string{"Database": "postgres", "Replicas": ""}
+
+	DescribeTable("Matches",
+		func(condition BlueprintResourceGroupCondition, expected bool, expectedErr string) {
+			matches, err := condition.Matches(tenantLabels, tenantAnnotations, tenantParameters)
+			if expectedErr != "" {
+				Expect(err).To(MatchError(ContainSubstring(expectedErr)))
+				return
+			}
+			Expect(err).NotTo(HaveOccurred())
+			Expect(matches).To(Equal(expected))
+		},
+		Entry("without selectors", BlueprintResourceGroupCondition{}, true, ""),
+		Entry("matching labels", BlueprintResourceGroupCondition{
+			Labels: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "premium"}},
+		}, true, ""),
+		Entry("not matching labels", BlueprintResourceGroupCondition{
+			Labels: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "basic"}},
+		}, false, ""),
+		Entry("matching annotations", BlueprintResourceGroupCondition{
+			Annotations: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "aeto.net/region", Operator: metav1.LabelSelectorOpIn, Values: []string{"eu", "us"}},
+			}},
+		}, true, ""),
+		Entry("matching parameters", BlueprintResourceGroupCondition{
+			Parameters: &metav1.LabelSelector{MatchLabels: map[string]string{"Database": "postgres"}},
+		}, true, ""),
+		Entry("all selectors must match", BlueprintResourceGroupCondition{
+			Labels:     &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "premium"}},
+			Parameters: &metav1.LabelSelector{MatchLabels: map[string]string{"Database": "mysql"}},
+		}, false, ""),
+		Entry("invalid selector", BlueprintResourceGroupCondition{
+			Labels: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "tier", Operator: "Unknown"},
+			}},
+		}, false, "is not a valid pod selector operator"),
+	)
+
+	DescribeTable("ParameterNames",
+		func(condition BlueprintResourceGroupCondition, expected []string) {
+			Expect(condition.ParameterNames()).To(Equal(expected))
+		},
+		Entry("without parameters selector", BlueprintResourceGroupCondition{
+			Labels: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "premium"}},
+		}, []string{}),
+		Entry("sorted and unique names", BlueprintResourceGroupCondition{
+			Parameters: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"Replicas": "1", "Database": "postgres"},
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "Database", Operator: metav1.LabelSelectorOpExists},
+					{Key: "Cache", Operator: metav1.LabelSelectorOpDoesNotExist},
+				},
+			},
+		}, []string{"Cache", "Database", "Replicas"}),
+	)
+})
@@ -0,0 +1,146 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// BlueprintPreviewSpec defines the desired state of BlueprintPreview
+type BlueprintPreviewSpec struct {
+	// Blueprint contains the name of the Blueprint to preview changes of, all tenants are previewed when empty
+	// +kubebuilder:validation:Optional
+	Blueprint string `json:"blueprint,omitempty"`
+
+	// Proposed defines the proposed spec of the Blueprint, the current spec of the Blueprint is used when empty
+	// +kubebuilder:validation:Optional
+	Proposed *BlueprintSpec `json:"proposed,omitempty"`
+
+	// ResourceTemplates defines proposed ResourceTemplates, replacing the ResourceTemplates with the same name
+	// +kubebuilder:validation:Optional
+	ResourceTemplates []BlueprintPreviewResourceTemplate `json:"resourceTemplates,omitempty"`
+}
+
+// BlueprintPreviewResourceTemplate defines the proposed spec of a ResourceTemplate
+type BlueprintPreviewResourceTemplate struct {
+	// Name contains the name of the ResourceTemplate
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Spec defines the proposed spec of the ResourceTemplate
+	// +kubebuilder:validation:Required
+	Spec ResourceTemplateSpec `json:"spec"`
+}
+
+// BlueprintPreviewStatus defines the observed state of BlueprintPreview
+type BlueprintPreviewStatus struct {
+	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
+	// Important: Run "make" to regenerate code after modifying this file
+
+	// ObservedGeneration is the last previewed generation.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Tenants is the preview of the changes to the resources of each affected Tenant.
+	Tenants []BlueprintPreviewTenantStatus `json:"tenants,omitempty"`
+
+	// Status is the current phase of the BlueprintPreview.
+	Status string `json:"status,omitempty"`
+
+	// Conditions represent the latest available observations of the BlueprintPreview state.
+	Conditions []metav1.Condition `json:"conditions"`
+}
+
+// BlueprintPreviewTenantStatus defines the changes to the resources of a Tenant
+type BlueprintPreviewTenantStatus struct {
+	// Tenant is the namespace/name of the Tenant.
+	Tenant string `json:"tenant"`
+
+	// Added lists the resources that would be added.
+	Added []BlueprintPreviewResourceStatus `json:"added,omitempty"`
+
+	// Updated lists the resources that would be updated.
+	Updated []BlueprintPreviewResourceStatus `json:"updated,omitempty"`
+
+	// Removed lists the resources that would be removed.
+	Removed []BlueprintPreviewResourceStatus `json:"removed,omitempty"`
+
+	// Error holds the error encountered when generating the resources of the Tenant.
+	Error string `json:"error,omitempty"`
+}
+
+// BlueprintPreviewResourceStatus defines a change to a Tenant resource
+type BlueprintPreviewResourceStatus struct {
+	// Resource is the kind and namespace/name of the resource.
+	Resource string `json:"resource"`
+
+	// Diff contains the changes to the manifest of the resource.
+	Diff []string `json:"diff,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Blueprint",priority=0,type="string",JSONPath=".spec.blueprint",description="Blueprint name"
+//+kubebuilder:printcolumn:name="Status",priority=0,type="string",JSONPath=".status.status",description="Preview phase"
+//+kubebuilder:printcolumn:name="Ready",priority=0,type="string",JSONPath=`.status.conditions[?(@.type == "Ready")].message`,description="Preview ready"
+
+// BlueprintPreview is the Schema for the blueprintpreviews API
+type BlueprintPreview struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   BlueprintPreviewSpec   `json:"spec,omitempty"`
+	Status BlueprintPreviewStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// BlueprintPreviewList contains a list of BlueprintPreview
+type BlueprintPreviewList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []BlueprintPreview `json:"items"`
+}
+
+// NamespacedName returns a namespaced name for the custom resource
+func (bp BlueprintPreview) NamespacedName() types.NamespacedName {
+	return types.NamespacedName{
+		Namespace: bp.Namespace,
+		Name:      bp.Name,
+	}
+}
+
+// AffectedByBlueprint returns true when changes to the Blueprint affect the preview, a Blueprint replaced by its
+// proposed spec does not
+func (bp BlueprintPreview) AffectedByBlueprint(name string) bool {
+	return bp.Spec.Proposed == nil || bp.Spec.Blueprint != name
+}
+
+// AffectedByResourceTemplate returns true when changes to the ResourceTemplate affect the preview, a ResourceTemplate
+// replaced by a proposed ResourceTemplate does not
+func (bp BlueprintPreview) AffectedByResourceTemplate(name string) bool {
+	for _, rt := range bp.Spec.ResourceTemplates {
+		if rt.Name == name {
+			return false
+		}
+	}
+	return true
+}
+
+func init() {
+	SchemeBuilder.Register(&BlueprintPreview{}, &BlueprintPreviewList{})
+}
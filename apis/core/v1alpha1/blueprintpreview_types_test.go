@@ -0,0 +1,54 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("BlueprintPreview", func() {
+	preview := func(blueprint string, proposed bool, templates ...string) BlueprintPreview {
+		bp := BlueprintPreview{Spec: BlueprintPreviewSpec{Blueprint: blueprint}}
+		if proposed {
+			bp.Spec.Proposed = &BlueprintSpec{}
+		}
+		for _, name := range templates {
+			bp.Spec.ResourceTemplates = append(bp.Spec.ResourceTemplates, BlueprintPreviewResourceTemplate{Name: name})
+		}
+		return bp
+	}
+
+	DescribeTable("AffectedByBlueprint",
+		func(bp BlueprintPreview, name string, expected bool) {
+			Expect(bp.AffectedByBlueprint(name)).To(Equal(expected))
+		},
+		Entry("all tenants", preview("", false), "default", true),
+		Entry("current spec of the blueprint", preview("default", false), "default", true),
+		Entry("proposed spec of the blueprint", preview("default", true), "default", false),
+		Entry("other blueprint", preview("default", true), "base", true),
+	)
+
+	DescribeTable("AffectedByResourceTemplate",
+		func(bp BlueprintPreview, name string, expected bool) {
+			Expect(bp.AffectedByResourceTemplate(name)).To(Equal(expected))
+		},
+		Entry("no proposed templates", preview("default", false), "namespace", true),
+		Entry("proposed template", preview("default", false, "namespace"), "namespace", false),
+		Entry("other template", preview("default", false, "namespace"), "quota", true),
+	)
+})
@@ -17,6 +17,8 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"fmt"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 )
@@ -34,6 +36,9 @@ const (
 
 	// ResourceSetPaused means the resource set reconciliation has been paused
 	ResourceSetPaused ResourceSetPhase = "Paused"
+
+	// ResourceSetPendingApproval means the resource set must be approved before it is activated
+	ResourceSetPendingApproval ResourceSetPhase = "PendingApproval"
 )
 
 // ResourceSetSpec defines the desired state of ResourceSet
@@ -47,6 +52,15 @@ type ResourceSetSpec struct {
 	// Resources contains embedded resources
 	// +kubebuilder:validation:Required
 	Resources ResourceSetResourceList `json:"resources"`
+
+	// DriftPolicy defines if resources that have drifted from the desired state are re-applied or only reported
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=AutoCorrect;ReportOnly
+	DriftPolicy DriftPolicy `json:"driftPolicy,omitempty"`
+
+	// ApprovalRequired is true when the ResourceSet must be approved, using the approved-by annotation, before it is activated
+	// +kubebuilder:validation:Optional
+	ApprovalRequired bool `json:"approvalRequired,omitempty"`
 }
 
 // ResourceSetResourceList defines a list of resources in a ResourceSet
@@ -65,6 +79,60 @@ type ResourceSetResource struct {
 	// Embedded holds an embedded kubernetes resource
 	// +kubebuilder:validation:Required
 	Embedded EmbeddedResource `json:"embedded"`
+
+	// Sensitive is true when the resource is rendered from a template referencing secret parameters, changes to the
+	// resource are reported without values
+	// +kubebuilder:validation:Optional
+	Sensitive bool `json:"sensitive,omitempty"`
+
+	// Source holds the template a sensitive resource is rendered from when it is applied, the embedded resource of a
+	// sensitive resource only holds its kind, name, namespace, labels and annotations
+	// +kubebuilder:validation:Optional
+	Source *ResourceSource `json:"source,omitempty"`
+}
+
+// ResourceSource defines the template and template data a resource is rendered from. Parameter values read from
+// Secrets, or generated, are given as references ($(aeto:secret:<namespace>/<name>/<key>)) resolved when rendered.
+type ResourceSource struct {
+	// Template is the name of the ResourceTemplate
+	Template string `json:"template"`
+
+	// Revision is the revision of the ResourceTemplate
+	// +kubebuilder:validation:Optional
+	Revision string `json:"revision,omitempty"`
+
+	// Documents contains the raw templates and resources of the ResourceTemplate, in order
+	// +kubebuilder:validation:Optional
+	Documents []string `json:"documents,omitempty"`
+
+	// Helm defines the helm chart of the ResourceTemplate
+	// +kubebuilder:validation:Optional
+	Helm *HelmChartSource `json:"helm,omitempty"`
+
+	// Index is the position of the resource among the resources rendered from the template
+	Index int `json:"index"`
+
+	// Tenant holds the tenant names, namespaces, labels and annotations of the template data
+	Tenant ResourceSourceTenant `json:"tenant"`
+
+	// Parameters defines the parameters of the ResourceTemplate
+	// +kubebuilder:validation:Optional
+	Parameters ResourceTemplateParameterList `json:"parameters,omitempty"`
+
+	// Values holds the values of the parameters
+	// +kubebuilder:validation:Optional
+	Values []ParameterValue `json:"values,omitempty"`
+}
+
+// ResourceSourceTenant defines the tenant part of the template data of a resource
+type ResourceSourceTenant struct {
+	Name              string            `json:"name"`
+	PrefixedName      string            `json:"prefixedName"`
+	FullName          string            `json:"fullName,omitempty"`
+	Namespace         string            `json:"namespace"`
+	OperatorNamespace string            `json:"operatorNamespace"`
+	Labels            map[string]string `json:"labels,omitempty"`
+	Annotations       map[string]string `json:"annotations,omitempty"`
 }
 
 // ResourceSetStatus defines the observed state of ResourceSet
@@ -83,6 +151,44 @@ type ResourceSetStatus struct {
 
 	// Conditions represent the latest available observations of the ResourceSet state.
 	Conditions []metav1.Condition `json:"conditions"`
+
+	// Resources represent the latest available observations of each resource in the ResourceSet.
+	// +kubebuilder:validation:Optional
+	Resources ResourceSetResourceStatusList `json:"resources,omitempty"`
+
+	// Diff contains the changes compared to the active ResourceSet of the tenant while the ResourceSet is pending approval.
+	// +kubebuilder:validation:Optional
+	Diff []string `json:"diff,omitempty"`
+}
+
+// ResourceSetResourceStatusList defines a list of resource statuses in a ResourceSet
+type ResourceSetResourceStatusList []ResourceSetResourceStatus
+
+// ResourceSetResourceStatus defines the observed state of a resource in a ResourceSet
+type ResourceSetResourceStatus struct {
+	// Id holds the unique identifier of the resource
+	Id string `json:"id"`
+
+	// Kind is the kind of the resource
+	Kind string `json:"kind"`
+
+	// Namespace is the namespace of the resource
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name is the name of the resource
+	Name string `json:"name"`
+
+	// Applied is true when the resource was successfully applied during the last reconcile
+	Applied bool `json:"applied"`
+
+	// Ready is the ready state of the resource, Unknown when readiness could not be determined
+	Ready metav1.ConditionStatus `json:"ready"`
+
+	// Error holds the last apply or readiness error of the resource
+	Error string `json:"error,omitempty"`
+
+	// Drift holds the paths of the fields that have drifted from the desired state
+	Drift []string `json:"drift,omitempty"`
 }
 
 type ResourceSetPhase string
@@ -135,6 +241,61 @@ func (rl ResourceSetResourceList) Find(id string) (index int, existing *Resource
 	return
 }
 
+// SetReady sets the ready state of the resource status at the specified index, keeping any previously recorded apply error
+func (rsl ResourceSetResourceStatusList) SetReady(index int, checked bool, ready bool, err error) {
+	if index < 0 || index >= len(rsl) {
+		return
+	}
+
+	rsl[index].Ready = metav1.ConditionUnknown
+	if checked && ready {
+		rsl[index].Ready = metav1.ConditionTrue
+	} else if checked {
+		rsl[index].Ready = metav1.ConditionFalse
+	}
+
+	if err != nil && rsl[index].Error == "" {
+		rsl[index].Error = err.Error()
+	}
+}
+
+// Summarize returns a summary of the resource statuses, resources that failed to apply, are not ready or have drifted
+// are listed by kind and name
+func (rsl ResourceSetResourceStatusList) Summarize() *TenantResourcesStatus {
+	summary := &TenantResourcesStatus{
+		Total: len(rsl),
+	}
+
+	for _, r := range rsl {
+		if r.Applied {
+			summary.Applied++
+		}
+		if r.Ready == metav1.ConditionTrue {
+			summary.Ready++
+		}
+		if len(r.Drift) > 0 {
+			summary.Drifted = append(summary.Drifted, r.String())
+		}
+		if (!r.Applied && len(r.Drift) == 0) || r.Ready == metav1.ConditionFalse || r.Error != "" {
+			entry := r.String()
+			if r.Error != "" {
+				entry = fmt.Sprintf("%s: %s", entry, r.Error)
+			}
+			summary.NotReady = append(summary.NotReady, entry)
+		}
+	}
+
+	return summary
+}
+
+// String returns the kind, namespace and name of the resource
+func (rs ResourceSetResourceStatus) String() string {
+	if rs.Namespace != "" {
+		return fmt.Sprintf("%s %s/%s", rs.Kind, rs.Namespace, rs.Name)
+	}
+	return fmt.Sprintf("%s %s", rs.Kind, rs.Name)
+}
+
 func init() {
 	SchemeBuilder.Register(&ResourceSet{}, &ResourceSetList{})
 }
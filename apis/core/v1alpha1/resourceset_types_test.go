@@ -0,0 +1,81 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("ResourceSetResourceStatusList", func() {
+	status := func(name string, applied bool, ready metav1.ConditionStatus, err string, drift ...string) ResourceSetResourceStatus {
+		return ResourceSetResourceStatus{Id: name, Kind: "ConfigMap", Namespace: "acme", Name: name, Applied: applied, Ready: ready, Error: err, Drift: drift}
+	}
+
+	DescribeTable("SetReady",
+		func(applyErr string, checked bool, ready bool, err error, expectedReady metav1.ConditionStatus, expectedErr string) {
+			rsl := ResourceSetResourceStatusList{status("app", true, metav1.ConditionUnknown, applyErr)}
+			rsl.SetReady(0, checked, ready, err)
+			Expect(rsl[0].Ready).To(Equal(expectedReady))
+			Expect(rsl[0].Error).To(Equal(expectedErr))
+		},
+		Entry("ready", "", true, true, nil, metav1.ConditionTrue, ""),
+		Entry("not ready", "", true, false, nil, metav1.ConditionFalse, ""),
+		Entry("unchecked", "", false, false, nil, metav1.ConditionUnknown, ""),
+		Entry("readiness error", "", false, false, errors.New("not found"), metav1.ConditionUnknown, "not found"),
+		Entry("keeps the apply error", "apply failed", false, false, errors.New("not found"), metav1.ConditionUnknown, "apply failed"),
+	)
+
+	It("should ignore indexes out of range", func() {
+		rsl := ResourceSetResourceStatusList{status("app", true, metav1.ConditionUnknown, "")}
+		rsl.SetReady(-1, true, true, nil)
+		rsl.SetReady(1, true, true, nil)
+		Expect(rsl[0].Ready).To(Equal(metav1.ConditionUnknown))
+	})
+
+	Describe("Summarize", func() {
+		It("should summarize an empty list", func() {
+			Expect(ResourceSetResourceStatusList{}.Summarize()).To(Equal(&TenantResourcesStatus{}))
+		})
+
+		It("should count applied and ready resources and list the others", func() {
+			summary := ResourceSetResourceStatusList{
+				status("ready", true, metav1.ConditionTrue, ""),
+				status("unchecked", true, metav1.ConditionUnknown, ""),
+				status("not-ready", true, metav1.ConditionFalse, ""),
+				status("failed", false, metav1.ConditionUnknown, "apply failed"),
+				status("drifted", false, metav1.ConditionTrue, "", "data.a"),
+				{Id: "cluster", Kind: "Namespace", Name: "acme", Applied: true, Ready: metav1.ConditionTrue},
+			}.Summarize()
+
+			Expect(summary).To(Equal(&TenantResourcesStatus{
+				Total:   6,
+				Applied: 4,
+				Ready:   3,
+				NotReady: []string{
+					"ConfigMap acme/not-ready",
+					"ConfigMap acme/failed: apply failed",
+				},
+				Drifted: []string{"ConfigMap acme/drifted"},
+			}))
+		})
+	})
+})
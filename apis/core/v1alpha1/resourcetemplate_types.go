@@ -33,6 +33,9 @@ const (
 	ResourceNamespaceKeep     ResourceNamespaceRule = "keep"
 	ResourceNamespaceTenant   ResourceNamespaceRule = "tenant"
 	ResourceNamespaceOperator ResourceNamespaceRule = "operator"
+
+	OutputSourceRendered OutputSource = "Rendered"
+	OutputSourceLive     OutputSource = "Live"
 )
 
 // ResourceTemplateSpec defines the desired state of ResourceTemplate
@@ -55,10 +58,92 @@ type ResourceTemplateSpec struct {
 	// Raw contains raw yaml documents in go templating format (prefer using Manifests over Raw)
 	// +kubebuilder:validation:Optional
 	Raw []string `json:"raw,omitempty"`
+
+	// Helm defines a helm chart rendered into resources of the template
+	// +kubebuilder:validation:Optional
+	Helm *HelmChartSource `json:"helm,omitempty"`
+
+	// Outputs defines named values read from the resources of the template and published in the status of the tenant
+	// +kubebuilder:validation:Optional
+	Outputs []ResourceTemplateOutput `json:"outputs,omitempty"`
 }
 
 type ResourceTemplateParameterList []*Parameter
 
+// HelmChartSource defines a helm chart and the values used to render it
+type HelmChartSource struct {
+	// ConfigMap defines a ConfigMap, in the operator namespace, holding a packaged chart (.tgz)
+	// +kubebuilder:validation:Optional
+	ConfigMap *HelmChartConfigMapRef `json:"configMap,omitempty"`
+
+	// Repository defines a chart in a chart repository
+	// +kubebuilder:validation:Optional
+	Repository *HelmChartRepositoryRef `json:"repository,omitempty"`
+
+	// ReleaseName defines the release name used to render the chart, defaults to the prefixed name of the tenant
+	// +kubebuilder:validation:Optional
+	ReleaseName string `json:"releaseName,omitempty"`
+
+	// Values contains a yaml document in go templating format, merged with the default values of the chart
+	// +kubebuilder:validation:Optional
+	Values string `json:"values,omitempty"`
+
+	// IncludeCRDs includes the custom resource definitions of the chart
+	// +kubebuilder:validation:Optional
+	IncludeCRDs bool `json:"includeCRDs,omitempty"`
+}
+
+// HelmChartConfigMapRef defines a ConfigMap holding a packaged chart
+type HelmChartConfigMapRef struct {
+	// Name defines the name of the ConfigMap
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Key defines the key of the packaged chart in the binary data of the ConfigMap
+	// +kubebuilder:validation:Required
+	Key string `json:"key"`
+}
+
+// HelmChartRepositoryRef defines a chart in a chart repository
+type HelmChartRepositoryRef struct {
+	// URL defines the url of the chart repository (http, https or file), the index.yaml of the repository is read
+	// from the url. File repositories must be below the chart root of the operator
+	// +kubebuilder:validation:Required
+	URL string `json:"url"`
+
+	// Chart defines the name of the chart
+	// +kubebuilder:validation:Required
+	Chart string `json:"chart"`
+
+	// Version defines the exact version of the chart
+	// +kubebuilder:validation:Required
+	Version string `json:"version"`
+}
+
+// ResourceTemplateOutput defines a named value read from a resource of the ResourceTemplate. Values are not read from
+// live Secrets nor from templates referencing secret parameters, values that are not strings are given as JSON.
+type ResourceTemplateOutput struct {
+	// Name defines the name of the output
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Kind defines the kind of the resource to read the value from, the first resource of the kind is used
+	// +kubebuilder:validation:Required
+	Kind string `json:"kind"`
+
+	// Source defines if the value is read from the rendered or the live resource
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Rendered;Live
+	// +kubebuilder:default=Rendered
+	Source OutputSource `json:"source,omitempty"`
+
+	// JsonPath defines the path of the value in the resource
+	// +kubebuilder:validation:Required
+	JsonPath string `json:"jsonPath"`
+}
+
+type OutputSource string
+
 // ResourceTemplateRules defines rules of the ResourceTemplate
 type ResourceTemplateRules struct {
 	// Name defines the naming rule to apply for the resources in the ResourceTemplate
@@ -80,10 +165,24 @@ type ResourceNameRule string
 type ResourceTemplateStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
+
+	// Revision is the name of the ResourceTemplateRevision of the current spec.
+	Revision string `json:"revision,omitempty"`
+
+	// Blueprints is the names of the Blueprints using the ResourceTemplate in resource groups or hooks.
+	Blueprints []string `json:"blueprints,omitempty"`
+
+	// ValidationErrors is the errors found when validating the ResourceTemplate.
+	ValidationErrors []string `json:"validationErrors,omitempty"`
+
+	// Conditions represent the latest available observations of the ResourceTemplate state.
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Revision",priority=1,type="string",JSONPath=".status.revision",description="Current revision"
+//+kubebuilder:printcolumn:name="Ready",priority=0,type="string",JSONPath=`.status.conditions[?(@.type == "Ready")].status`,description="ResourceTemplate ready"
 
 // ResourceTemplate is the Schema for the resourcetemplates API
 type ResourceTemplate struct {
@@ -109,12 +208,14 @@ func (rtp ResourceTemplateParameterList) SetValues(pvs []ParameterValue, resolve
 		for _, pv := range pvs {
 			if p.Name == pv.Name {
 				p.value = pv.Value
+				p.sensitive = false
 				if p.value == "" && pv.ValueFrom != nil {
 					val, err := resolverFunc(*pv.ValueFrom)
 					if err != nil {
 						return err
 					}
 					p.value = val
+					p.sensitive = pv.ValueFrom.SecretKeyRef != nil || pv.ValueFrom.Generated != nil
 				}
 			}
 		}
@@ -122,10 +223,21 @@ func (rtp ResourceTemplateParameterList) SetValues(pvs []ParameterValue, resolve
 	return nil
 }
 
-// Validate returns an error if the resource template parameter list contains validation errors
+// Sensitive returns true when a value of the resource template parameter list was read from a Secret or generated
+func (rtp ResourceTemplateParameterList) Sensitive() bool {
+	for _, p := range rtp {
+		if p.sensitive {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate returns an error if the resource template parameter list contains validation errors, values must be of the
+// type and satisfy the constraints of the parameter
 func (rtp ResourceTemplateParameterList) Validate() (err error) {
 	for _, p := range rtp {
-		if _, e := p.Value(); e != nil {
+		if _, e := p.TypedValue(); e != nil {
 			if err == nil {
 				err = e
 				continue
@@ -140,6 +252,19 @@ func (rtp ResourceTemplateParameterList) Validate() (err error) {
 	return err
 }
 
+// TypedValues returns the values of the parameters converted to their type, keyed by parameter name
+func (rtp ResourceTemplateParameterList) TypedValues() (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+	for _, p := range rtp {
+		v, err := p.TypedValue()
+		if err != nil {
+			return nil, err
+		}
+		values[p.Name] = v
+	}
+	return values, nil
+}
+
 // NamespacedName returns a namespaced name for the custom resource
 func (rt ResourceTemplate) NamespacedName() types.NamespacedName {
 	return types.NamespacedName{
@@ -0,0 +1,84 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// ResourceTemplateRevisionSpec defines an immutable snapshot of the spec of a ResourceTemplate
+type ResourceTemplateRevisionSpec struct {
+	// ResourceTemplate contains the name of the ResourceTemplate the revision is a snapshot of
+	// +kubebuilder:validation:Required
+	ResourceTemplate string `json:"resourceTemplate"`
+
+	// Revision is the sequence number of the revision, starting at 1
+	// +kubebuilder:validation:Required
+	Revision int64 `json:"revision"`
+
+	// Hash is the sha256 hash of the snapshot
+	// +kubebuilder:validation:Required
+	Hash string `json:"hash"`
+
+	// Snapshot is the spec of the ResourceTemplate at the time of the revision
+	// +kubebuilder:validation:Required
+	Snapshot ResourceTemplateSpec `json:"snapshot"`
+}
+
+// ResourceTemplateRevisionStatus defines the observed state of ResourceTemplateRevision
+type ResourceTemplateRevisionStatus struct {
+	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
+	// Important: Run "make" to regenerate code after modifying this file
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="ResourceTemplate",priority=0,type="string",JSONPath=".spec.resourceTemplate",description="ResourceTemplate name"
+//+kubebuilder:printcolumn:name="Revision",priority=0,type="integer",JSONPath=".spec.revision",description="Revision number"
+//+kubebuilder:printcolumn:name="Hash",priority=1,type="string",JSONPath=".spec.hash",description="Snapshot hash"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// ResourceTemplateRevision is the Schema for the resourcetemplaterevisions API
+type ResourceTemplateRevision struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ResourceTemplateRevisionSpec   `json:"spec,omitempty"`
+	Status ResourceTemplateRevisionStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ResourceTemplateRevisionList contains a list of ResourceTemplateRevision
+type ResourceTemplateRevisionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ResourceTemplateRevision `json:"items"`
+}
+
+// NamespacedName returns a namespaced name for the custom resource
+func (r ResourceTemplateRevision) NamespacedName() types.NamespacedName {
+	return types.NamespacedName{
+		Namespace: r.Namespace,
+		Name:      r.Name,
+	}
+}
+
+func init() {
+	SchemeBuilder.Register(&ResourceTemplateRevision{}, &ResourceTemplateRevisionList{})
+}
@@ -2,10 +2,88 @@ package v1alpha1
 
 import (
 	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
 
+	yaml "github.com/ghodss/yaml"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+const (
+	// AnnotationDeletionProtection blocks deletion of a Tenant while set to "true"
+	AnnotationDeletionProtection = "aeto.net/deletion-protection"
+
+	// AnnotationExpiresAt sets the expiry (RFC3339) of a tenant, overriding the expiry calculated from the ttl
+	AnnotationExpiresAt = "aeto.net/expires-at"
+
+	// AnnotationExtendTTL extends the expiry of a tenant by a duration (e.g. 72h)
+	AnnotationExtendTTL = "aeto.net/extend-ttl"
+
+	// AnnotationApprovedBy approves a ResourceSet pending approval, the value names the approving user who must be
+	// allowed the approve verb on the ResourceSet, granted to the user rather than to a group of the user
+	AnnotationApprovedBy = "aeto.net/approved-by"
+
+	// AnnotationRotateGeneratedValues rotates the named generated values of a tenant (comma separated, * for all), the
+	// annotation is removed once the values are rotated
+	AnnotationRotateGeneratedValues = "aeto.net/rotate-generated-values"
+
+	// AnnotationCascadeDelete deletes the child tenants of a tenant when it is deleted, without it deletion waits for the
+	// child tenants to be deleted
+	AnnotationCascadeDelete = "aeto.net/cascade-delete"
+
+	// AnnotationRetainPolicy sets the retain policy of a resource, template or tenant
+	AnnotationRetainPolicy = "aeto.net/retain-policy"
+
+	// RetainPolicyRetain orphans the resource instead of deleting it
+	RetainPolicyRetain RetainPolicy = "Retain"
+
+	// RetainPolicyDelete deletes the resource (default)
+	RetainPolicyDelete RetainPolicy = "Delete"
+)
+
+type RetainPolicy string
+
+// controlAnnotations controls the behavior of the operator for a tenant, changing them must not change the resources of
+// the tenant
+var controlAnnotations = []string{
+	AnnotationDeletionProtection,
+	AnnotationExpiresAt,
+	AnnotationExtendTTL,
+	AnnotationRotateGeneratedValues,
+	AnnotationCascadeDelete,
+}
+
+// IsControlAnnotation returns true for annotations controlling the operator
+func IsControlAnnotation(key string) bool {
+	for _, a := range controlAnnotations {
+		if a == key {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	// DriftPolicyAutoCorrect re-applies resources that have drifted from the desired state (default)
+	DriftPolicyAutoCorrect DriftPolicy = "AutoCorrect"
+
+	// DriftPolicyReportOnly reports resources that have drifted from the desired state without re-applying them
+	DriftPolicyReportOnly DriftPolicy = "ReportOnly"
+)
+
+type DriftPolicy string
+
+const (
+	// ApprovalPolicyAutomatic activates new ResourceSets without approval (default)
+	ApprovalPolicyAutomatic ApprovalPolicy = "Automatic"
+
+	// ApprovalPolicyManual requires new ResourceSets to be approved using the approved-by annotation before they are activated
+	ApprovalPolicyManual ApprovalPolicy = "Manual"
+)
+
+type ApprovalPolicy string
+
 // EmbeddedResource holds a kubernetes resource
 // +kubebuilder:validation:XPreserveUnknownFields
 // +kubebuilder:validation:XEmbeddedResource
@@ -28,10 +106,46 @@ type Parameter struct {
 	// +kubebuilder:validation:Optional
 	Default string `json:"default,omitempty"`
 
+	// Type defines the type of the parameter, list and object values are given as json or yaml
+	// +kubebuilder:default=string
+	// +kubebuilder:validation:Optional
+	Type ParameterType `json:"type,omitempty"`
+
+	// Enum defines the allowed values of the parameter, for lists it applies to every item
+	// +kubebuilder:validation:Optional
+	Enum []string `json:"enum,omitempty"`
+
+	// Pattern defines a regular expression the value must match, for lists it applies to every item
+	// +kubebuilder:validation:Optional
+	Pattern string `json:"pattern,omitempty"`
+
+	// Minimum defines the minimum value of an int, or the minimum length of a string, list or object
+	// +kubebuilder:validation:Optional
+	Minimum *int64 `json:"minimum,omitempty"`
+
+	// Maximum defines the maximum value of an int, or the maximum length of a string, list or object
+	// +kubebuilder:validation:Optional
+	Maximum *int64 `json:"maximum,omitempty"`
+
 	// Value holds the value of the parameter
 	value string `json:"-"`
+
+	// sensitive is true when the value was read from a Secret
+	sensitive bool `json:"-"`
 }
 
+// ParameterType defines the type of a template parameter
+// +kubebuilder:validation:Enum=string;int;bool;list;object
+type ParameterType string
+
+const (
+	ParameterTypeString ParameterType = "string"
+	ParameterTypeInt    ParameterType = "int"
+	ParameterTypeBool   ParameterType = "bool"
+	ParameterTypeList   ParameterType = "list"
+	ParameterTypeObject ParameterType = "object"
+)
+
 // ParameterValue defines a template parameter
 type ParameterValue struct {
 	// Name defines the name of the parameter
@@ -56,6 +170,73 @@ type ValueRef struct {
 	// Resource defines a reference to a value from a kubernetes resource
 	// +kubebuilder:validation:Optional
 	Resource *ResourceValueRef `json:"resource,omitempty"`
+
+	// SecretKeyRef defines a reference to a key of a Secret, the value is kept out of logs and events
+	// +kubebuilder:validation:Optional
+	SecretKeyRef *KeyValueRef `json:"secretKeyRef,omitempty"`
+
+	// ConfigMapKeyRef defines a reference to a key of a ConfigMap
+	// +kubebuilder:validation:Optional
+	ConfigMapKeyRef *KeyValueRef `json:"configMapKeyRef,omitempty"`
+
+	// Generated defines a value generated once per tenant and stored in a Secret, the value is kept out of logs and
+	// events
+	// +kubebuilder:validation:Optional
+	Generated *GeneratedValueRef `json:"generated,omitempty"`
+}
+
+// GeneratedValueType defines the type of a generated value
+// +kubebuilder:validation:Enum=password;uuid;string
+type GeneratedValueType string
+
+const (
+	// GeneratedValuePassword generates a password of letters, digits and -_.~ (default length 32)
+	GeneratedValuePassword GeneratedValueType = "password"
+
+	// GeneratedValueUUID generates a random (version 4) UUID
+	GeneratedValueUUID GeneratedValueType = "uuid"
+
+	// GeneratedValueString generates a string of lowercase letters and digits (default length 8)
+	GeneratedValueString GeneratedValueType = "string"
+)
+
+// GeneratedValueRef defines a value generated once per tenant
+type GeneratedValueRef struct {
+	// Name defines the name of the generated value, values with the same name are shared within a tenant
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^[-._a-zA-Z0-9]+$`
+	Name string `json:"name"`
+
+	// Type defines the type of the generated value
+	// +kubebuilder:default=password
+	// +kubebuilder:validation:Optional
+	Type GeneratedValueType `json:"type,omitempty"`
+
+	// Length defines the length of generated passwords and strings
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=256
+	Length int `json:"length,omitempty"`
+
+	// Charset defines the characters of generated passwords and strings
+	// +kubebuilder:validation:Optional
+	Charset string `json:"charset,omitempty"`
+}
+
+// KeyValueRef defines a reference to a key of a Secret or ConfigMap
+type KeyValueRef struct {
+	// Name defines the name of the Secret or ConfigMap, $TENANT_NAME is replaced with the prefixed name of the tenant
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Namespace defines the namespace of the Secret or ConfigMap, $TENANT_NAMESPACE and $OPERATOR_NAMESPACE are
+	// replaced with the namespace of the tenant and the operator
+	// +kubebuilder:validation:Required
+	Namespace string `json:"namespace"`
+
+	// Key defines the key of the value
+	// +kubebuilder:validation:Required
+	Key string `json:"key"`
 }
 
 // BlueprintValueRef defines a reference to a value in a blueprint resource group
@@ -108,3 +289,150 @@ func (p Parameter) Value() (string, error) {
 
 	return "", nil
 }
+
+// TypedValue returns the value of the parameter converted to its type, string, int64, bool, []interface{} or
+// map[string]interface{}. An error is returned when the value can not be converted or violates the constraints of the
+// parameter. Optional parameters without a value returns the zero value of the type.
+func (p Parameter) TypedValue() (interface{}, error) {
+	v, err := p.Value()
+	if err != nil {
+		return nil, err
+	}
+
+	if v == "" {
+		switch p.typeOrDefault() {
+		case ParameterTypeString:
+			return "", nil
+		case ParameterTypeInt:
+			return int64(0), nil
+		case ParameterTypeBool:
+			return false, nil
+		case ParameterTypeList:
+			return []interface{}{}, nil
+		case ParameterTypeObject:
+			return map[string]interface{}{}, nil
+		}
+	}
+
+	return p.Parse(v)
+}
+
+// Parse converts a value to the type of the parameter and validates it against the constraints of the parameter
+func (p Parameter) Parse(value string) (interface{}, error) {
+	switch p.typeOrDefault() {
+	case ParameterTypeString:
+		if err := p.validateScalar(value); err != nil {
+			return nil, err
+		}
+		if err := p.validateRange(int64(len(value)), "length"); err != nil {
+			return nil, err
+		}
+		return value, nil
+	case ParameterTypeInt:
+		i, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parameter %s must be an int, got %s", p.Name, p.display(value))
+		}
+		if err := p.validateScalar(strconv.FormatInt(i, 10)); err != nil {
+			return nil, err
+		}
+		if err := p.validateRange(i, "value"); err != nil {
+			return nil, err
+		}
+		return i, nil
+	case ParameterTypeBool:
+		b, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("parameter %s must be a bool, got %s", p.Name, p.display(value))
+		}
+		if err := p.validateScalar(strconv.FormatBool(b)); err != nil {
+			return nil, err
+		}
+		return b, nil
+	case ParameterTypeList:
+		var l []interface{}
+		if err := yaml.Unmarshal([]byte(value), &l); err != nil {
+			return nil, fmt.Errorf("parameter %s must be a list, got %s", p.Name, p.display(value))
+		}
+		if l == nil {
+			l = []interface{}{}
+		}
+		for _, item := range l {
+			if err := p.validateScalar(fmt.Sprintf("%v", item)); err != nil {
+				return nil, err
+			}
+		}
+		if err := p.validateRange(int64(len(l)), "length"); err != nil {
+			return nil, err
+		}
+		return l, nil
+	case ParameterTypeObject:
+		var o map[string]interface{}
+		if err := yaml.Unmarshal([]byte(value), &o); err != nil {
+			return nil, fmt.Errorf("parameter %s must be an object, got %s", p.Name, p.display(value))
+		}
+		if o == nil {
+			o = map[string]interface{}{}
+		}
+		if err := p.validateRange(int64(len(o)), "length"); err != nil {
+			return nil, err
+		}
+		return o, nil
+	default:
+		return nil, fmt.Errorf("parameter %s has an unsupported type %s", p.Name, p.Type)
+	}
+}
+
+func (p Parameter) typeOrDefault() ParameterType {
+	if p.Type == "" {
+		return ParameterTypeString
+	}
+	return p.Type
+}
+
+// validateScalar validates a string representation of a value against the enum and pattern of the parameter
+func (p Parameter) validateScalar(value string) error {
+	if len(p.Enum) > 0 {
+		allowed := false
+		for _, e := range p.Enum {
+			if e == value {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("parameter %s has value %s which is not one of [%s]", p.Name, p.display(value), strings.Join(p.Enum, ", "))
+		}
+	}
+
+	if p.Pattern != "" {
+		re, err := regexp.Compile(p.Pattern)
+		if err != nil {
+			return fmt.Errorf("parameter %s has an invalid pattern, %v", p.Name, err)
+		}
+		if !re.MatchString(value) {
+			return fmt.Errorf("parameter %s has value %s which does not match pattern %s", p.Name, p.display(value), p.Pattern)
+		}
+	}
+
+	return nil
+}
+
+// display returns the quoted value for use in error messages, values read from Secrets are redacted
+func (p Parameter) display(value string) string {
+	if p.sensitive {
+		return "[redacted]"
+	}
+	return fmt.Sprintf("%q", value)
+}
+
+// validateRange validates a number against the minimum and maximum of the parameter
+func (p Parameter) validateRange(n int64, subject string) error {
+	if p.Minimum != nil && n < *p.Minimum {
+		return fmt.Errorf("parameter %s has a %s of %d which is less than the minimum %d", p.Name, subject, n, *p.Minimum)
+	}
+	if p.Maximum != nil && n > *p.Maximum {
+		return fmt.Errorf("parameter %s has a %s of %d which is greater than the maximum %d", p.Name, subject, n, *p.Maximum)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func int64Ptr(i int64) *int64 {
+	return &i
+}
+
+var _ = Describe("Parameter", func() {
+	DescribeTable("Parse",
+		func(p Parameter, value string, expected interface{}, expectedErr string) {
+			v, err := p.Parse(value)
+			if expectedErr != "" {
+				Expect(err).To(MatchError(ContainSubstring(expectedErr)))
+				return
+			}
+			Expect(err).NotTo(HaveOccurred())
+			Expect(v).To(Equal(expected))
+		},
+		Entry("string by default", Parameter{Name: "p"}, "a", "a", ""),
+		Entry("string in enum", Parameter{Name: "p", Enum: []string{"a", "b"}}, "b", "b", ""),
+		Entry("string not in enum", Parameter{Name: "p", Enum: []string{"a", "b"}}, "c", nil, `parameter p has value "c" which is not one of [a, b]`),
+		Entry("string matching pattern", Parameter{Name: "p", Pattern: "^[a-z]+$"}, "abc", "abc", ""),
+		Entry("string not matching pattern", Parameter{Name: "p", Pattern: "^[a-z]+$"}, "ABC", nil, `parameter p has value "ABC" which does not match pattern ^[a-z]+$`),
+		Entry("invalid pattern", Parameter{Name: "p", Pattern: "("}, "a", nil, "parameter p has an invalid pattern"),
+		Entry("string shorter than minimum", Parameter{Name: "p", Minimum: int64Ptr(2)}, "a", nil, "parameter p has a length of 1 which is less than the minimum 2"),
+		Entry("string longer than maximum", Parameter{Name: "p", Maximum: int64Ptr(2)}, "abc", nil, "parameter p has a length of 3 which is greater than the maximum 2"),
+		Entry("int", Parameter{Name: "p", Type: ParameterTypeInt}, " 42 ", int64(42), ""),
+		Entry("invalid int", Parameter{Name: "p", Type: ParameterTypeInt}, "4.2", nil, `parameter p must be an int, got "4.2"`),
+		Entry("int in range", Parameter{Name: "p", Type: ParameterTypeInt, Minimum: int64Ptr(1), Maximum: int64Ptr(3)}, "3", int64(3), ""),
+		Entry("int below minimum", Parameter{Name: "p", Type: ParameterTypeInt, Minimum: int64Ptr(1)}, "0", nil, "parameter p has a value of 0 which is less than the minimum 1"),
+		Entry("int in enum", Parameter{Name: "p", Type: ParameterTypeInt, Enum: []string{"1", "3"}}, "03", int64(3), ""),
+		Entry("bool", Parameter{Name: "p", Type: ParameterTypeBool}, "true", true, ""),
+		Entry("invalid bool", Parameter{Name: "p", Type: ParameterTypeBool}, "yes", nil, `parameter p must be a bool, got "yes"`),
+		Entry("json list", Parameter{Name: "p", Type: ParameterTypeList}, `["a", 1]`, []interface{}{"a", float64(1)}, ""),
+		Entry("yaml list", Parameter{Name: "p", Type: ParameterTypeList}, "- a\n- b", []interface{}{"a", "b"}, ""),
+		Entry("empty list", Parameter{Name: "p", Type: ParameterTypeList}, "", []interface{}{}, ""),
+		Entry("invalid list", Parameter{Name: "p", Type: ParameterTypeList}, `{"a": 1}`, nil, "parameter p must be a list"),
+		Entry("list items not in enum", Parameter{Name: "p", Type: ParameterTypeList, Enum: []string{"a"}}, `["a", "b"]`, nil, `parameter p has value "b" which is not one of [a]`),
+		Entry("list longer than maximum", Parameter{Name: "p", Type: ParameterTypeList, Maximum: int64Ptr(1)}, `["a", "b"]`, nil, "parameter p has a length of 2 which is greater than the maximum 1"),
+		Entry("object", Parameter{Name: "p", Type: ParameterTypeObject}, "a: 1", map[string]interface{}{"a": float64(1)}, ""),
+		Entry("invalid object", Parameter{Name: "p", Type: ParameterTypeObject}, `["a"]`, nil, "parameter p must be an object"),
+		Entry("unsupported type", Parameter{Name: "p", Type: "float"}, "1.0", nil, "parameter p has an unsupported type float"),
+	)
+
+	DescribeTable("TypedValue",
+		func(p Parameter, expected interface{}, expectedErr string) {
+			v, err := p.TypedValue()
+			if expectedErr != "" {
+				Expect(err).To(MatchError(ContainSubstring(expectedErr)))
+				return
+			}
+			Expect(err).NotTo(HaveOccurred())
+			Expect(v).To(Equal(expected))
+		},
+		Entry("value", Parameter{Name: "p", Type: ParameterTypeInt, Default: "1", value: "2"}, int64(2), ""),
+		Entry("default", Parameter{Name: "p", Type: ParameterTypeInt, Default: "1"}, int64(1), ""),
+		Entry("required without value", Parameter{Name: "p", Required: true}, nil, "required parameter p has no value"),
+		Entry("optional string without value", Parameter{Name: "p"}, "", ""),
+		Entry("optional int without value", Parameter{Name: "p", Type: ParameterTypeInt}, int64(0), ""),
+		Entry("optional bool without value", Parameter{Name: "p", Type: ParameterTypeBool}, false, ""),
+		Entry("optional list without value", Parameter{Name: "p", Type: ParameterTypeList}, []interface{}{}, ""),
+		Entry("optional object without value", Parameter{Name: "p", Type: ParameterTypeObject}, map[string]interface{}{}, ""),
+		Entry("optional with enum without value", Parameter{Name: "p", Enum: []string{"a"}}, "", ""),
+		Entry("invalid default", Parameter{Name: "p", Type: ParameterTypeBool, Default: "maybe"}, nil, `parameter p must be a bool, got "maybe"`),
+		Entry("redacts sensitive values in errors", Parameter{Name: "p", Type: ParameterTypeInt, value: "secret", sensitive: true}, nil, "parameter p must be an int, got [redacted]"),
+	)
+})
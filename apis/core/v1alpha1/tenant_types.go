@@ -17,10 +17,18 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 )
 
+// operatorKeyPrefix is the prefix of labels and annotations of the operator
+const operatorKeyPrefix = "aeto.net/"
+
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
@@ -36,8 +44,70 @@ type TenantSpec struct {
 	// Blueprint contains the name of the Blueprint to use for the tenant
 	// +kubebuilder:validation:Optional
 	Blueprint string `json:"blueprint,omitempty"`
+
+	// Parent contains the name of a Tenant, in the same namespace, to inherit labels, annotations and parameters from.
+	// The parent is not deleted until its children are deleted.
+	// +kubebuilder:validation:Optional
+	Parent string `json:"parent,omitempty"`
+
+	// Parameters defines parameter values that applies to all templates of the tenant, parameters of blueprint resource groups take precedence
+	// +kubebuilder:validation:Optional
+	Parameters []ParameterValue `json:"parameters,omitempty"`
+
+	// AddOns contains the names of Blueprints whose resource groups and hooks are added, in order, to the Blueprint of the tenant
+	// +kubebuilder:validation:Optional
+	AddOns []string `json:"addOns,omitempty"`
+
+	// AdoptionPolicy controls if pre-existing resources matching the generated resources may be adopted by the tenant
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Never;Adopt
+	// +kubebuilder:default=Never
+	AdoptionPolicy AdoptionPolicy `json:"adoptionPolicy,omitempty"`
+
+	// ApprovalPolicy defines if new ResourceSets are activated automatically or require manual approval, overrides the approval policy of the blueprint
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Automatic;Manual
+	ApprovalPolicy ApprovalPolicy `json:"approvalPolicy,omitempty"`
+
+	// MaintenanceWindows defines the weekday and time ranges (e.g. MON-FRI 22:00-23:59 Europe/Stockholm) when new ResourceSets may be activated, overrides the maintenance windows of the blueprint
+	// +kubebuilder:validation:Optional
+	MaintenanceWindows []string `json:"maintenanceWindows,omitempty"`
+
+	// TTL defines the time to live of the tenant, the tenant is deleted when it expires
+	// +kubebuilder:validation:Optional
+	TTL *metav1.Duration `json:"ttl,omitempty"`
 }
 
+const (
+	// DefaultBlueprint is the name of the blueprint used when no blueprint is specified or selected
+	DefaultBlueprint = "default"
+
+	// BlueprintReferenceSelector is the blueprint reference of tenants without a blueprint specified, their blueprint is
+	// selected by tenant selector or defaults to DefaultBlueprint
+	BlueprintReferenceSelector = "$selector"
+
+	// AdoptionPolicyNever reports pre-existing resources without applying them (default)
+	AdoptionPolicyNever AdoptionPolicy = "Never"
+
+	// AdoptionPolicyAdopt takes over pre-existing resources, adopted resources are retained when the tenant is deleted
+	AdoptionPolicyAdopt AdoptionPolicy = "Adopt"
+)
+
+type AdoptionPolicy string
+
+const (
+	// BlueprintSelectionSpec means the blueprint is specified by the tenant
+	BlueprintSelectionSpec BlueprintSelection = "Spec"
+
+	// BlueprintSelectionSelector means the blueprint was selected by its tenant selector
+	BlueprintSelectionSelector BlueprintSelection = "Selector"
+
+	// BlueprintSelectionDefault means no blueprint was specified or selected and the default blueprint is used
+	BlueprintSelectionDefault BlueprintSelection = "Default"
+)
+
+type BlueprintSelection string
+
 // TenantStatus defines the observed state of Tenant
 type TenantStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
@@ -49,12 +119,42 @@ type TenantStatus struct {
 	// Blueprint is the namespace/name of the Blueprint in use by the Tenant.
 	Blueprint string `json:"blueprint,omitempty"`
 
+	// BlueprintSelection describes how the Blueprint in use by the Tenant was selected.
+	BlueprintSelection BlueprintSelection `json:"blueprintSelection,omitempty"`
+
+	// AddOns is the namespace/name of the add-on Blueprints in use by the Tenant.
+	AddOns []string `json:"addOns,omitempty"`
+
+	// Children is the names of the Tenants that has this Tenant as their parent.
+	Children []string `json:"children,omitempty"`
+
 	// ResourceSet is the the namespace/name of the ResourceSet in use by the Tenant.
 	ResourceSet string `json:"resourceSet,omitempty"`
 
+	// Deferred is the resource groups waiting for the resource groups they depend on to become ready.
+	Deferred []TenantDeferredResourceGroupStatus `json:"deferred,omitempty"`
+
 	// Events is the number of events produced for the Tenant.
 	Events int `json:"events,omitempty"`
 
+	// Resources is a summary of the resources in the active ResourceSet of the Tenant.
+	Resources *TenantResourcesStatus `json:"resources,omitempty"`
+
+	// Hooks is the status of the lifecycle hooks run for the Tenant.
+	Hooks []TenantHookStatus `json:"hooks,omitempty"`
+
+	// Adoptions is the status of pre-existing resources adopted by, or pending adoption into, the Tenant.
+	Adoptions []TenantAdoptionStatus `json:"adoptions,omitempty"`
+
+	// Outputs contains the named values published by the resources of the Tenant.
+	Outputs map[string]string `json:"outputs,omitempty"`
+
+	// Maintenance is the pending change of the Tenant, waiting for the next maintenance window.
+	Maintenance *TenantMaintenanceStatus `json:"maintenance,omitempty"`
+
+	// ExpiresAt is the time when the Tenant expires and is deleted.
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
+
 	// Status is the current lifecycle phase of the Tenant.
 	Status string `json:"status,omitempty"`
 
@@ -62,13 +162,87 @@ type TenantStatus struct {
 	Conditions []metav1.Condition `json:"conditions"`
 }
 
+// TenantDeferredResourceGroupStatus defines a resource group waiting for its dependencies
+type TenantDeferredResourceGroupStatus struct {
+	// Name is the name of the resource group.
+	Name string `json:"name"`
+
+	// WaitingFor is the names of the resource groups that are not yet ready.
+	WaitingFor []string `json:"waitingFor"`
+}
+
+// TenantResourcesStatus defines a summary of the observed state of the Tenant resources
+type TenantResourcesStatus struct {
+	// Total is the number of resources in the active ResourceSet.
+	Total int `json:"total"`
+
+	// Applied is the number of resources successfully applied.
+	Applied int `json:"applied"`
+
+	// Ready is the number of resources reported as ready.
+	Ready int `json:"ready"`
+
+	// NotReady lists the resources that failed to apply or are not ready.
+	NotReady []string `json:"notReady,omitempty"`
+
+	// Drifted lists the resources that have drifted from the desired state.
+	Drifted []string `json:"drifted,omitempty"`
+}
+
+// TenantMaintenanceStatus defines a change of the Tenant waiting for the next maintenance window
+type TenantMaintenanceStatus struct {
+	// NextWindow is the start of the next maintenance window.
+	NextWindow *metav1.Time `json:"nextWindow,omitempty"`
+
+	// ResourceSet is the namespace/name of the ResourceSet activated in the next maintenance window.
+	ResourceSet string `json:"resourceSet"`
+
+	// Changes lists the resources added (+), updated (~) or removed (-) by the ResourceSet.
+	Changes []string `json:"changes,omitempty"`
+}
+
+// TenantHookStatus defines the observed state of a Tenant lifecycle hook
+type TenantHookStatus struct {
+	// Name is the name of the hook.
+	Name string `json:"name"`
+
+	// Phase is the lifecycle phase of the hook.
+	Phase string `json:"phase"`
+
+	// Job is the namespace/name of the Job run by the hook.
+	Job string `json:"job"`
+
+	// Status is the status of the hook.
+	Status string `json:"status"`
+
+	// Reason is the reason of a hook failure.
+	Reason string `json:"reason,omitempty"`
+}
+
+// TenantAdoptionStatus defines the observed state of a pre-existing resource matching a Tenant resource
+type TenantAdoptionStatus struct {
+	// Id is the id of the resource.
+	Id string `json:"id"`
+
+	// Resource is the kind and namespace/name of the resource.
+	Resource string `json:"resource"`
+
+	// Status is the adoption status of the resource.
+	Status string `json:"status"`
+
+	// Diff contains the paths of the fields that differ between the pre-existing and the generated resource.
+	Diff []string `json:"diff,omitempty"`
+}
+
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
 //+kubebuilder:printcolumn:name="Tenant",priority=0,type="string",JSONPath=".spec.name",description="The display name of the tenant"
 //+kubebuilder:printcolumn:name="Namespace",priority=0,type="string",JSONPath=".status.namespace",description="Tenant namespace"
+//+kubebuilder:printcolumn:name="Parent",priority=1,type="string",JSONPath=".spec.parent",description="Parent tenant"
 //+kubebuilder:printcolumn:name="Blueprint",priority=1,type="string",JSONPath=".status.blueprint",description="Blueprint name"
 //+kubebuilder:printcolumn:name="ResourceSet",priority=1,type="string",JSONPath=".status.resourceSet",description="ResourceSet name"
 //+kubebuilder:printcolumn:name="Events",priority=1,type="string",JSONPath=".status.events",description="Events produced for tenant"
+//+kubebuilder:printcolumn:name="Expires",priority=1,type="string",JSONPath=".status.expiresAt",description="Tenant expiry"
 //+kubebuilder:printcolumn:name="Status",priority=0,type="string",JSONPath=".status.status",description="Tenant lifecycle phase"
 //+kubebuilder:printcolumn:name="Ready",priority=0,type="string",JSONPath=`.status.conditions[?(@.type == "Ready")].status`,description="Tenant ready"
 
@@ -90,12 +264,179 @@ type TenantList struct {
 	Items           []Tenant `json:"items"`
 }
 
+// BlueprintReferences returns the names of the blueprints specified for and in use by the tenant, tenants without a
+// blueprint specified reference BlueprintReferenceSelector
+func (t Tenant) BlueprintReferences() []string {
+	names := make([]string, 0)
+	add := func(name string) {
+		for _, n := range names {
+			if n == name {
+				return
+			}
+		}
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+
+	if t.Spec.Blueprint == "" {
+		add(BlueprintReferenceSelector)
+	} else {
+		add(t.Spec.Blueprint)
+	}
+	for _, addOn := range t.Spec.AddOns {
+		add(addOn)
+	}
+
+	for _, nn := range append([]string{t.Status.Blueprint}, t.Status.AddOns...) {
+		parts := strings.Split(nn, string(types.Separator))
+		add(parts[len(parts)-1])
+	}
+
+	return names
+}
+
+// UsesBlueprint returns true when the blueprint or an add-on in use by the tenant is one of the namespace/name of blueprints
+func (t Tenant) UsesBlueprint(blueprints ...string) bool {
+	for _, nn := range append([]string{t.Status.Blueprint}, t.Status.AddOns...) {
+		for _, b := range blueprints {
+			if nn != "" && nn == b {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// BlueprintsChanged returns true when the blueprints specified for or in use by the tenant differ from the other tenant,
+// other changes of the tenant do not affect its blueprints
+func (t Tenant) BlueprintsChanged(other Tenant) bool {
+	return t.Spec.Blueprint != other.Spec.Blueprint ||
+		t.Status.Blueprint != other.Status.Blueprint ||
+		!reflect.DeepEqual(t.Status.AddOns, other.Status.AddOns)
+}
+
 // Blueprint returns the name of the blueprint to use for generating tenant resources
 func (t *Tenant) Blueprint() string {
 	if t.Spec.Blueprint != "" {
 		return t.Spec.Blueprint
 	}
-	return "default"
+	return DefaultBlueprint
+}
+
+// ExpiresAt returns the expiry of the tenant, calculated from the ttl or expires-at annotation and extended by the extend-ttl annotation
+func (t Tenant) ExpiresAt() (*time.Time, error) {
+	var expiresAt time.Time
+
+	if v, ok := t.Annotations[AnnotationExpiresAt]; ok && v != "" {
+		ea, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s annotation, %w", AnnotationExpiresAt, err)
+		}
+		expiresAt = ea
+	} else if t.Spec.TTL != nil {
+		expiresAt = t.CreationTimestamp.Add(t.Spec.TTL.Duration)
+	} else {
+		return nil, nil
+	}
+
+	if v, ok := t.Annotations[AnnotationExtendTTL]; ok && v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s annotation, %w", AnnotationExtendTTL, err)
+		}
+		expiresAt = expiresAt.Add(d)
+	}
+
+	expiresAt = expiresAt.UTC().Truncate(time.Second)
+	return &expiresAt, nil
+}
+
+// SelectBlueprint returns the blueprint with the highest priority selecting the tenant, blueprints of the same priority
+// are selected by name. Blueprints with an invalid tenant selector are skipped and returned as errors.
+func (t Tenant) SelectBlueprint(blueprints []Blueprint) (*Blueprint, []error) {
+	var selected *Blueprint
+	errs := make([]error, 0)
+	for i, b := range blueprints {
+		match, err := b.Selects(t)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid tenant selector of blueprint %s, %w", b.Name, err))
+			continue
+		}
+		if !match {
+			continue
+		}
+		if selected == nil || b.Spec.Priority > selected.Spec.Priority || (b.Spec.Priority == selected.Spec.Priority && b.Name < selected.Name) {
+			selected = &blueprints[i]
+		}
+	}
+	return selected, errs
+}
+
+// DeletionProtected returns true when the tenant is protected from deletion by the deletion-protection annotation
+func (t Tenant) DeletionProtected() bool {
+	return t.Annotations[AnnotationDeletionProtection] == "true"
+}
+
+// ApprovalPolicy returns the approval policy of the tenant, falling back to the approval policy of the blueprint
+func (t Tenant) ApprovalPolicy(blueprint Blueprint) ApprovalPolicy {
+	if t.Spec.ApprovalPolicy != "" {
+		return t.Spec.ApprovalPolicy
+	}
+	return blueprint.Spec.ApprovalPolicy
+}
+
+// MaintenanceWindows returns the maintenance windows of the tenant, falling back to the maintenance windows of the blueprint
+func (t Tenant) MaintenanceWindows(blueprint Blueprint) []string {
+	if len(t.Spec.MaintenanceWindows) > 0 {
+		return t.Spec.MaintenanceWindows
+	}
+	return blueprint.Spec.MaintenanceWindows
+}
+
+// InheritFrom returns a copy of the tenant with the labels, annotations and parameters of the parent added, values of the tenant take precedence.
+// Labels and annotations of the operator (aeto.net/) are not inherited.
+func (t Tenant) InheritFrom(parent Tenant) Tenant {
+	tenant := *t.DeepCopy()
+
+	if len(parent.Labels) > 0 && tenant.Labels == nil {
+		tenant.Labels = make(map[string]string)
+	}
+	for k, v := range parent.Labels {
+		if strings.HasPrefix(k, operatorKeyPrefix) {
+			continue
+		}
+		if _, ok := tenant.Labels[k]; !ok {
+			tenant.Labels[k] = v
+		}
+	}
+
+	if len(parent.Annotations) > 0 && tenant.Annotations == nil {
+		tenant.Annotations = make(map[string]string)
+	}
+	for k, v := range parent.Annotations {
+		if strings.HasPrefix(k, operatorKeyPrefix) {
+			continue
+		}
+		if _, ok := tenant.Annotations[k]; !ok {
+			tenant.Annotations[k] = v
+		}
+	}
+
+	for _, pv := range parent.Spec.Parameters {
+		found := false
+		for _, tpv := range tenant.Spec.Parameters {
+			if tpv.Name == pv.Name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			tenant.Spec.Parameters = append(tenant.Spec.Parameters, *pv.DeepCopy())
+		}
+	}
+
+	return tenant
 }
 
 // NamespacedName returns a namespaced name for the custom resource
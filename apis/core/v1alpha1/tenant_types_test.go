@@ -0,0 +1,242 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Tenant", func() {
+	Describe("ExpiresAt", func() {
+		created := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+
+		DescribeTable("expiry",
+			func(ttl *time.Duration, annotations map[string]string, expected string, expectedErr string) {
+				tenant := Tenant{
+					ObjectMeta: metav1.ObjectMeta{
+						CreationTimestamp: metav1.NewTime(created),
+						Annotations:       annotations,
+					},
+				}
+				if ttl != nil {
+					tenant.Spec.TTL = &metav1.Duration{Duration: *ttl}
+				}
+
+				expiresAt, err := tenant.ExpiresAt()
+				if expectedErr != "" {
+					Expect(err).To(MatchError(ContainSubstring(expectedErr)))
+					return
+				}
+				Expect(err).NotTo(HaveOccurred())
+				if expected == "" {
+					Expect(expiresAt).To(BeNil())
+					return
+				}
+				Expect(expiresAt).NotTo(BeNil())
+				Expect(expiresAt.Format(time.RFC3339)).To(Equal(expected))
+			},
+			Entry("without ttl or annotation", nil, nil, "", ""),
+			Entry("from the ttl", durationPtr(48*time.Hour), nil, "2022-06-03T12:00:00Z", ""),
+			Entry("from the expires-at annotation, overriding the ttl", durationPtr(48*time.Hour), map[string]string{AnnotationExpiresAt: "2022-07-01T00:00:00+02:00"}, "2022-06-30T22:00:00Z", ""),
+			Entry("extended by the extend-ttl annotation", durationPtr(time.Hour), map[string]string{AnnotationExtendTTL: "72h"}, "2022-06-04T13:00:00Z", ""),
+			Entry("extend-ttl without ttl", nil, map[string]string{AnnotationExtendTTL: "72h"}, "", ""),
+			Entry("invalid expires-at annotation", nil, map[string]string{AnnotationExpiresAt: "tomorrow"}, "", "invalid aeto.net/expires-at annotation"),
+			Entry("invalid extend-ttl annotation", durationPtr(time.Hour), map[string]string{AnnotationExtendTTL: "3 days"}, "", "invalid aeto.net/extend-ttl annotation"),
+		)
+	})
+
+	Describe("SelectBlueprint", func() {
+		tenant := Tenant{ObjectMeta: metav1.ObjectMeta{Name: "acme", Labels: map[string]string{"tier": "premium", "region": "eu"}}}
+
+		blueprint := func(name string, priority int, selector *metav1.LabelSelector) Blueprint {
+			b := testBlueprint(name)
+			b.Spec.Priority = priority
+			b.Spec.TenantSelector = selector
+			return b
+		}
+		premium := &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "premium"}}
+		eu := &metav1.LabelSelector{MatchLabels: map[string]string{"region": "eu"}}
+		us := &metav1.LabelSelector{MatchLabels: map[string]string{"region": "us"}}
+		invalid := &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "tier", Operator: "Unknown"}}}
+
+		DescribeTable("selection",
+			func(blueprints []Blueprint, expected string, expectedErrs int) {
+				selected, errs := tenant.SelectBlueprint(blueprints)
+				Expect(errs).To(HaveLen(expectedErrs))
+				if expected == "" {
+					Expect(selected).To(BeNil())
+				} else {
+					Expect(selected).NotTo(BeNil())
+					Expect(selected.Name).To(Equal(expected))
+				}
+			},
+			Entry("no blueprints", []Blueprint{}, "", 0),
+			Entry("blueprints without selectors", []Blueprint{blueprint("a", 10, nil), blueprint("b", 0, &metav1.LabelSelector{})}, "", 0),
+			Entry("no matching selector", []Blueprint{blueprint("a", 0, us)}, "", 0),
+			Entry("matching selector", []Blueprint{blueprint("a", 0, us), blueprint("b", 0, eu)}, "b", 0),
+			Entry("highest priority", []Blueprint{blueprint("a", 1, premium), blueprint("b", 5, eu), blueprint("c", 2, premium)}, "b", 0),
+			Entry("negative priority", []Blueprint{blueprint("a", -1, premium), blueprint("b", 0, eu)}, "b", 0),
+			Entry("ties are broken by name", []Blueprint{blueprint("c", 5, premium), blueprint("a", 5, eu), blueprint("b", 5, premium)}, "a", 0),
+			Entry("invalid selectors are skipped", []Blueprint{blueprint("a", 10, invalid), blueprint("b", 0, eu)}, "b", 1),
+		)
+
+		It("should report the blueprint of invalid selectors", func() {
+			_, errs := tenant.SelectBlueprint([]Blueprint{blueprint("broken", 0, invalid)})
+			Expect(errs).To(HaveLen(1))
+			Expect(errs[0]).To(MatchError(ContainSubstring("invalid tenant selector of blueprint broken")))
+		})
+	})
+
+	DescribeTable("UsesBlueprint",
+		func(status TenantStatus, blueprints []string, expected bool) {
+			tenant := Tenant{Status: status}
+			Expect(tenant.UsesBlueprint(blueprints...)).To(Equal(expected))
+		},
+		Entry("no blueprint in use", TenantStatus{}, []string{"aeto/default"}, false),
+		Entry("no blueprints", TenantStatus{Blueprint: "aeto/default"}, []string{}, false),
+		Entry("blueprint in use", TenantStatus{Blueprint: "aeto/default"}, []string{"aeto/basic", "aeto/default"}, true),
+		Entry("add-on in use", TenantStatus{Blueprint: "aeto/default", AddOns: []string{"aeto/monitoring"}}, []string{"aeto/monitoring"}, true),
+		Entry("blueprint in other namespace", TenantStatus{Blueprint: "aeto/default"}, []string{"other/default"}, false),
+		Entry("specified but not in use", TenantStatus{}, []string{""}, false),
+	)
+
+	DescribeTable("BlueprintsChanged",
+		func(old Tenant, new Tenant, expected bool) {
+			Expect(new.BlueprintsChanged(old)).To(Equal(expected))
+		},
+		Entry("unchanged",
+			Tenant{Spec: TenantSpec{Blueprint: "basic"}, Status: TenantStatus{Blueprint: "aeto/basic", AddOns: []string{"aeto/monitoring"}}},
+			Tenant{Spec: TenantSpec{Blueprint: "basic"}, Status: TenantStatus{Blueprint: "aeto/basic", AddOns: []string{"aeto/monitoring"}}},
+			false,
+		),
+		Entry("other changes",
+			Tenant{Spec: TenantSpec{Name: "Tenant"}, Status: TenantStatus{Status: "Pending"}},
+			Tenant{Spec: TenantSpec{Name: "Renamed tenant"}, Status: TenantStatus{Status: "Reconciled"}},
+			false,
+		),
+		Entry("specified blueprint",
+			Tenant{Spec: TenantSpec{Blueprint: "basic"}},
+			Tenant{Spec: TenantSpec{Blueprint: "premium"}},
+			true,
+		),
+		Entry("blueprint in use",
+			Tenant{Status: TenantStatus{Blueprint: "aeto/default"}},
+			Tenant{Status: TenantStatus{Blueprint: "aeto/premium"}},
+			true,
+		),
+		Entry("add-ons in use",
+			Tenant{Status: TenantStatus{AddOns: []string{"aeto/monitoring"}}},
+			Tenant{Status: TenantStatus{AddOns: []string{"aeto/monitoring", "aeto/backup"}}},
+			true,
+		),
+	)
+
+	DescribeTable("BlueprintReferences",
+		func(spec TenantSpec, status TenantStatus, expected []string) {
+			tenant := Tenant{Spec: spec, Status: status}
+			Expect(tenant.BlueprintReferences()).To(Equal(expected))
+		},
+		Entry("selected blueprint", TenantSpec{}, TenantStatus{}, []string{BlueprintReferenceSelector}),
+		Entry("specified blueprint", TenantSpec{Blueprint: "premium"}, TenantStatus{}, []string{"premium"}),
+		Entry("add-ons", TenantSpec{Blueprint: "premium", AddOns: []string{"monitoring", "premium"}}, TenantStatus{}, []string{"premium", "monitoring"}),
+		Entry("blueprints in use",
+			TenantSpec{Blueprint: "premium"},
+			TenantStatus{Blueprint: "aeto/basic", AddOns: []string{"aeto/monitoring"}},
+			[]string{"premium", "basic", "monitoring"},
+		),
+		Entry("selected blueprint in use", TenantSpec{}, TenantStatus{Blueprint: "aeto/default"}, []string{BlueprintReferenceSelector, "default"}),
+	)
+
+	DescribeTable("DeletionProtected",
+		func(annotations map[string]string, expected bool) {
+			tenant := Tenant{ObjectMeta: metav1.ObjectMeta{Annotations: annotations}}
+			Expect(tenant.DeletionProtected()).To(Equal(expected))
+		},
+		Entry("without annotations", nil, false),
+		Entry("protected", map[string]string{AnnotationDeletionProtection: "true"}, true),
+		Entry("not protected", map[string]string{AnnotationDeletionProtection: "false"}, false),
+		Entry("invalid value", map[string]string{AnnotationDeletionProtection: "yes"}, false),
+	)
+
+	Describe("InheritFrom", func() {
+		parent := Tenant{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "parent",
+				Labels: map[string]string{"team": "platform", "env": "prod", "aeto.net/tenant": "parent"},
+				Annotations: map[string]string{
+					"owner":                         "platform@example.com",
+					AnnotationDeletionProtection:    "true",
+					AnnotationExpiresAt:             "2022-07-01T00:00:00Z",
+					AnnotationRotateGeneratedValues: "*",
+					AnnotationCascadeDelete:         "true",
+				},
+			},
+			Spec: TenantSpec{
+				Parameters: []ParameterValue{{Name: "region", Value: "eu-west-1"}, {Name: "size", Value: "large"}},
+			},
+		}
+
+		DescribeTable("inherited values",
+			func(child Tenant, labels map[string]string, annotations map[string]string, parameters []ParameterValue) {
+				inherited := child.InheritFrom(parent)
+				Expect(inherited.Labels).To(Equal(labels))
+				Expect(inherited.Annotations).To(Equal(annotations))
+				Expect(inherited.Spec.Parameters).To(Equal(parameters))
+			},
+			Entry("tenant without values",
+				Tenant{},
+				map[string]string{"team": "platform", "env": "prod"},
+				map[string]string{"owner": "platform@example.com"},
+				[]ParameterValue{{Name: "region", Value: "eu-west-1"}, {Name: "size", Value: "large"}},
+			),
+			Entry("values of the tenant take precedence",
+				Tenant{
+					ObjectMeta: metav1.ObjectMeta{
+						Labels:      map[string]string{"env": "dev"},
+						Annotations: map[string]string{"owner": "dev@example.com"},
+					},
+					Spec: TenantSpec{Parameters: []ParameterValue{{Name: "size", Value: "small"}}},
+				},
+				map[string]string{"team": "platform", "env": "dev"},
+				map[string]string{"owner": "dev@example.com"},
+				[]ParameterValue{{Name: "size", Value: "small"}, {Name: "region", Value: "eu-west-1"}},
+			),
+			Entry("operator annotations of the tenant are kept",
+				Tenant{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{AnnotationDeletionProtection: "false"}}},
+				map[string]string{"team": "platform", "env": "prod"},
+				map[string]string{"owner": "platform@example.com", AnnotationDeletionProtection: "false"},
+				[]ParameterValue{{Name: "region", Value: "eu-west-1"}, {Name: "size", Value: "large"}},
+			),
+		)
+
+		It("should not modify the tenant", func() {
+			child := Tenant{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"env": "dev"}}}
+			child.InheritFrom(parent)
+			Expect(child.Labels).To(Equal(map[string]string{"env": "dev"}))
+			Expect(child.Spec.Parameters).To(BeEmpty())
+		})
+	})
+})
+
+func durationPtr(d time.Duration) *time.Duration {
+	return &d
+}
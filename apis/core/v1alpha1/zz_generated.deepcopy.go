@@ -32,7 +32,7 @@ func (in *Blueprint) DeepCopyInto(out *Blueprint) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Blueprint.
@@ -53,6 +53,28 @@ func (in *Blueprint) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BlueprintHook) DeepCopyInto(out *BlueprintHook) {
+	*out = *in
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make([]ParameterValue, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlueprintHook.
+func (in *BlueprintHook) DeepCopy() *BlueprintHook {
+	if in == nil {
+		return nil
+	}
+	out := new(BlueprintHook)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BlueprintList) DeepCopyInto(out *BlueprintList) {
 	*out = *in
@@ -85,6 +107,208 @@ func (in *BlueprintList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BlueprintParameterStatus) DeepCopyInto(out *BlueprintParameterStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlueprintParameterStatus.
+func (in *BlueprintParameterStatus) DeepCopy() *BlueprintParameterStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BlueprintParameterStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BlueprintPreview) DeepCopyInto(out *BlueprintPreview) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlueprintPreview.
+func (in *BlueprintPreview) DeepCopy() *BlueprintPreview {
+	if in == nil {
+		return nil
+	}
+	out := new(BlueprintPreview)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *BlueprintPreview) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BlueprintPreviewList) DeepCopyInto(out *BlueprintPreviewList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]BlueprintPreview, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlueprintPreviewList.
+func (in *BlueprintPreviewList) DeepCopy() *BlueprintPreviewList {
+	if in == nil {
+		return nil
+	}
+	out := new(BlueprintPreviewList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *BlueprintPreviewList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BlueprintPreviewResourceStatus) DeepCopyInto(out *BlueprintPreviewResourceStatus) {
+	*out = *in
+	if in.Diff != nil {
+		in, out := &in.Diff, &out.Diff
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlueprintPreviewResourceStatus.
+func (in *BlueprintPreviewResourceStatus) DeepCopy() *BlueprintPreviewResourceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BlueprintPreviewResourceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BlueprintPreviewResourceTemplate) DeepCopyInto(out *BlueprintPreviewResourceTemplate) {
+	*out = *in
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlueprintPreviewResourceTemplate.
+func (in *BlueprintPreviewResourceTemplate) DeepCopy() *BlueprintPreviewResourceTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(BlueprintPreviewResourceTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BlueprintPreviewSpec) DeepCopyInto(out *BlueprintPreviewSpec) {
+	*out = *in
+	if in.Proposed != nil {
+		in, out := &in.Proposed, &out.Proposed
+		*out = new(BlueprintSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ResourceTemplates != nil {
+		in, out := &in.ResourceTemplates, &out.ResourceTemplates
+		*out = make([]BlueprintPreviewResourceTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlueprintPreviewSpec.
+func (in *BlueprintPreviewSpec) DeepCopy() *BlueprintPreviewSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BlueprintPreviewSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BlueprintPreviewStatus) DeepCopyInto(out *BlueprintPreviewStatus) {
+	*out = *in
+	if in.Tenants != nil {
+		in, out := &in.Tenants, &out.Tenants
+		*out = make([]BlueprintPreviewTenantStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlueprintPreviewStatus.
+func (in *BlueprintPreviewStatus) DeepCopy() *BlueprintPreviewStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BlueprintPreviewStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BlueprintPreviewTenantStatus) DeepCopyInto(out *BlueprintPreviewTenantStatus) {
+	*out = *in
+	if in.Added != nil {
+		in, out := &in.Added, &out.Added
+		*out = make([]BlueprintPreviewResourceStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Updated != nil {
+		in, out := &in.Updated, &out.Updated
+		*out = make([]BlueprintPreviewResourceStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Removed != nil {
+		in, out := &in.Removed, &out.Removed
+		*out = make([]BlueprintPreviewResourceStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlueprintPreviewTenantStatus.
+func (in *BlueprintPreviewTenantStatus) DeepCopy() *BlueprintPreviewTenantStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BlueprintPreviewTenantStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BlueprintResourceGroup) DeepCopyInto(out *BlueprintResourceGroup) {
 	*out = *in
@@ -95,6 +319,16 @@ func (in *BlueprintResourceGroup) DeepCopyInto(out *BlueprintResourceGroup) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Condition != nil {
+		in, out := &in.Condition, &out.Condition
+		*out = new(BlueprintResourceGroupCondition)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DependsOn != nil {
+		in, out := &in.DependsOn, &out.DependsOn
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlueprintResourceGroup.
@@ -108,110 +342,672 @@ func (in *BlueprintResourceGroup) DeepCopy() *BlueprintResourceGroup {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *BlueprintSpec) DeepCopyInto(out *BlueprintSpec) {
+func (in *BlueprintResourceGroupCondition) DeepCopyInto(out *BlueprintResourceGroupCondition) {
 	*out = *in
-	if in.Resources != nil {
-		in, out := &in.Resources, &out.Resources
-		*out = make([]BlueprintResourceGroup, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlueprintSpec.
-func (in *BlueprintSpec) DeepCopy() *BlueprintSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlueprintResourceGroupCondition.
+func (in *BlueprintResourceGroupCondition) DeepCopy() *BlueprintResourceGroupCondition {
 	if in == nil {
 		return nil
 	}
-	out := new(BlueprintSpec)
+	out := new(BlueprintResourceGroupCondition)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *BlueprintStatus) DeepCopyInto(out *BlueprintStatus) {
+func (in *BlueprintResourceGroupStatus) DeepCopyInto(out *BlueprintResourceGroupStatus) {
 	*out = *in
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make([]BlueprintParameterStatus, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlueprintStatus.
-func (in *BlueprintStatus) DeepCopy() *BlueprintStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlueprintResourceGroupStatus.
+func (in *BlueprintResourceGroupStatus) DeepCopy() *BlueprintResourceGroupStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(BlueprintStatus)
+	out := new(BlueprintResourceGroupStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *BlueprintValueRef) DeepCopyInto(out *BlueprintValueRef) {
+func (in *BlueprintRevision) DeepCopyInto(out *BlueprintRevision) {
 	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlueprintValueRef.
-func (in *BlueprintValueRef) DeepCopy() *BlueprintValueRef {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlueprintRevision.
+func (in *BlueprintRevision) DeepCopy() *BlueprintRevision {
 	if in == nil {
 		return nil
 	}
-	out := new(BlueprintValueRef)
+	out := new(BlueprintRevision)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *BlueprintRevision) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *EmbeddedResource) DeepCopyInto(out *EmbeddedResource) {
+func (in *BlueprintRevisionList) DeepCopyInto(out *BlueprintRevisionList) {
 	*out = *in
-	in.RawExtension.DeepCopyInto(&out.RawExtension)
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]BlueprintRevision, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EmbeddedResource.
-func (in *EmbeddedResource) DeepCopy() *EmbeddedResource {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlueprintRevisionList.
+func (in *BlueprintRevisionList) DeepCopy() *BlueprintRevisionList {
 	if in == nil {
 		return nil
 	}
-	out := new(EmbeddedResource)
+	out := new(BlueprintRevisionList)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *BlueprintRevisionList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Parameter) DeepCopyInto(out *Parameter) {
+func (in *BlueprintRevisionSpec) DeepCopyInto(out *BlueprintRevisionSpec) {
 	*out = *in
+	in.Snapshot.DeepCopyInto(&out.Snapshot)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Parameter.
-func (in *Parameter) DeepCopy() *Parameter {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlueprintRevisionSpec.
+func (in *BlueprintRevisionSpec) DeepCopy() *BlueprintRevisionSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(Parameter)
+	out := new(BlueprintRevisionSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ParameterValue) DeepCopyInto(out *ParameterValue) {
+func (in *BlueprintRevisionStatus) DeepCopyInto(out *BlueprintRevisionStatus) {
 	*out = *in
-	if in.ValueFrom != nil {
-		in, out := &in.ValueFrom, &out.ValueFrom
-		*out = new(ValueRef)
-		(*in).DeepCopyInto(*out)
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ParameterValue.
-func (in *ParameterValue) DeepCopy() *ParameterValue {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlueprintRevisionStatus.
+func (in *BlueprintRevisionStatus) DeepCopy() *BlueprintRevisionStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(ParameterValue)
+	out := new(BlueprintRevisionStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ResourceSet) DeepCopyInto(out *ResourceSet) {
+func (in *BlueprintSpec) DeepCopyInto(out *BlueprintSpec) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]BlueprintResourceGroup, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Hooks != nil {
+		in, out := &in.Hooks, &out.Hooks
+		*out = make([]BlueprintHook, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.MaintenanceWindows != nil {
+		in, out := &in.MaintenanceWindows, &out.MaintenanceWindows
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.TenantSelector != nil {
+		in, out := &in.TenantSelector, &out.TenantSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlueprintSpec.
+func (in *BlueprintSpec) DeepCopy() *BlueprintSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BlueprintSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BlueprintStatus) DeepCopyInto(out *BlueprintStatus) {
+	*out = *in
+	if in.Ancestors != nil {
+		in, out := &in.Ancestors, &out.Ancestors
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Resolved != nil {
+		in, out := &in.Resolved, &out.Resolved
+		*out = new(BlueprintSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Tenants != nil {
+		in, out := &in.Tenants, &out.Tenants
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ResourceGroups != nil {
+		in, out := &in.ResourceGroups, &out.ResourceGroups
+		*out = make([]BlueprintResourceGroupStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ValidationErrors != nil {
+		in, out := &in.ValidationErrors, &out.ValidationErrors
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlueprintStatus.
+func (in *BlueprintStatus) DeepCopy() *BlueprintStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BlueprintStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BlueprintValueRef) DeepCopyInto(out *BlueprintValueRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlueprintValueRef.
+func (in *BlueprintValueRef) DeepCopy() *BlueprintValueRef {
+	if in == nil {
+		return nil
+	}
+	out := new(BlueprintValueRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EmbeddedResource) DeepCopyInto(out *EmbeddedResource) {
+	*out = *in
+	in.RawExtension.DeepCopyInto(&out.RawExtension)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EmbeddedResource.
+func (in *EmbeddedResource) DeepCopy() *EmbeddedResource {
+	if in == nil {
+		return nil
+	}
+	out := new(EmbeddedResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GeneratedValueRef) DeepCopyInto(out *GeneratedValueRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GeneratedValueRef.
+func (in *GeneratedValueRef) DeepCopy() *GeneratedValueRef {
+	if in == nil {
+		return nil
+	}
+	out := new(GeneratedValueRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HelmChartConfigMapRef) DeepCopyInto(out *HelmChartConfigMapRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HelmChartConfigMapRef.
+func (in *HelmChartConfigMapRef) DeepCopy() *HelmChartConfigMapRef {
+	if in == nil {
+		return nil
+	}
+	out := new(HelmChartConfigMapRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HelmChartRepositoryRef) DeepCopyInto(out *HelmChartRepositoryRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HelmChartRepositoryRef.
+func (in *HelmChartRepositoryRef) DeepCopy() *HelmChartRepositoryRef {
+	if in == nil {
+		return nil
+	}
+	out := new(HelmChartRepositoryRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HelmChartSource) DeepCopyInto(out *HelmChartSource) {
+	*out = *in
+	if in.ConfigMap != nil {
+		in, out := &in.ConfigMap, &out.ConfigMap
+		*out = new(HelmChartConfigMapRef)
+		**out = **in
+	}
+	if in.Repository != nil {
+		in, out := &in.Repository, &out.Repository
+		*out = new(HelmChartRepositoryRef)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HelmChartSource.
+func (in *HelmChartSource) DeepCopy() *HelmChartSource {
+	if in == nil {
+		return nil
+	}
+	out := new(HelmChartSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeyValueRef) DeepCopyInto(out *KeyValueRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeyValueRef.
+func (in *KeyValueRef) DeepCopy() *KeyValueRef {
+	if in == nil {
+		return nil
+	}
+	out := new(KeyValueRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Parameter) DeepCopyInto(out *Parameter) {
+	*out = *in
+	if in.Enum != nil {
+		in, out := &in.Enum, &out.Enum
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Minimum != nil {
+		in, out := &in.Minimum, &out.Minimum
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Maximum != nil {
+		in, out := &in.Maximum, &out.Maximum
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Parameter.
+func (in *Parameter) DeepCopy() *Parameter {
+	if in == nil {
+		return nil
+	}
+	out := new(Parameter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ParameterValue) DeepCopyInto(out *ParameterValue) {
+	*out = *in
+	if in.ValueFrom != nil {
+		in, out := &in.ValueFrom, &out.ValueFrom
+		*out = new(ValueRef)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ParameterValue.
+func (in *ParameterValue) DeepCopy() *ParameterValue {
+	if in == nil {
+		return nil
+	}
+	out := new(ParameterValue)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceSet) DeepCopyInto(out *ResourceSet) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceSet.
+func (in *ResourceSet) DeepCopy() *ResourceSet {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceSet)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ResourceSet) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceSetList) DeepCopyInto(out *ResourceSetList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ResourceSet, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceSetList.
+func (in *ResourceSetList) DeepCopy() *ResourceSetList {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceSetList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ResourceSetList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceSetResource) DeepCopyInto(out *ResourceSetResource) {
+	*out = *in
+	in.Embedded.DeepCopyInto(&out.Embedded)
+	if in.Source != nil {
+		in, out := &in.Source, &out.Source
+		*out = new(ResourceSource)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceSetResource.
+func (in *ResourceSetResource) DeepCopy() *ResourceSetResource {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceSetResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in ResourceSetResourceList) DeepCopyInto(out *ResourceSetResourceList) {
+	{
+		in := &in
+		*out = make(ResourceSetResourceList, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceSetResourceList.
+func (in ResourceSetResourceList) DeepCopy() ResourceSetResourceList {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceSetResourceList)
+	in.DeepCopyInto(out)
+	return *out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceSetResourceStatus) DeepCopyInto(out *ResourceSetResourceStatus) {
+	*out = *in
+	if in.Drift != nil {
+		in, out := &in.Drift, &out.Drift
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceSetResourceStatus.
+func (in *ResourceSetResourceStatus) DeepCopy() *ResourceSetResourceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceSetResourceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in ResourceSetResourceStatusList) DeepCopyInto(out *ResourceSetResourceStatusList) {
+	{
+		in := &in
+		*out = make(ResourceSetResourceStatusList, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceSetResourceStatusList.
+func (in ResourceSetResourceStatusList) DeepCopy() ResourceSetResourceStatusList {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceSetResourceStatusList)
+	in.DeepCopyInto(out)
+	return *out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceSetSpec) DeepCopyInto(out *ResourceSetSpec) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make(ResourceSetResourceList, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceSetSpec.
+func (in *ResourceSetSpec) DeepCopy() *ResourceSetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceSetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceSetStatus) DeepCopyInto(out *ResourceSetStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make(ResourceSetResourceStatusList, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Diff != nil {
+		in, out := &in.Diff, &out.Diff
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceSetStatus.
+func (in *ResourceSetStatus) DeepCopy() *ResourceSetStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceSetStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceSource) DeepCopyInto(out *ResourceSource) {
+	*out = *in
+	if in.Documents != nil {
+		in, out := &in.Documents, &out.Documents
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Helm != nil {
+		in, out := &in.Helm, &out.Helm
+		*out = new(HelmChartSource)
+		(*in).DeepCopyInto(*out)
+	}
+	in.Tenant.DeepCopyInto(&out.Tenant)
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make(ResourceTemplateParameterList, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(Parameter)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make([]ParameterValue, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceSource.
+func (in *ResourceSource) DeepCopy() *ResourceSource {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceSourceTenant) DeepCopyInto(out *ResourceSourceTenant) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceSourceTenant.
+func (in *ResourceSourceTenant) DeepCopy() *ResourceSourceTenant {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceSourceTenant)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceTemplate) DeepCopyInto(out *ResourceTemplate) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -219,18 +1015,18 @@ func (in *ResourceSet) DeepCopyInto(out *ResourceSet) {
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceSet.
-func (in *ResourceSet) DeepCopy() *ResourceSet {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceTemplate.
+func (in *ResourceTemplate) DeepCopy() *ResourceTemplate {
 	if in == nil {
 		return nil
 	}
-	out := new(ResourceSet)
+	out := new(ResourceTemplate)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ResourceSet) DeepCopyObject() runtime.Object {
+func (in *ResourceTemplate) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -238,31 +1034,31 @@ func (in *ResourceSet) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ResourceSetList) DeepCopyInto(out *ResourceSetList) {
+func (in *ResourceTemplateList) DeepCopyInto(out *ResourceTemplateList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]ResourceSet, len(*in))
+		*out = make([]ResourceTemplate, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceSetList.
-func (in *ResourceSetList) DeepCopy() *ResourceSetList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceTemplateList.
+func (in *ResourceTemplateList) DeepCopy() *ResourceTemplateList {
 	if in == nil {
 		return nil
 	}
-	out := new(ResourceSetList)
+	out := new(ResourceTemplateList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ResourceSetList) DeepCopyObject() runtime.Object {
+func (in *ResourceTemplateList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -270,88 +1066,47 @@ func (in *ResourceSetList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ResourceSetResource) DeepCopyInto(out *ResourceSetResource) {
+func (in *ResourceTemplateOutput) DeepCopyInto(out *ResourceTemplateOutput) {
 	*out = *in
-	in.Embedded.DeepCopyInto(&out.Embedded)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceSetResource.
-func (in *ResourceSetResource) DeepCopy() *ResourceSetResource {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceTemplateOutput.
+func (in *ResourceTemplateOutput) DeepCopy() *ResourceTemplateOutput {
 	if in == nil {
 		return nil
 	}
-	out := new(ResourceSetResource)
+	out := new(ResourceTemplateOutput)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in ResourceSetResourceList) DeepCopyInto(out *ResourceSetResourceList) {
+func (in ResourceTemplateParameterList) DeepCopyInto(out *ResourceTemplateParameterList) {
 	{
 		in := &in
-		*out = make(ResourceSetResourceList, len(*in))
+		*out = make(ResourceTemplateParameterList, len(*in))
 		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(Parameter)
+				(*in).DeepCopyInto(*out)
+			}
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceSetResourceList.
-func (in ResourceSetResourceList) DeepCopy() ResourceSetResourceList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceTemplateParameterList.
+func (in ResourceTemplateParameterList) DeepCopy() ResourceTemplateParameterList {
 	if in == nil {
 		return nil
 	}
-	out := new(ResourceSetResourceList)
+	out := new(ResourceTemplateParameterList)
 	in.DeepCopyInto(out)
 	return *out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ResourceSetSpec) DeepCopyInto(out *ResourceSetSpec) {
-	*out = *in
-	if in.Resources != nil {
-		in, out := &in.Resources, &out.Resources
-		*out = make(ResourceSetResourceList, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceSetSpec.
-func (in *ResourceSetSpec) DeepCopy() *ResourceSetSpec {
-	if in == nil {
-		return nil
-	}
-	out := new(ResourceSetSpec)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ResourceSetStatus) DeepCopyInto(out *ResourceSetStatus) {
-	*out = *in
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]v1.Condition, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceSetStatus.
-func (in *ResourceSetStatus) DeepCopy() *ResourceSetStatus {
-	if in == nil {
-		return nil
-	}
-	out := new(ResourceSetStatus)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ResourceTemplate) DeepCopyInto(out *ResourceTemplate) {
+func (in *ResourceTemplateRevision) DeepCopyInto(out *ResourceTemplateRevision) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -359,18 +1114,18 @@ func (in *ResourceTemplate) DeepCopyInto(out *ResourceTemplate) {
 	out.Status = in.Status
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceTemplate.
-func (in *ResourceTemplate) DeepCopy() *ResourceTemplate {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceTemplateRevision.
+func (in *ResourceTemplateRevision) DeepCopy() *ResourceTemplateRevision {
 	if in == nil {
 		return nil
 	}
-	out := new(ResourceTemplate)
+	out := new(ResourceTemplateRevision)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ResourceTemplate) DeepCopyObject() runtime.Object {
+func (in *ResourceTemplateRevision) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -378,31 +1133,31 @@ func (in *ResourceTemplate) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ResourceTemplateList) DeepCopyInto(out *ResourceTemplateList) {
+func (in *ResourceTemplateRevisionList) DeepCopyInto(out *ResourceTemplateRevisionList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]ResourceTemplate, len(*in))
+		*out = make([]ResourceTemplateRevision, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceTemplateList.
-func (in *ResourceTemplateList) DeepCopy() *ResourceTemplateList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceTemplateRevisionList.
+func (in *ResourceTemplateRevisionList) DeepCopy() *ResourceTemplateRevisionList {
 	if in == nil {
 		return nil
 	}
-	out := new(ResourceTemplateList)
+	out := new(ResourceTemplateRevisionList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ResourceTemplateList) DeepCopyObject() runtime.Object {
+func (in *ResourceTemplateRevisionList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -410,28 +1165,34 @@ func (in *ResourceTemplateList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in ResourceTemplateParameterList) DeepCopyInto(out *ResourceTemplateParameterList) {
-	{
-		in := &in
-		*out = make(ResourceTemplateParameterList, len(*in))
-		for i := range *in {
-			if (*in)[i] != nil {
-				in, out := &(*in)[i], &(*out)[i]
-				*out = new(Parameter)
-				**out = **in
-			}
-		}
+func (in *ResourceTemplateRevisionSpec) DeepCopyInto(out *ResourceTemplateRevisionSpec) {
+	*out = *in
+	in.Snapshot.DeepCopyInto(&out.Snapshot)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceTemplateRevisionSpec.
+func (in *ResourceTemplateRevisionSpec) DeepCopy() *ResourceTemplateRevisionSpec {
+	if in == nil {
+		return nil
 	}
+	out := new(ResourceTemplateRevisionSpec)
+	in.DeepCopyInto(out)
+	return out
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceTemplateParameterList.
-func (in ResourceTemplateParameterList) DeepCopy() ResourceTemplateParameterList {
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceTemplateRevisionStatus) DeepCopyInto(out *ResourceTemplateRevisionStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceTemplateRevisionStatus.
+func (in *ResourceTemplateRevisionStatus) DeepCopy() *ResourceTemplateRevisionStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(ResourceTemplateParameterList)
+	out := new(ResourceTemplateRevisionStatus)
 	in.DeepCopyInto(out)
-	return *out
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -460,7 +1221,7 @@ func (in *ResourceTemplateSpec) DeepCopyInto(out *ResourceTemplateSpec) {
 			if (*in)[i] != nil {
 				in, out := &(*in)[i], &(*out)[i]
 				*out = new(Parameter)
-				**out = **in
+				(*in).DeepCopyInto(*out)
 			}
 		}
 	}
@@ -476,6 +1237,16 @@ func (in *ResourceTemplateSpec) DeepCopyInto(out *ResourceTemplateSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Helm != nil {
+		in, out := &in.Helm, &out.Helm
+		*out = new(HelmChartSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Outputs != nil {
+		in, out := &in.Outputs, &out.Outputs
+		*out = make([]ResourceTemplateOutput, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceTemplateSpec.
@@ -491,6 +1262,23 @@ func (in *ResourceTemplateSpec) DeepCopy() *ResourceTemplateSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResourceTemplateStatus) DeepCopyInto(out *ResourceTemplateStatus) {
 	*out = *in
+	if in.Blueprints != nil {
+		in, out := &in.Blueprints, &out.Blueprints
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ValidationErrors != nil {
+		in, out := &in.ValidationErrors, &out.ValidationErrors
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceTemplateStatus.
@@ -523,7 +1311,7 @@ func (in *Tenant) DeepCopyInto(out *Tenant) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
@@ -545,6 +1333,61 @@ func (in *Tenant) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TenantAdoptionStatus) DeepCopyInto(out *TenantAdoptionStatus) {
+	*out = *in
+	if in.Diff != nil {
+		in, out := &in.Diff, &out.Diff
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TenantAdoptionStatus.
+func (in *TenantAdoptionStatus) DeepCopy() *TenantAdoptionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TenantAdoptionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TenantDeferredResourceGroupStatus) DeepCopyInto(out *TenantDeferredResourceGroupStatus) {
+	*out = *in
+	if in.WaitingFor != nil {
+		in, out := &in.WaitingFor, &out.WaitingFor
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TenantDeferredResourceGroupStatus.
+func (in *TenantDeferredResourceGroupStatus) DeepCopy() *TenantDeferredResourceGroupStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TenantDeferredResourceGroupStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TenantHookStatus) DeepCopyInto(out *TenantHookStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TenantHookStatus.
+func (in *TenantHookStatus) DeepCopy() *TenantHookStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TenantHookStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TenantList) DeepCopyInto(out *TenantList) {
 	*out = *in
@@ -577,9 +1420,80 @@ func (in *TenantList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TenantMaintenanceStatus) DeepCopyInto(out *TenantMaintenanceStatus) {
+	*out = *in
+	if in.NextWindow != nil {
+		in, out := &in.NextWindow, &out.NextWindow
+		*out = (*in).DeepCopy()
+	}
+	if in.Changes != nil {
+		in, out := &in.Changes, &out.Changes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TenantMaintenanceStatus.
+func (in *TenantMaintenanceStatus) DeepCopy() *TenantMaintenanceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TenantMaintenanceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TenantResourcesStatus) DeepCopyInto(out *TenantResourcesStatus) {
+	*out = *in
+	if in.NotReady != nil {
+		in, out := &in.NotReady, &out.NotReady
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Drifted != nil {
+		in, out := &in.Drifted, &out.Drifted
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TenantResourcesStatus.
+func (in *TenantResourcesStatus) DeepCopy() *TenantResourcesStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TenantResourcesStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TenantSpec) DeepCopyInto(out *TenantSpec) {
 	*out = *in
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make([]ParameterValue, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AddOns != nil {
+		in, out := &in.AddOns, &out.AddOns
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MaintenanceWindows != nil {
+		in, out := &in.MaintenanceWindows, &out.MaintenanceWindows
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.TTL != nil {
+		in, out := &in.TTL, &out.TTL
+		*out = new(v1.Duration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TenantSpec.
@@ -595,6 +1509,56 @@ func (in *TenantSpec) DeepCopy() *TenantSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TenantStatus) DeepCopyInto(out *TenantStatus) {
 	*out = *in
+	if in.AddOns != nil {
+		in, out := &in.AddOns, &out.AddOns
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Children != nil {
+		in, out := &in.Children, &out.Children
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Deferred != nil {
+		in, out := &in.Deferred, &out.Deferred
+		*out = make([]TenantDeferredResourceGroupStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(TenantResourcesStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Hooks != nil {
+		in, out := &in.Hooks, &out.Hooks
+		*out = make([]TenantHookStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Adoptions != nil {
+		in, out := &in.Adoptions, &out.Adoptions
+		*out = make([]TenantAdoptionStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Outputs != nil {
+		in, out := &in.Outputs, &out.Outputs
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Maintenance != nil {
+		in, out := &in.Maintenance, &out.Maintenance
+		*out = new(TenantMaintenanceStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExpiresAt != nil {
+		in, out := &in.ExpiresAt, &out.ExpiresAt
+		*out = (*in).DeepCopy()
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]v1.Condition, len(*in))
@@ -627,6 +1591,21 @@ func (in *ValueRef) DeepCopyInto(out *ValueRef) {
 		*out = new(ResourceValueRef)
 		**out = **in
 	}
+	if in.SecretKeyRef != nil {
+		in, out := &in.SecretKeyRef, &out.SecretKeyRef
+		*out = new(KeyValueRef)
+		**out = **in
+	}
+	if in.ConfigMapKeyRef != nil {
+		in, out := &in.ConfigMapKeyRef, &out.ConfigMapKeyRef
+		*out = new(KeyValueRef)
+		**out = **in
+	}
+	if in.Generated != nil {
+		in, out := &in.Generated, &out.Generated
+		*out = new(GeneratedValueRef)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ValueRef.
@@ -18,13 +18,28 @@ package core
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	kreconcile "sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	corev1alpha1 "github.com/kristofferahl/aeto/apis/core/v1alpha1"
 	"github.com/kristofferahl/aeto/internal/pkg/kubernetes"
+	"github.com/kristofferahl/aeto/internal/pkg/reconcile"
+	domain "github.com/kristofferahl/aeto/internal/pkg/tenant"
+	"github.com/kristofferahl/aeto/internal/pkg/util"
 )
 
 // BlueprintReconciler reconciles a Blueprint object
@@ -36,27 +51,206 @@ type BlueprintReconciler struct {
 //+kubebuilder:rbac:groups=core.aeto.net,resources=blueprints,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core.aeto.net,resources=blueprints/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=core.aeto.net,resources=blueprints/finalizers,verbs=update
+//+kubebuilder:rbac:groups=core.aeto.net,resources=blueprintrevisions,verbs=get;list;watch;create;delete
+//+kubebuilder:rbac:groups=core.aeto.net,resources=resourcetemplates,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core.aeto.net,resources=resourcetemplaterevisions,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core.aeto.net,resources=tenants,verbs=get;list;watch
 
-// Reconcile is part of the main kubernetes reconciliation loop which aims to
-// move the current state of the cluster closer to the desired state.
-// TODO(user): Modify the Reconcile function to compare the state specified by
-// the Blueprint object against the actual cluster state, and then
-// perform operations to make the cluster state reflect the state specified by
-// the user.
-//
-// For more details, check Reconcile and its Result here:
-// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.10.0/pkg/reconcile
+// Reconcile snapshots the spec of the Blueprint into a BlueprintRevision, resolves the blueprints extended by the Blueprint,
+// validates the resolved spec and reports the resolved spec, the resolved parameters and the Tenants using it in its status
 func (r *BlueprintReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	_ = log.FromContext(ctx)
+	rctx := reconcile.NewContext("blueprint", req, log.FromContext(ctx))
+	rctx.Log.Info("reconciling")
 
-	// your logic here
+	var blueprint corev1alpha1.Blueprint
+	if err := r.Get(rctx, req.NamespacedName, &blueprint); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
 
-	return ctrl.Result{}, nil
+	revision, err := ensureBlueprintRevision(rctx, r.Client, blueprint)
+	if err != nil {
+		return rctx.Complete(rctx.Error(err))
+	}
+	blueprint.Status.Revision = revision
+
+	if err := pruneBlueprintRevisions(rctx, r.Client, blueprint, revision); err != nil {
+		rctx.Log.Error(err, "failed to prune BlueprintRevisions")
+		return rctx.Complete(rctx.Error(err))
+	}
+
+	tenants, err := r.consumers(rctx, blueprint)
+	if err != nil {
+		return rctx.Complete(rctx.Error(err))
+	}
+	blueprint.Status.Tenants = make([]string, 0)
+	for _, t := range tenants {
+		blueprint.Status.Tenants = append(blueprint.Status.Tenants, t.NamespacedName().String())
+	}
+
+	readyCondition := metav1.Condition{
+		Type:   ConditionTypeReady,
+		Status: metav1.ConditionTrue,
+		Reason: "Valid",
+	}
+
+	resolved, err := resolveBlueprint(rctx, r.Client, req.NamespacedName)
+	blueprint.Status.Ancestors = resolved.Ancestors
+	if err != nil {
+		rctx.Log.V(1).Info("failed to resolve Blueprint", "error", err.Error())
+		blueprint.Status.Resolved = nil
+		blueprint.Status.ResourceGroups = nil
+		blueprint.Status.ValidationErrors = nil
+		readyCondition.Status = metav1.ConditionFalse
+		readyCondition.Reason = "ResolveFailed"
+		readyCondition.Message = err.Error()
+	} else {
+		blueprint.Status.Resolved = resolved.Spec.DeepCopy()
+		blueprint.Status.ResourceGroups, blueprint.Status.ValidationErrors = domain.ValidateBlueprint(resolved.Blueprint, tenants, templateSpec(rctx, r.Client, blueprint.Namespace))
+		if len(blueprint.Status.ValidationErrors) > 0 {
+			rctx.Log.V(1).Info("Blueprint validation failed", "errors", blueprint.Status.ValidationErrors)
+			readyCondition.Status = metav1.ConditionFalse
+			readyCondition.Reason = "ValidationFailed"
+			readyCondition.Message = fmt.Sprintf("%d validation error(s)", len(blueprint.Status.ValidationErrors))
+		}
+	}
+	apimeta.SetStatusCondition(&blueprint.Status.Conditions, readyCondition)
+
+	if err := r.UpdateStatus(rctx, &blueprint); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return rctx.Complete(rctx.Done())
+}
+
+// consumers returns the tenants, with inherited parameters, using the blueprint or a blueprint extending it
+func (r *BlueprintReconciler) consumers(ctx reconcile.Context, blueprint corev1alpha1.Blueprint) ([]corev1alpha1.Tenant, error) {
+	descendants, err := blueprintDescendants(ctx.Context, r.Client.GetClient(), blueprint.Namespace, blueprint.Name)
+	if err != nil {
+		return nil, err
+	}
+	names := []string{blueprint.NamespacedName().String()}
+	for _, d := range descendants {
+		names = append(names, d.NamespacedName().String())
+	}
+
+	var list corev1alpha1.TenantList
+	if err := r.List(ctx, &list); err != nil {
+		return nil, err
+	}
+
+	tenants := make([]corev1alpha1.Tenant, 0)
+	for _, t := range domain.InheritedTenants(list.Items) {
+		if t.UsesBlueprint(names...) {
+			tenants = append(tenants, t)
+		}
+	}
+	return tenants, nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *BlueprintReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&corev1alpha1.Blueprint{}).
+		For(&corev1alpha1.Blueprint{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(
+			&source.Kind{Type: &corev1alpha1.Blueprint{}},
+			handler.EnqueueRequestsFromMapFunc(r.findDescendantBlueprints),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{}),
+		).
+		Watches(
+			&source.Kind{Type: &corev1alpha1.ResourceTemplate{}},
+			handler.EnqueueRequestsFromMapFunc(r.findBlueprintsForResourceTemplate),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{}),
+		).
+		Watches(
+			&source.Kind{Type: &corev1alpha1.Tenant{}},
+			handler.EnqueueRequestsFromMapFunc(r.findBlueprintsForTenant),
+			builder.WithPredicates(predicate.Funcs{UpdateFunc: tenantBlueprintChanged}),
+		).
 		Complete(r)
 }
+
+func (r *BlueprintReconciler) findDescendantBlueprints(o client.Object) []kreconcile.Request {
+	descendants, err := blueprintDescendants(context.TODO(), r.Client.GetClient(), o.GetNamespace(), o.GetName())
+	if err != nil {
+		return []kreconcile.Request{}
+	}
+	return blueprintRequests(descendants)
+}
+
+func (r *BlueprintReconciler) findBlueprintsForResourceTemplate(o client.Object) []kreconcile.Request {
+	var list corev1alpha1.BlueprintList
+	if err := r.Client.GetClient().List(context.TODO(), &list, client.InNamespace(o.GetNamespace())); err != nil {
+		return []kreconcile.Request{}
+	}
+
+	names := make([]string, 0)
+	blueprints := make([]corev1alpha1.Blueprint, 0)
+	for _, b := range list.Items {
+		if util.SliceContainsString(b.Templates(), o.GetName()) {
+			names = append(names, b.Name)
+			blueprints = append(blueprints, b)
+		}
+	}
+
+	descendants, err := blueprintDescendants(context.TODO(), r.Client.GetClient(), o.GetNamespace(), names...)
+	if err != nil {
+		return []kreconcile.Request{}
+	}
+	return blueprintRequests(append(blueprints, descendants...))
+}
+
+func (r *BlueprintReconciler) findBlueprintsForTenant(o client.Object) []kreconcile.Request {
+	tenant := o.(*corev1alpha1.Tenant)
+
+	requests := make([]kreconcile.Request, 0)
+	seen := make(map[string]bool)
+	for _, nn := range append([]string{tenant.Status.Blueprint}, tenant.Status.AddOns...) {
+		parts := strings.Split(nn, string(types.Separator))
+		if len(parts) != 2 {
+			continue
+		}
+
+		// Tenants are consumers of the blueprints extended by their blueprints
+		for name := parts[1]; name != "" && !seen[name]; {
+			seen[name] = true
+			requests = append(requests, kreconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: parts[0], Name: name},
+			})
+
+			var b corev1alpha1.Blueprint
+			if err := r.Client.GetClient().Get(context.TODO(), types.NamespacedName{Namespace: parts[0], Name: name}, &b); err != nil {
+				break
+			}
+			name = b.Spec.Extends
+		}
+	}
+	return requests
+}
+
+// tenantBlueprintChanged returns true when the blueprints specified or used by the tenant changed, other changes of the
+// tenant do not affect its blueprints
+func tenantBlueprintChanged(e event.UpdateEvent) bool {
+	oldTenant, ok := e.ObjectOld.(*corev1alpha1.Tenant)
+	if !ok {
+		return true
+	}
+	newTenant, ok := e.ObjectNew.(*corev1alpha1.Tenant)
+	if !ok {
+		return true
+	}
+
+	return newTenant.BlueprintsChanged(*oldTenant)
+}
+
+func blueprintRequests(blueprints []corev1alpha1.Blueprint) []kreconcile.Request {
+	requests := make([]kreconcile.Request, 0)
+	for _, b := range blueprints {
+		requests = append(requests, kreconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Name:      b.Name,
+				Namespace: b.Namespace,
+			},
+		})
+	}
+	return requests
+}
@@ -0,0 +1,108 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	corev1alpha1 "github.com/kristofferahl/aeto/apis/core/v1alpha1"
+	"github.com/kristofferahl/aeto/internal/pkg/kubernetes"
+	"github.com/kristofferahl/aeto/internal/pkg/reconcile"
+	domain "github.com/kristofferahl/aeto/internal/pkg/tenant"
+	"github.com/kristofferahl/aeto/internal/pkg/util"
+)
+
+// resolvedBlueprint is a blueprint merged with the blueprints it extends
+type resolvedBlueprint struct {
+	corev1alpha1.Blueprint
+
+	// Ancestors is the names of the extended blueprints, nearest first
+	Ancestors []string
+
+	// Revisions is the revision names of the blueprint and the extended blueprints
+	Revisions []string
+}
+
+// resolveBlueprint fetches a blueprint and merges it with the blueprints it extends. Blueprints in the overrides replace
+// the blueprint with the same name.
+func resolveBlueprint(ctx reconcile.Context, k8s kubernetes.Client, nn types.NamespacedName, overrides ...corev1alpha1.Blueprint) (resolvedBlueprint, error) {
+	get := func(name string) (corev1alpha1.Blueprint, error) {
+		var blueprint corev1alpha1.Blueprint
+		if err := k8s.Get(ctx, types.NamespacedName{Namespace: nn.Namespace, Name: name}, &blueprint); err != nil {
+			return blueprint, err
+		}
+		for _, o := range overrides {
+			if o.Name == blueprint.Name {
+				blueprint.Spec = *o.Spec.DeepCopy()
+			}
+		}
+		return blueprint, nil
+	}
+
+	blueprint, err := get(nn.Name)
+	if err != nil {
+		return resolvedBlueprint{Blueprint: blueprint}, err
+	}
+
+	res := resolvedBlueprint{
+		Blueprint: blueprint,
+		Ancestors: make([]string, 0),
+		Revisions: make([]string, 0),
+	}
+	chain := []corev1alpha1.Blueprint{blueprint}
+	visited := []string{blueprint.Name}
+
+	for name := blueprint.Spec.Extends; name != ""; {
+		if util.SliceContainsString(visited, name) {
+			return res, fmt.Errorf("blueprint %s extends %s which creates a cycle (%s -> %s)", nn.Name, name, strings.Join(visited, " -> "), name)
+		}
+		visited = append(visited, name)
+
+		parent, err := get(name)
+		if err != nil {
+			return res, fmt.Errorf("failed to get blueprint %s extended by %s, %w", name, chain[len(chain)-1].Name, err)
+		}
+
+		chain = append(chain, parent)
+		res.Ancestors = append(res.Ancestors, parent.Name)
+		name = parent.Spec.Extends
+	}
+
+	for _, b := range chain {
+		hash, err := domain.RevisionHash(b.Spec)
+		if err != nil {
+			return res, err
+		}
+		res.Revisions = append(res.Revisions, domain.RevisionName(b.Name, hash))
+	}
+
+	resolved := chain[len(chain)-1]
+	for i := len(chain) - 2; i >= 0; i-- {
+		resolved, err = chain[i].Extend(resolved)
+		if err != nil {
+			return res, err
+		}
+	}
+
+	for _, rg := range resolved.Spec.Resources {
+		if rg.Template == "" {
+			return res, fmt.Errorf("resource group %s of blueprint %s has no template", rg.Name, nn.Name)
+		}
+	}
+
+	res.Blueprint = resolved
+	return res, nil
+}
+
+// blueprintDescendants returns the blueprints extending, directly or indirectly, any of the named blueprints
+func blueprintDescendants(ctx context.Context, c client.Client, namespace string, names ...string) ([]corev1alpha1.Blueprint, error) {
+	blueprints := &corev1alpha1.BlueprintList{}
+	if err := c.List(ctx, blueprints, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+
+	return blueprints.Descendants(names...), nil
+}
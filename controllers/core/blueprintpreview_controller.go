@@ -0,0 +1,276 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+	"fmt"
+
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	kreconcile "sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	corev1alpha1 "github.com/kristofferahl/aeto/apis/core/v1alpha1"
+	"github.com/kristofferahl/aeto/internal/pkg/config"
+	"github.com/kristofferahl/aeto/internal/pkg/eventstore"
+	"github.com/kristofferahl/aeto/internal/pkg/kubernetes"
+	"github.com/kristofferahl/aeto/internal/pkg/reconcile"
+	domain "github.com/kristofferahl/aeto/internal/pkg/tenant"
+	"github.com/kristofferahl/aeto/internal/pkg/util"
+)
+
+const (
+	maxPreviewDiffEntries = 50
+)
+
+// BlueprintPreviewReconciler reconciles a BlueprintPreview object
+type BlueprintPreviewReconciler struct {
+	kubernetes.Client
+	Scheme *runtime.Scheme
+}
+
+//+kubebuilder:rbac:groups=core.aeto.net,resources=blueprintpreviews,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=core.aeto.net,resources=blueprintpreviews/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=core.aeto.net,resources=blueprintpreviews/finalizers,verbs=update
+
+// Reconcile generates the resources of the tenants affected by the proposed changes and reports
+// the resources that would be added, updated or removed. No events are committed for the tenants.
+func (r *BlueprintPreviewReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	rctx := reconcile.NewContext("blueprintpreview", req, log.FromContext(ctx))
+	rctx.Log.Info("reconciling")
+
+	var preview corev1alpha1.BlueprintPreview
+	if err := r.Get(rctx, req.NamespacedName, &preview); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	var tenants corev1alpha1.TenantList
+	if err := r.List(rctx, &tenants); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	templates := make([]corev1alpha1.ResourceTemplate, 0)
+	for _, rt := range preview.Spec.ResourceTemplates {
+		templates = append(templates, corev1alpha1.ResourceTemplate{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: config.Operator.Namespace,
+				Name:      rt.Name,
+			},
+			Spec: *rt.Spec.DeepCopy(),
+		})
+	}
+	generator := domain.NewResourceGenerator(rctx, domain.ResourceGeneratoreServices{Client: r.Client}).WithTemplates(templates...).DryRun()
+
+	store := eventstore.New(r.Client.GetClient(), rctx.Log, rctx.Context, serializer)
+
+	preview.Status.Tenants = nil
+	changed := 0
+	for _, tenant := range tenants.Items {
+		stream, err := store.Get(eventstore.StreamId(tenant.NamespacedName().String()))
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if stream.Length() == 0 {
+			continue
+		}
+
+		t := domain.NewTenantFromEvents(stream)
+
+		status := corev1alpha1.BlueprintPreviewTenantStatus{
+			Tenant: tenant.NamespacedName().String(),
+		}
+
+		rp, affected, err := r.previewTenant(rctx, preview, t, generator)
+		if !affected {
+			continue
+		}
+		if err != nil {
+			rctx.Log.V(1).Info("failed to preview Tenant", "tenant", status.Tenant, "error", err.Error())
+			status.Error = err.Error()
+		} else {
+			status.Added = previewResources(rp.Added)
+			status.Updated = previewResources(rp.Updated)
+			status.Removed = previewResources(rp.Removed)
+		}
+
+		if status.Error != "" || len(status.Added) > 0 || len(status.Updated) > 0 || len(status.Removed) > 0 {
+			changed++
+		}
+		preview.Status.Tenants = append(preview.Status.Tenants, status)
+	}
+
+	readyCondition := metav1.Condition{
+		Type:    ConditionTypeReady,
+		Status:  metav1.ConditionTrue,
+		Reason:  "Previewed",
+		Message: fmt.Sprintf("%d of %d tenant(s) affected", changed, len(preview.Status.Tenants)),
+	}
+	apimeta.SetStatusCondition(&preview.Status.Conditions, readyCondition)
+	preview.Status.Status = "Previewed"
+	preview.Status.ObservedGeneration = preview.Generation
+
+	if err := r.UpdateStatus(rctx, &preview); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return rctx.Complete(rctx.Done())
+}
+
+// previewTenant generates the resources of a tenant from its blueprint and add-ons, with the proposed changes applied.
+// Tenants are affected when the previewed blueprint is their blueprint, one of their add-ons or extended by any of them.
+func (r *BlueprintPreviewReconciler) previewTenant(ctx reconcile.Context, preview corev1alpha1.BlueprintPreview, t *domain.TenantAggregate, generator domain.ResourceGenerator) (domain.ResourcePreview, bool, error) {
+	overrides := make([]corev1alpha1.Blueprint, 0)
+	if preview.Spec.Proposed != nil {
+		overrides = append(overrides, corev1alpha1.Blueprint{
+			ObjectMeta: metav1.ObjectMeta{Name: preview.Spec.Blueprint},
+			Spec:       *preview.Spec.Proposed.DeepCopy(),
+		})
+	}
+
+	affected := preview.Spec.Blueprint == ""
+	resolve := func(nn types.NamespacedName) (corev1alpha1.Blueprint, error) {
+		resolved, err := resolveBlueprint(ctx, r.Client, nn, overrides...)
+		if nn.Name == preview.Spec.Blueprint || util.SliceContainsString(resolved.Ancestors, preview.Spec.Blueprint) {
+			affected = true
+		}
+		return resolved.Blueprint, err
+	}
+
+	blueprint, err := resolve(t.Blueprint())
+	if err != nil {
+		return domain.ResourcePreview{}, affected, err
+	}
+
+	addOns := make([]corev1alpha1.Blueprint, 0)
+	for _, name := range t.AddOns() {
+		addOn, err := resolve(types.NamespacedName{Namespace: t.Blueprint().Namespace, Name: name})
+		if err != nil {
+			return domain.ResourcePreview{}, affected, err
+		}
+		addOns = append(addOns, addOn)
+	}
+
+	if !affected {
+		return domain.ResourcePreview{}, false, nil
+	}
+
+	composed, err := blueprint.Compose(addOns...)
+	if err != nil {
+		return domain.ResourcePreview{}, true, err
+	}
+
+	rp, err := t.Preview(generator, composed)
+	return rp, true, err
+}
+
+func previewResources(changes []domain.ResourceChange) []corev1alpha1.BlueprintPreviewResourceStatus {
+	resources := make([]corev1alpha1.BlueprintPreviewResourceStatus, 0)
+	for _, change := range changes {
+		resources = append(resources, corev1alpha1.BlueprintPreviewResourceStatus{
+			Resource: resourceDescription(change.Kind, change.Namespace, change.Name),
+			Diff:     util.LimitStrings(change.Diff, maxPreviewDiffEntries),
+		})
+	}
+	return resources
+}
+
+// SetupWithManager sets up the controller with the Manager. Previews are refreshed when the Tenants, Blueprints and
+// ResourceTemplates they are generated from change, and on every reconcile interval.
+func (r *BlueprintPreviewReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&corev1alpha1.BlueprintPreview{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(
+			&source.Kind{Type: &corev1alpha1.Tenant{}},
+			handler.EnqueueRequestsFromMapFunc(r.findPreviewsForTenant),
+			builder.WithPredicates(predicate.Funcs{UpdateFunc: tenantPreviewChanged}),
+		).
+		Watches(
+			&source.Kind{Type: &corev1alpha1.Blueprint{}},
+			handler.EnqueueRequestsFromMapFunc(r.findPreviewsForBlueprint),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{}),
+		).
+		Watches(
+			&source.Kind{Type: &corev1alpha1.ResourceTemplate{}},
+			handler.EnqueueRequestsFromMapFunc(r.findPreviewsForResourceTemplate),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{}),
+		).
+		Complete(r)
+}
+
+func (r *BlueprintPreviewReconciler) findPreviewsForTenant(o client.Object) []kreconcile.Request {
+	return r.findPreviews(func(bp corev1alpha1.BlueprintPreview) bool {
+		return true
+	})
+}
+
+func (r *BlueprintPreviewReconciler) findPreviewsForBlueprint(o client.Object) []kreconcile.Request {
+	if o.GetNamespace() != config.Operator.Namespace {
+		return []kreconcile.Request{}
+	}
+	return r.findPreviews(func(bp corev1alpha1.BlueprintPreview) bool {
+		return bp.AffectedByBlueprint(o.GetName())
+	})
+}
+
+func (r *BlueprintPreviewReconciler) findPreviewsForResourceTemplate(o client.Object) []kreconcile.Request {
+	if o.GetNamespace() != config.Operator.Namespace {
+		return []kreconcile.Request{}
+	}
+	return r.findPreviews(func(bp corev1alpha1.BlueprintPreview) bool {
+		return bp.AffectedByResourceTemplate(o.GetName())
+	})
+}
+
+func (r *BlueprintPreviewReconciler) findPreviews(affected func(bp corev1alpha1.BlueprintPreview) bool) []kreconcile.Request {
+	var list corev1alpha1.BlueprintPreviewList
+	if err := r.Client.GetClient().List(context.TODO(), &list); err != nil {
+		return []kreconcile.Request{}
+	}
+
+	requests := make([]kreconcile.Request, 0)
+	for _, bp := range list.Items {
+		if affected(bp) {
+			requests = append(requests, kreconcile.Request{NamespacedName: bp.NamespacedName()})
+		}
+	}
+	return requests
+}
+
+// tenantPreviewChanged returns true when the blueprints or the events of the tenant changed, previews are generated
+// from the blueprints and the event stream of the tenant
+func tenantPreviewChanged(e event.UpdateEvent) bool {
+	oldTenant, ok := e.ObjectOld.(*corev1alpha1.Tenant)
+	if !ok {
+		return true
+	}
+	newTenant, ok := e.ObjectNew.(*corev1alpha1.Tenant)
+	if !ok {
+		return true
+	}
+
+	return tenantBlueprintChanged(e) || oldTenant.Status.Events != newTenant.Status.Events
+}
@@ -5,4 +5,9 @@ const (
 	ConditionTypeTerminating string = "Terminating"
 	ConditionTypeReady       string = "Ready"
 	ConditionTypeActive      string = "Active"
+	ConditionTypeExpiring    string = "Expiring"
+	ConditionTypeDrifted     string = "Drifted"
+	ConditionTypeApproved    string = "Approved"
+
+	PhasePendingApproval string = "PendingApproval"
 )
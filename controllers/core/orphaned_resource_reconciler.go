@@ -11,6 +11,7 @@ import (
 func ReconcileOrphanedResources(ctx reconcile.Context, k8s kubernetes.Client, stream eventsource.Stream) reconcile.Result {
 	state := orhanedResourceState{
 		Active:  tenant.ResourceList{},
+		Removed: tenant.ResourceList{},
 		Deleted: tenant.ResourceList{},
 	}
 
@@ -28,6 +29,16 @@ func ReconcileOrphanedResources(ctx reconcile.Context, k8s kubernetes.Client, st
 			continue
 		}
 
+		retained, err := r.Retained()
+		if err != nil {
+			ctx.Log.Error(err, "failed to read retain policy of orphaned resource", "nn", ri.NamespacedName.String(), "gvk", ri.GroupVersionKind.String())
+			continue
+		}
+		if retained {
+			ctx.Log.V(1).Info("retaining orphaned resource", "nn", ri.NamespacedName.String(), "gvk", ri.GroupVersionKind.String())
+			continue
+		}
+
 		// TODO: Make sure the resource is actually owned/created by the aeto tenant
 		ctx.Log.V(1).Info("making sure orphaned resource is deleted", "nn", ri.NamespacedName.String(), "gvk", ri.GroupVersionKind.String())
 		if err := k8s.DynamicDelete(ctx, ri.NamespacedName, ri.GroupVersionKind); client.IgnoreNotFound(err) != nil {
@@ -45,6 +56,7 @@ type OrphanedResourceEventHandler struct {
 type orhanedResourceState struct {
 	DeleteAllowed bool
 	Deleted       tenant.ResourceList
+	Removed       tenant.ResourceList
 	Active        tenant.ResourceList
 }
 
@@ -60,12 +72,23 @@ func (h *OrphanedResourceEventHandler) On(e eventsource.Event) {
 		h.state.Active = append(h.state.Active, event.Resource)
 		index, _ := h.state.Deleted.Find(event.Resource.Id)
 		h.state.Deleted = h.state.Deleted.Remove(index)
+		index, _ = h.state.Removed.Find(event.Resource.Id)
+		h.state.Removed = h.state.Removed.Remove(index)
+	case *tenant.ResourceUpdated:
+		index, _ := h.state.Active.Find(event.Resource.Id)
+		if index >= 0 {
+			h.state.Active[index] = event.Resource
+		}
 	case *tenant.ResourceRemoved:
 		index, r := h.state.Active.Find(event.ResourceId)
 		if index >= 0 && r != nil {
 			h.state.Active = h.state.Active.Remove(index)
-			h.state.Deleted = append(h.state.Deleted, *r)
+			h.state.Removed = append(h.state.Removed, *r)
 		}
+	case *tenant.ResourceSetActivated:
+		// Removed resources are deleted once the resource set without them is activated
+		h.state.Deleted = append(h.state.Deleted, h.state.Removed...)
+		h.state.Removed = tenant.ResourceList{}
 	case *tenant.ResourceGenererationFailed:
 		h.state.DeleteAllowed = false
 	case *tenant.ResourceGenererationSuccessful:
@@ -20,8 +20,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"strings"
 
+	"k8s.io/apimachinery/pkg/api/equality"
 	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -38,10 +38,15 @@ import (
 	"github.com/kristofferahl/aeto/internal/pkg/convert"
 	"github.com/kristofferahl/aeto/internal/pkg/kubernetes"
 	"github.com/kristofferahl/aeto/internal/pkg/reconcile"
+	domain "github.com/kristofferahl/aeto/internal/pkg/tenant"
+	"github.com/kristofferahl/aeto/internal/pkg/util"
 )
 
 const (
 	ResourceSetFinalizerName = "resourceset.core.aeto.net/finalizer"
+
+	maxDriftEntries        = 20
+	maxApprovalDiffEntries = 50
 )
 
 // ResourceSetReconciler reconciles a ResourceSet object
@@ -53,6 +58,7 @@ type ResourceSetReconciler struct {
 //+kubebuilder:rbac:groups=core.aeto.net,resources=resourcesets,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core.aeto.net,resources=resourcesets/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=core.aeto.net,resources=resourcesets/finalizers,verbs=update
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -93,25 +99,53 @@ func (r *ResourceSetReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	}
 
 	results := reconcile.ResultList{}
+	resources := newResourceStatusList(rctx, resourceSet.Spec.Resources)
 
 	if resourceSet.Spec.Active {
-		for _, resource := range resourceSet.Spec.Resources {
-			result := r.applyResource(rctx, resource.Embedded)
-			results = append(results, result)
+		specChanged := resourceSet.Generation != resourceSet.Status.ObservedGeneration
+		for i, resource := range resourceSet.Spec.Resources {
+			if !specChanged {
+				drift, err := r.detectDrift(rctx, resource)
+				if err != nil {
+					resources[i].Error = err.Error()
+					results = append(results, rctx.Error(err))
+					continue
+				}
+				resources[i].Drift = drift
+				if len(drift) > 0 && resourceSet.Spec.DriftPolicy == corev1alpha1.DriftPolicyReportOnly {
+					rctx.Log.Info("resource has drifted from the desired state, skipping apply", "id", resource.Id, "drift", drift)
+					resources[i].Applied = true
+					results = append(results, rctx.Done())
+					continue
+				}
+			}
+			if err := r.applyResource(rctx, resource); err != nil {
+				resources[i].Error = err.Error()
+				results = append(results, rctx.Error(err))
+				continue
+			}
+			resources[i].Applied = true
+			results = append(results, rctx.Done())
 		}
 	} else {
 		rctx.Log.Info("ResourceSet inactive, skipping reconcile of resources")
 	}
 
+	resourceSet.Status.Resources = resources
 	results = append(results, r.reconcileStatus(rctx, resourceSet, corev1alpha1.ResourceSetReconciling, results.AllDone()))
 
 	return rctx.Complete(results...)
 }
 
-func (r *ResourceSetReconciler) applyResource(ctx reconcile.Context, resource corev1alpha1.EmbeddedResource) reconcile.Result {
-	b, err := json.Marshal(resource)
+func (r *ResourceSetReconciler) applyResource(ctx reconcile.Context, resource corev1alpha1.ResourceSetResource) error {
+	desired, err := r.resolveResource(ctx, resource)
 	if err != nil {
-		return ctx.Error(err)
+		return err
+	}
+
+	b, err := desired.MarshalJSON()
+	if err != nil {
+		return err
 	}
 
 	manifest := string(b)
@@ -133,11 +167,59 @@ func (r *ResourceSetReconciler) applyResource(ctx reconcile.Context, resource co
 
 	err = r.DynamicApply(ctx, resourceRef, manifest)
 	if err != nil {
-		ctx.Log.Error(err, "failed to apply resource from ResourceSet", "resource", resource)
-		return ctx.Error(err)
+		ctx.Log.Error(err, "failed to apply resource from ResourceSet", "resource", resourceRef, "id", resource.Id)
+		return err
 	}
 
-	return ctx.Done()
+	return nil
+}
+
+// detectDrift returns the paths of the fields owned by aeto, according to the managed fields of the live resource, that differ
+// between the live resource and the embedded manifest
+func (r *ResourceSetReconciler) detectDrift(ctx reconcile.Context, resource corev1alpha1.ResourceSetResource) ([]string, error) {
+	desired, err := r.resolveResource(ctx, resource)
+	if err != nil {
+		return nil, err
+	}
+
+	nn := types.NamespacedName{
+		Namespace: desired.GetNamespace(),
+		Name:      desired.GetName(),
+	}
+
+	live, err := r.DynamicGet(ctx, nn, desired.GroupVersionKind())
+	if err != nil {
+		return nil, err
+	}
+	if live == nil {
+		return nil, nil
+	}
+
+	drift, err := kubernetes.Drift(desired, live)
+	if err != nil {
+		return nil, err
+	}
+
+	return util.LimitStrings(drift, maxDriftEntries), nil
+}
+
+// resolveResource returns the embedded manifest with references to values of secrets replaced with the values, sensitive
+// resources are rendered from their source
+func (r *ResourceSetReconciler) resolveResource(ctx reconcile.Context, resource corev1alpha1.ResourceSetResource) (*unstructured.Unstructured, error) {
+	desired, err := convert.RawExtensionToUnstructured(resource.Embedded.RawExtension)
+	if err != nil {
+		return nil, err
+	}
+
+	if resource.Source != nil {
+		return domain.RenderResource(ctx, domain.ResourceGeneratoreServices{Client: r.Client}, desired, *resource.Source)
+	}
+
+	if err := r.ResolveSecretReferences(ctx, desired.Object); err != nil {
+		return nil, err
+	}
+
+	return desired, nil
 }
 
 func (r *ResourceSetReconciler) reconcileDelete(ctx reconcile.Context, resourceSet corev1alpha1.ResourceSet) reconcile.Result {
@@ -163,6 +245,12 @@ func (r *ResourceSetReconciler) reconcileDelete(ctx reconcile.Context, resourceS
 			Namespace: unstructured.GetNamespace(),
 		}
 
+		if unstructured.GetAnnotations()[corev1alpha1.AnnotationRetainPolicy] == string(corev1alpha1.RetainPolicyRetain) {
+			ctx.Log.V(1).Info("retaining resource belonging to ResourceSet", "id", resource.Id, "nn", nn.String(), "gvk", unstructured.GroupVersionKind().String())
+			results = append(results, ctx.Done())
+			continue
+		}
+
 		if err := r.DynamicDelete(ctx, nn, unstructured.GroupVersionKind()); err != nil {
 			results = append(results, ctx.Error(err))
 			continue
@@ -193,44 +281,27 @@ func (r *ResourceSetReconciler) reconcileDelete(ctx reconcile.Context, resourceS
 	return ctx.Done()
 }
 
-func (r *ResourceSetReconciler) checkResourceReady(ctx reconcile.Context, resource corev1alpha1.EmbeddedResource) (checked bool, ready bool) {
+func (r *ResourceSetReconciler) checkResourceReady(ctx reconcile.Context, resource corev1alpha1.EmbeddedResource) (checked bool, ready bool, err error) {
 	ri, err := convert.RawExtensionToResourceIdentifier(resource.RawExtension)
 	if err != nil {
 		ctx.Log.Error(err, "failed to convert resource to resource identifier")
-		return false, false
+		return false, false, err
 	}
 
 	ur, err := r.DynamicGet(ctx, ri.NamespacedName, ri.GroupVersionKind)
 	if err != nil {
-		return false, false
+		return false, false, err
 	}
 
-	status, found, err := unstructured.NestedMap(ur.Object, "status")
-	if err != nil || !found {
-		return false, false
-	}
-
-	readyCondition, err := jsonpath.Get("$.conditions[?(@.type == \"Ready\")].status", status)
-	if err == nil {
-		results := readyCondition.([]interface{})
-		if len(results) == 1 {
-			ready := strings.ToLower(fmt.Sprintf("%s", results[0])) == "true"
-			ctx.Log.V(1).Info("checking resource readiness using ready condition", "ready", ready, "nn", ri.NamespacedName.String(), "gvk", ri.GroupVersionKind)
-			return true, ready
-		}
+	if ur == nil {
+		return false, false, fmt.Errorf("resource %s %s not found", ri.NamespacedName.String(), ri.GroupVersionKind.String())
 	}
 
-	readyField, err := jsonpath.Get("$.ready", status)
-	if err == nil {
-		readyStatus := strings.ToLower(fmt.Sprintf("%s", readyField))
-		if readyStatus == "true" || readyStatus == "false" {
-			ready := readyStatus == "true"
-			ctx.Log.V(1).Info("checking resource readiness using ready field", "ready", ready, "nn", ri.NamespacedName.String(), "gvk", ri.GroupVersionKind)
-			return true, ready
-		}
+	checked, ready, source := kubernetes.ResourceReady(ur)
+	if checked {
+		ctx.Log.V(1).Info("checking resource readiness", "ready", ready, "source", source, "nn", ri.NamespacedName.String(), "gvk", ri.GroupVersionKind)
 	}
-
-	return false, false
+	return checked, ready, nil
 }
 
 func (r *ResourceSetReconciler) reconcileStatus(ctx reconcile.Context, resourceSet corev1alpha1.ResourceSet, phase corev1alpha1.ResourceSetPhase, resourcesApplied bool) reconcile.Result {
@@ -240,6 +311,18 @@ func (r *ResourceSetReconciler) reconcileStatus(ctx reconcile.Context, resourceS
 
 	if !resourceSet.Spec.Active && resourceSet.Status.Status == corev1alpha1.ResourceSetReconciling {
 		resourceSet.Status.Status = corev1alpha1.ResourceSetPaused
+		if resourceSet.Spec.ApprovalRequired {
+			resourceSet.Status.Status = corev1alpha1.ResourceSetPendingApproval
+		}
+	}
+
+	resourceSet.Status.Diff = nil
+	if resourceSet.Status.Status == corev1alpha1.ResourceSetPendingApproval {
+		diff, err := r.diffActive(ctx, resourceSet)
+		if err != nil {
+			return ctx.Error(err)
+		}
+		resourceSet.Status.Diff = diff
 	}
 
 	active := metav1.ConditionFalse
@@ -264,13 +347,14 @@ func (r *ResourceSetReconciler) reconcileStatus(ctx reconcile.Context, resourceS
 		totalCount := len(resourceSet.Spec.Resources)
 		uncheckedCount := 0
 		readyCount := 0
-		for _, resource := range resourceSet.Spec.Resources {
-			checked, ready := r.checkResourceReady(ctx, resource.Embedded)
+		for i, resource := range resourceSet.Spec.Resources {
+			checked, ready, err := r.checkResourceReady(ctx, resource.Embedded)
 			if !checked {
 				uncheckedCount++
 			} else if checked && ready {
 				readyCount++
 			}
+			resourceSet.Status.Resources.SetReady(i, checked, ready, err)
 		}
 		desiredCount := totalCount - uncheckedCount
 		ready := readyCount == desiredCount
@@ -285,6 +369,9 @@ func (r *ResourceSetReconciler) reconcileStatus(ctx reconcile.Context, resourceS
 		readyMsg = fmt.Sprintf("%d/%d (%d)", readyCount, desiredCount, totalCount)
 	case corev1alpha1.ResourceSetPaused:
 		readyStatus = metav1.ConditionUnknown
+	case corev1alpha1.ResourceSetPendingApproval:
+		readyStatus = metav1.ConditionUnknown
+		readyMsg = fmt.Sprintf("approve using the %s annotation", corev1alpha1.AnnotationApprovedBy)
 	case corev1alpha1.ResourceSetTerminating:
 		readyStatus = metav1.ConditionFalse
 	}
@@ -298,6 +385,29 @@ func (r *ResourceSetReconciler) reconcileStatus(ctx reconcile.Context, resourceS
 	}
 	apimeta.SetStatusCondition(&resourceSet.Status.Conditions, readyCondition)
 
+	drifted := 0
+	for _, rs := range resourceSet.Status.Resources {
+		if len(rs.Drift) > 0 {
+			drifted++
+		}
+	}
+	driftedCondition := metav1.Condition{
+		Type:               ConditionTypeDrifted,
+		Status:             metav1.ConditionFalse,
+		Reason:             "NoDrift",
+		Message:            "",
+		ObservedGeneration: resourceSet.Generation,
+	}
+	if drifted > 0 {
+		driftedCondition.Status = metav1.ConditionTrue
+		driftedCondition.Reason = "DriftCorrected"
+		if resourceSet.Spec.DriftPolicy == corev1alpha1.DriftPolicyReportOnly {
+			driftedCondition.Reason = "DriftDetected"
+		}
+		driftedCondition.Message = fmt.Sprintf("%d resource(s) drifted from the desired state", drifted)
+	}
+	apimeta.SetStatusCondition(&resourceSet.Status.Conditions, driftedCondition)
+
 	if err := r.UpdateStatus(ctx, &resourceSet); err != nil {
 		return ctx.Error(err)
 	}
@@ -309,6 +419,63 @@ func (r *ResourceSetReconciler) reconcileStatus(ctx reconcile.Context, resourceS
 	return ctx.Done()
 }
 
+// diffActive returns the changes of the resource set compared to the active resource set of the same tenant
+func (r *ResourceSetReconciler) diffActive(ctx reconcile.Context, resourceSet corev1alpha1.ResourceSet) ([]string, error) {
+	var sets corev1alpha1.ResourceSetList
+	if err := r.List(ctx, &sets, client.InNamespace(resourceSet.Namespace), client.MatchingLabels{"aeto.net/tenant": resourceSet.Labels["aeto.net/tenant"]}); err != nil {
+		return nil, err
+	}
+
+	active := corev1alpha1.ResourceSetResourceList{}
+	for _, rs := range sets.Items {
+		if rs.Spec.Active && rs.Name != resourceSet.Name {
+			active = rs.Spec.Resources
+		}
+	}
+
+	diff := make([]string, 0)
+	for _, resource := range resourceSet.Spec.Resources {
+		desired, err := convert.RawExtensionToUnstructured(resource.Embedded.RawExtension)
+		if err != nil {
+			return nil, err
+		}
+		description := resourceDescription(desired.GetKind(), desired.GetNamespace(), desired.GetName())
+
+		_, existing := active.Find(resource.Id)
+		if existing == nil {
+			diff = append(diff, fmt.Sprintf("+ %s", description))
+			continue
+		}
+
+		current, err := convert.RawExtensionToUnstructured(existing.Embedded.RawExtension)
+		if err != nil {
+			return nil, err
+		}
+		changes := util.DiffValues(current.Object, desired.Object)
+		if resource.Sensitive || existing.Sensitive {
+			// sensitive resources are stored without their content, changes are found in their source
+			if len(changes) > 0 || !equality.Semantic.DeepEqual(resource.Source, existing.Source) {
+				changes = []string{domain.SensitiveDiff}
+			}
+		}
+		for _, change := range changes {
+			diff = append(diff, fmt.Sprintf("~ %s: %s", description, change))
+		}
+	}
+
+	for _, resource := range active {
+		if _, found := resourceSet.Spec.Resources.Find(resource.Id); found == nil {
+			current, err := convert.RawExtensionToUnstructured(resource.Embedded.RawExtension)
+			if err != nil {
+				return nil, err
+			}
+			diff = append(diff, fmt.Sprintf("- %s", resourceDescription(current.GetKind(), current.GetNamespace(), current.GetName())))
+		}
+	}
+
+	return util.LimitStrings(diff, maxApprovalDiffEntries), nil
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *ResourceSetReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
@@ -316,6 +483,27 @@ func (r *ResourceSetReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Complete(r)
 }
 
+func newResourceStatusList(ctx reconcile.Context, resources corev1alpha1.ResourceSetResourceList) corev1alpha1.ResourceSetResourceStatusList {
+	statuses := make(corev1alpha1.ResourceSetResourceStatusList, 0)
+	for _, resource := range resources {
+		status := corev1alpha1.ResourceSetResourceStatus{
+			Id:    resource.Id,
+			Ready: metav1.ConditionUnknown,
+		}
+		ri, err := convert.RawExtensionToResourceIdentifier(resource.Embedded.RawExtension)
+		if err != nil {
+			ctx.Log.Error(err, "failed to convert resource to resource identifier", "id", resource.Id)
+			status.Error = err.Error()
+		} else {
+			status.Kind = ri.GroupVersionKind.Kind
+			status.Namespace = ri.NamespacedName.Namespace
+			status.Name = ri.NamespacedName.Name
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
 func reverseResourceList(s []corev1alpha1.ResourceSetResource) []corev1alpha1.ResourceSetResource {
 	a := make([]corev1alpha1.ResourceSetResource, len(s))
 	copy(a, s)
@@ -71,9 +71,17 @@ func ReconcileResourceSet(ctx reconcile.Context, k8s kubernetes.Client, stream e
 				}
 			}
 		} else {
-			// Updating existing
+			// Updating existing, keeping the approval of the ResourceSet
+			approvedBy := existing.Annotations[corev1alpha1.AnnotationApprovedBy]
 			existing.Labels = rs.Labels
 			existing.Annotations = rs.Annotations
+			if approvedBy != "" {
+				existing.Annotations = make(map[string]string)
+				for k, v := range rs.Annotations {
+					existing.Annotations[k] = v
+				}
+				existing.Annotations[corev1alpha1.AnnotationApprovedBy] = approvedBy
+			}
 			existing.Spec = rs.Spec
 			if err := k8s.Update(ctx, &existing); err != nil {
 				return ctx.Error(err)
@@ -118,6 +126,7 @@ type resourceSetState struct {
 	Current      string
 	Labels       map[string]string
 	Annotations  map[string]string
+	DriftPolicy  corev1alpha1.DriftPolicy
 	ResourceSets map[string]*corev1alpha1.ResourceSet
 }
 
@@ -147,6 +156,11 @@ func (h *ResourceSetEventHandler) On(e eventsource.Event) {
 		h.state.Labels = event.Labels
 	case *tenant.AnnotationsChanged:
 		h.state.Annotations = event.Annotations
+	case *tenant.DriftPolicyChanged:
+		h.state.DriftPolicy = corev1alpha1.DriftPolicy(event.Policy)
+		h.onCurrentResourceSet(func(rs *corev1alpha1.ResourceSet) {
+			rs.Spec.DriftPolicy = h.state.DriftPolicy
+		})
 	case *tenant.ResourceSetCreated:
 		current := h.state.ResourceSets[h.state.Current]
 		if current != nil {
@@ -162,12 +176,15 @@ func (h *ResourceSetEventHandler) On(e eventsource.Event) {
 				Labels:      h.state.Labels,
 				Annotations: h.state.Annotations,
 			}
+			rs.Spec.DriftPolicy = h.state.DriftPolicy
 		})
 	case *tenant.ResourceAdded:
 		h.onCurrentResourceSet(func(rs *corev1alpha1.ResourceSet) {
 			rs.Spec.Resources = append(rs.Spec.Resources, corev1alpha1.ResourceSetResource{
-				Id:    event.Resource.Id,
-				Order: event.Resource.Order,
+				Id:        event.Resource.Id,
+				Order:     event.Resource.Order,
+				Sensitive: event.Resource.Sensitive,
+				Source:    event.Resource.Source,
 				Embedded: corev1alpha1.EmbeddedResource{
 					RawExtension: runtime.RawExtension{
 						Raw: event.Resource.Embedded.Raw,
@@ -179,6 +196,8 @@ func (h *ResourceSetEventHandler) On(e eventsource.Event) {
 		h.onCurrentResourceSet(func(rs *corev1alpha1.ResourceSet) {
 			i, r := rs.Spec.Resources.Find(event.Resource.Id)
 			r.Order = event.Resource.Order
+			r.Sensitive = event.Resource.Sensitive
+			r.Source = event.Resource.Source
 			r.Embedded = corev1alpha1.EmbeddedResource{
 				RawExtension: runtime.RawExtension{
 					Raw: event.Resource.Embedded.Raw,
@@ -199,6 +218,14 @@ func (h *ResourceSetEventHandler) On(e eventsource.Event) {
 		h.onResourceSet(event.Name, func(rs *corev1alpha1.ResourceSet) {
 			rs.Spec.Active = false
 		})
+	case *tenant.ResourceSetApprovalRequested:
+		h.onResourceSet(event.Name, func(rs *corev1alpha1.ResourceSet) {
+			rs.Spec.ApprovalRequired = true
+		})
+	case *tenant.ResourceSetApproved:
+		h.onResourceSet(event.Name, func(rs *corev1alpha1.ResourceSet) {
+			rs.Spec.ApprovalRequired = false
+		})
 	}
 
 	for _, rs := range h.state.ResourceSets {
@@ -18,13 +18,26 @@ package core
 
 import (
 	"context"
+	"fmt"
 
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	kreconcile "sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	corev1alpha1 "github.com/kristofferahl/aeto/apis/core/v1alpha1"
 	"github.com/kristofferahl/aeto/internal/pkg/kubernetes"
+	"github.com/kristofferahl/aeto/internal/pkg/reconcile"
+	domain "github.com/kristofferahl/aeto/internal/pkg/tenant"
+	"github.com/kristofferahl/aeto/internal/pkg/util"
 )
 
 // ResourceTemplateReconciler reconciles a ResourceTemplate object
@@ -36,27 +49,93 @@ type ResourceTemplateReconciler struct {
 //+kubebuilder:rbac:groups=core.aeto.net,resources=resourcetemplates,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core.aeto.net,resources=resourcetemplates/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=core.aeto.net,resources=resourcetemplates/finalizers,verbs=update
+//+kubebuilder:rbac:groups=core.aeto.net,resources=resourcetemplaterevisions,verbs=get;list;watch;create;delete
+//+kubebuilder:rbac:groups=core.aeto.net,resources=blueprints,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create
 
-// Reconcile is part of the main kubernetes reconciliation loop which aims to
-// move the current state of the cluster closer to the desired state.
-// TODO(user): Modify the Reconcile function to compare the state specified by
-// the ResourceTemplate object against the actual cluster state, and then
-// perform operations to make the cluster state reflect the state specified by
-// the user.
-//
-// For more details, check Reconcile and its Result here:
-// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.10.0/pkg/reconcile
+// Reconcile snapshots the spec of the ResourceTemplate into a ResourceTemplateRevision, validates the spec and reports
+// the revision and the Blueprints using it in its status
 func (r *ResourceTemplateReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	_ = log.FromContext(ctx)
+	rctx := reconcile.NewContext("resourcetemplate", req, log.FromContext(ctx))
+	rctx.Log.Info("reconciling")
 
-	// your logic here
+	var rt corev1alpha1.ResourceTemplate
+	if err := r.Get(rctx, req.NamespacedName, &rt); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
 
-	return ctrl.Result{}, nil
+	revision, err := ensureResourceTemplateRevision(rctx, r.Client, rt)
+	if err != nil {
+		return rctx.Complete(rctx.Error(err))
+	}
+	rt.Status.Revision = revision
+
+	if err := ensureChartSnapshot(rctx, r.Client, rt, revision); err != nil {
+		rctx.Log.Error(err, "failed to create chart snapshot", "revision", revision)
+		return rctx.Complete(rctx.Error(err))
+	}
+
+	var blueprints corev1alpha1.BlueprintList
+	if err := r.List(rctx, &blueprints, client.InNamespace(rt.Namespace)); err != nil {
+		return rctx.Complete(rctx.Error(err))
+	}
+	rt.Status.Blueprints = make([]string, 0)
+	for _, b := range blueprints.Items {
+		if util.SliceContainsString(b.Templates(), rt.Name) {
+			rt.Status.Blueprints = append(rt.Status.Blueprints, b.Name)
+		}
+	}
+
+	if err := pruneResourceTemplateRevisions(rctx, r.Client, rt, revision, blueprints.Items); err != nil {
+		rctx.Log.Error(err, "failed to prune ResourceTemplateRevisions")
+		return rctx.Complete(rctx.Error(err))
+	}
+
+	rt.Status.ValidationErrors = domain.ValidateResourceTemplate(rt.Spec)
+
+	readyCondition := metav1.Condition{
+		Type:   ConditionTypeReady,
+		Status: metav1.ConditionTrue,
+		Reason: "Valid",
+	}
+	if len(rt.Status.ValidationErrors) > 0 {
+		rctx.Log.V(1).Info("ResourceTemplate validation failed", "errors", rt.Status.ValidationErrors)
+		readyCondition.Status = metav1.ConditionFalse
+		readyCondition.Reason = "ValidationFailed"
+		readyCondition.Message = fmt.Sprintf("%d validation error(s)", len(rt.Status.ValidationErrors))
+	}
+	apimeta.SetStatusCondition(&rt.Status.Conditions, readyCondition)
+
+	if err := r.UpdateStatus(rctx, &rt); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return rctx.Complete(rctx.Done())
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *ResourceTemplateReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&corev1alpha1.ResourceTemplate{}).
+		For(&corev1alpha1.ResourceTemplate{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(
+			&source.Kind{Type: &corev1alpha1.Blueprint{}},
+			handler.EnqueueRequestsFromMapFunc(r.findResourceTemplatesForBlueprint),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{}),
+		).
 		Complete(r)
 }
+
+func (r *ResourceTemplateReconciler) findResourceTemplatesForBlueprint(o client.Object) []kreconcile.Request {
+	blueprint := o.(*corev1alpha1.Blueprint)
+
+	requests := make([]kreconcile.Request, 0)
+	for _, name := range blueprint.Templates() {
+		requests = append(requests, kreconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Name:      name,
+				Namespace: blueprint.Namespace,
+			},
+		})
+	}
+	return requests
+}
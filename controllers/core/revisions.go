@@ -0,0 +1,260 @@
+package core
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	corev1alpha1 "github.com/kristofferahl/aeto/apis/core/v1alpha1"
+	"github.com/kristofferahl/aeto/internal/pkg/config"
+	"github.com/kristofferahl/aeto/internal/pkg/helm"
+	"github.com/kristofferahl/aeto/internal/pkg/kubernetes"
+	"github.com/kristofferahl/aeto/internal/pkg/reconcile"
+	domain "github.com/kristofferahl/aeto/internal/pkg/tenant"
+)
+
+const (
+	blueprintRevisionLabel        = "aeto.net/blueprint"
+	resourceTemplateRevisionLabel = "aeto.net/resourcetemplate"
+
+	// maxChartSnapshotSize keeps chart snapshots within the size limit of a ConfigMap
+	maxChartSnapshotSize = 1000 * 1024
+)
+
+// ensureBlueprintRevision creates a revision of the current spec of the blueprint unless it exists, returning the name of the revision.
+// Revisions are never updated once created, an existing revision no longer matching its hash is reported as an error.
+func ensureBlueprintRevision(ctx reconcile.Context, k8s kubernetes.Client, blueprint corev1alpha1.Blueprint) (string, error) {
+	hash, err := domain.RevisionHash(blueprint.Spec)
+	if err != nil {
+		return "", err
+	}
+	name := domain.RevisionName(blueprint.Name, hash)
+
+	var existing corev1alpha1.BlueprintRevision
+	err = k8s.Get(ctx, types.NamespacedName{Namespace: blueprint.Namespace, Name: name}, &existing)
+	if err == nil {
+		if existing.Spec.Hash != hash {
+			return "", fmt.Errorf("revision %s has been modified, expected hash %s", name, hash)
+		}
+		return name, domain.VerifyRevision(existing.Name, existing.Spec.Hash, existing.Spec.Snapshot)
+	}
+	if !apierrors.IsNotFound(err) {
+		return "", err
+	}
+
+	var revisions corev1alpha1.BlueprintRevisionList
+	if err := k8s.List(ctx, &revisions, client.InNamespace(blueprint.Namespace), client.MatchingLabels{blueprintRevisionLabel: blueprint.Name}); err != nil {
+		return "", err
+	}
+	revision := int64(1)
+	for _, r := range revisions.Items {
+		if r.Spec.Revision >= revision {
+			revision = r.Spec.Revision + 1
+		}
+	}
+
+	rev := corev1alpha1.BlueprintRevision{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: blueprint.Namespace,
+			Labels: map[string]string{
+				blueprintRevisionLabel: blueprint.Name,
+			},
+		},
+		Spec: corev1alpha1.BlueprintRevisionSpec{
+			Blueprint: blueprint.Name,
+			Revision:  revision,
+			Hash:      hash,
+			Snapshot:  *blueprint.Spec.DeepCopy(),
+		},
+	}
+	if err := k8s.Create(ctx, &rev); err != nil && !apierrors.IsAlreadyExists(err) {
+		return "", err
+	}
+
+	ctx.Log.Info("created BlueprintRevision", "revision", name, "number", revision)
+	return name, nil
+}
+
+// ensureResourceTemplateRevision creates a revision of the current spec of the resource template unless it exists, returning the name of the revision.
+// Revisions are never updated once created, an existing revision no longer matching its hash is reported as an error.
+func ensureResourceTemplateRevision(ctx reconcile.Context, k8s kubernetes.Client, rt corev1alpha1.ResourceTemplate) (string, error) {
+	hash, err := domain.RevisionHash(rt.Spec)
+	if err != nil {
+		return "", err
+	}
+	name := domain.RevisionName(rt.Name, hash)
+
+	var existing corev1alpha1.ResourceTemplateRevision
+	err = k8s.Get(ctx, types.NamespacedName{Namespace: rt.Namespace, Name: name}, &existing)
+	if err == nil {
+		if existing.Spec.Hash != hash {
+			return "", fmt.Errorf("revision %s has been modified, expected hash %s", name, hash)
+		}
+		return name, domain.VerifyRevision(existing.Name, existing.Spec.Hash, existing.Spec.Snapshot)
+	}
+	if !apierrors.IsNotFound(err) {
+		return "", err
+	}
+
+	var revisions corev1alpha1.ResourceTemplateRevisionList
+	if err := k8s.List(ctx, &revisions, client.InNamespace(rt.Namespace), client.MatchingLabels{resourceTemplateRevisionLabel: rt.Name}); err != nil {
+		return "", err
+	}
+	revision := int64(1)
+	for _, r := range revisions.Items {
+		if r.Spec.Revision >= revision {
+			revision = r.Spec.Revision + 1
+		}
+	}
+
+	annotations := make(map[string]string)
+	if policy, ok := rt.Annotations[corev1alpha1.AnnotationRetainPolicy]; ok {
+		annotations[corev1alpha1.AnnotationRetainPolicy] = policy
+	}
+
+	rev := corev1alpha1.ResourceTemplateRevision{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: rt.Namespace,
+			Labels: map[string]string{
+				resourceTemplateRevisionLabel: rt.Name,
+			},
+			Annotations: annotations,
+		},
+		Spec: corev1alpha1.ResourceTemplateRevisionSpec{
+			ResourceTemplate: rt.Name,
+			Revision:         revision,
+			Hash:             hash,
+			Snapshot:         *rt.Spec.DeepCopy(),
+		},
+	}
+	if err := k8s.Create(ctx, &rev); err != nil && !apierrors.IsAlreadyExists(err) {
+		return "", err
+	}
+
+	ctx.Log.Info("created ResourceTemplateRevision", "revision", name, "number", revision)
+	return name, nil
+}
+
+// pruneBlueprintRevisions deletes the oldest revisions of the blueprint beyond the revision history limit, the current
+// revision is kept
+func pruneBlueprintRevisions(ctx reconcile.Context, k8s kubernetes.Client, blueprint corev1alpha1.Blueprint, current string) error {
+	var revisions corev1alpha1.BlueprintRevisionList
+	if err := k8s.List(ctx, &revisions, client.InNamespace(blueprint.Namespace), client.MatchingLabels{blueprintRevisionLabel: blueprint.Name}); err != nil {
+		return err
+	}
+
+	numbers := make(map[string]int64)
+	for _, r := range revisions.Items {
+		numbers[r.Name] = r.Spec.Revision
+	}
+
+	for _, name := range domain.ExpiredRevisions(numbers, []string{current}, config.Operator.MaxRevisionHistory) {
+		rev := corev1alpha1.BlueprintRevision{ObjectMeta: metav1.ObjectMeta{Namespace: blueprint.Namespace, Name: name}}
+		if err := k8s.Delete(ctx, &rev); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		ctx.Log.Info("deleted BlueprintRevision beyond the revision history limit", "revision", name)
+	}
+
+	return nil
+}
+
+// pruneResourceTemplateRevisions deletes the oldest revisions of the resource template beyond the revision history
+// limit, the current revision and revisions pinned by resource groups of blueprints are kept
+func pruneResourceTemplateRevisions(ctx reconcile.Context, k8s kubernetes.Client, rt corev1alpha1.ResourceTemplate, current string, blueprints []corev1alpha1.Blueprint) error {
+	var revisions corev1alpha1.ResourceTemplateRevisionList
+	if err := k8s.List(ctx, &revisions, client.InNamespace(rt.Namespace), client.MatchingLabels{resourceTemplateRevisionLabel: rt.Name}); err != nil {
+		return err
+	}
+
+	numbers := make(map[string]int64)
+	for _, r := range revisions.Items {
+		numbers[r.Name] = r.Spec.Revision
+	}
+
+	keep := []string{current}
+	for _, b := range blueprints {
+		for _, rg := range b.Spec.Resources {
+			if rg.Template == rt.Name && rg.TemplateRevision != "" {
+				keep = append(keep, rg.TemplateRevision)
+			}
+		}
+	}
+
+	for _, name := range domain.ExpiredRevisions(numbers, keep, config.Operator.MaxRevisionHistory) {
+		rev := corev1alpha1.ResourceTemplateRevision{ObjectMeta: metav1.ObjectMeta{Namespace: rt.Namespace, Name: name}}
+		if err := k8s.Delete(ctx, &rev); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		ctx.Log.Info("deleted ResourceTemplateRevision beyond the revision history limit", "revision", name)
+	}
+
+	return nil
+}
+
+// ensureChartSnapshot stores the helm chart of a resource template revision in a ConfigMap owned by the revision unless
+// it exists, so that tenants render the chart without fetching it from the chart repository. Charts too large for a
+// ConfigMap are not stored.
+func ensureChartSnapshot(ctx reconcile.Context, k8s kubernetes.Client, rt corev1alpha1.ResourceTemplate, revision string) error {
+	if rt.Spec.Helm == nil || rt.Spec.Helm.Repository == nil {
+		return nil
+	}
+
+	nn := types.NamespacedName{Namespace: rt.Namespace, Name: domain.ChartArchiveName(revision)}
+	var existing corev1.ConfigMap
+	err := k8s.Get(ctx, nn, &existing)
+	if err == nil {
+		return nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	var rev corev1alpha1.ResourceTemplateRevision
+	if err := k8s.Get(ctx, types.NamespacedName{Namespace: rt.Namespace, Name: revision}, &rev); err != nil {
+		return err
+	}
+
+	repo := rt.Spec.Helm.Repository
+	archive, err := helm.FetchArchive(repo.URL, repo.Chart, repo.Version)
+	if err != nil {
+		return err
+	}
+	if len(archive) > maxChartSnapshotSize {
+		ctx.Log.Info("chart too large for a snapshot, tenants fetch it from the chart repository", "chart", repo.Chart, "version", repo.Version, "size", len(archive))
+		return nil
+	}
+
+	cm := corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      nn.Name,
+			Namespace: nn.Namespace,
+			Labels: map[string]string{
+				resourceTemplateRevisionLabel: rt.Name,
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: corev1alpha1.GroupVersion.String(),
+					Kind:       "ResourceTemplateRevision",
+					Name:       rev.Name,
+					UID:        rev.UID,
+				},
+			},
+		},
+		BinaryData: map[string][]byte{
+			domain.ChartArchiveKey: archive,
+		},
+	}
+	if err := k8s.Create(ctx, &cm); err != nil && !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+
+	ctx.Log.Info("created chart snapshot", "revision", revision, "chart", repo.Chart, "version", repo.Version)
+	return nil
+}
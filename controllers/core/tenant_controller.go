@@ -18,17 +18,30 @@ package core
 
 import (
 	"context"
-
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	kreconcile "sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	corev1alpha1 "github.com/kristofferahl/aeto/apis/core/v1alpha1"
 	eventv1alpha1 "github.com/kristofferahl/aeto/apis/event/v1alpha1"
 	"github.com/kristofferahl/aeto/internal/pkg/config"
+	"github.com/kristofferahl/aeto/internal/pkg/eventsource"
 	"github.com/kristofferahl/aeto/internal/pkg/eventstore"
 	"github.com/kristofferahl/aeto/internal/pkg/kubernetes"
 	"github.com/kristofferahl/aeto/internal/pkg/reconcile"
@@ -36,7 +49,11 @@ import (
 )
 
 const (
-	TenantFinalizerName = "tenant.core.aeto.net/finalizer"
+	TenantFinalizerName       = "tenant.core.aeto.net/finalizer"
+	TenantParentFieldIndexKey = ".spec.parent"
+
+	TenantBlueprintFieldIndexKey   = ".spec.blueprint"
+	BlueprintTemplateFieldIndexKey = ".spec.templates"
 )
 
 var (
@@ -53,6 +70,12 @@ type TenantReconciler struct {
 //+kubebuilder:rbac:groups=core.aeto.net,resources=tenants,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core.aeto.net,resources=tenants/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=core.aeto.net,resources=tenants/finalizers,verbs=update
+//+kubebuilder:rbac:groups=core.aeto.net,resources=resourcetemplaterevisions,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -72,7 +95,45 @@ func (r *TenantReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
+	children, err := r.children(rctx, tenant)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	tenant.Status.Children = nil
+	for _, child := range children {
+		tenant.Status.Children = append(tenant.Status.Children, child.Name)
+	}
+
 	finalizer := reconcile.NewGenericFinalizer(TenantFinalizerName, func(c reconcile.Context) reconcile.Result {
+		if tenant.DeletionProtected() {
+			rctx.Log.Info("Tenant is protected from deletion, remove the annotation to allow it to be deleted", "annotation", corev1alpha1.AnnotationDeletionProtection)
+			if r.Recorder != nil {
+				r.Recorder.Eventf(&tenant, corev1.EventTypeWarning, "DeletionProtected", "Tenant is protected from deletion by the %s annotation", corev1alpha1.AnnotationDeletionProtection)
+			}
+			return rctx.RequeueIn(30, "deletion protection enabled")
+		}
+
+		if len(children) > 0 {
+			if tenant.Annotations[corev1alpha1.AnnotationCascadeDelete] != "true" {
+				rctx.Log.Info("Tenant has child tenants, delete them or set the annotation to delete them with the Tenant", "annotation", corev1alpha1.AnnotationCascadeDelete, "children", tenant.Status.Children)
+				if r.Recorder != nil {
+					r.Recorder.Eventf(&tenant, corev1.EventTypeWarning, "WaitingForChildren", "Tenant is not deleted until its child tenants are deleted, set the %s annotation to delete them with the Tenant", corev1alpha1.AnnotationCascadeDelete)
+				}
+				return rctx.RequeueIn(30, "waiting for child tenants to be deleted")
+			}
+
+			for _, child := range children {
+				child := child
+				if child.DeletionTimestamp.IsZero() {
+					rctx.Log.Info("deleting child Tenant", "child", child.Name)
+					if err := r.Delete(rctx, &child); err != nil {
+						return rctx.Error(client.IgnoreNotFound(err))
+					}
+				}
+			}
+			return rctx.RequeueIn(10, "waiting for child tenants to be deleted")
+		}
+
 		streamId := eventstore.StreamId(req.NamespacedName.String())
 		store := eventstore.New(r.Client.GetClient(), rctx.Log, rctx.Context, serializer)
 		stream, err := store.Get(streamId)
@@ -80,6 +141,26 @@ func (r *TenantReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 			return rctx.Error(err)
 		}
 		if stream.Length() > 0 {
+			rctx.Log.V(1).Info("event stream found, loading Tenant aggregate from history")
+			t := domain.NewTenantFromEvents(stream)
+
+			blueprint, missing, err := r.getPreDeleteBlueprint(rctx, t.Blueprint(), t.AddOns())
+			if err != nil {
+				return rctx.Error(err)
+			}
+			if len(missing) > 0 {
+				rctx.Log.Info("Blueprints not found, skipping their pre-delete hooks", "blueprints", missing)
+				if r.Recorder != nil {
+					r.Recorder.Eventf(&tenant, corev1.EventTypeWarning, "PreDeleteHooksSkipped", "Pre-delete hooks of blueprint(s) %s are skipped, the blueprints no longer exist", strings.Join(missing, ", "))
+				}
+			}
+			if completed, res := r.reconcileHooks(rctx, t, blueprint, corev1alpha1.HookPhasePreDelete); !completed {
+				if _, err := store.Save(t); err != nil {
+					return rctx.Error(err)
+				}
+				return res
+			}
+
 			results := reconcile.ResultList{}
 
 			results = append(results, ReconcileStatus(rctx, r.Client, tenant, stream))
@@ -90,12 +171,9 @@ func (r *TenantReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 				return rctx.Done()
 			}
 
-			rctx.Log.V(1).Info("event stream found, loading Tenant aggregate from history")
-			t := domain.NewTenantFromEvents(stream)
-
 			t.Delete()
 
-			_, err := store.Save(t)
+			_, err = store.Save(t)
 			if err != nil {
 				return rctx.Error(err)
 			} else {
@@ -109,12 +187,24 @@ func (r *TenantReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 		return *res, err
 	}
 
+	inherited, err := r.inherit(rctx, tenant)
+	if err != nil {
+		rctx.Log.Error(err, "failed to inherit from parent Tenant", "parent", tenant.Spec.Parent)
+		return ctrl.Result{}, err
+	}
+
+	blueprintName, selection, err := r.selectBlueprint(rctx, inherited)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	tenant.Status.BlueprintSelection = selection
+
 	blueprintRef := types.NamespacedName{
 		Namespace: config.Operator.Namespace,
-		Name:      tenant.Blueprint(),
+		Name:      blueprintName,
 	}
-	var blueprint corev1alpha1.Blueprint
-	if err := r.Get(rctx, blueprintRef, &blueprint); err != nil {
+	blueprint, revisions, err := r.getBlueprint(rctx, blueprintRef, tenant.Spec.AddOns)
+	if err != nil {
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
@@ -136,7 +226,7 @@ func (r *TenantReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 
 		t.Create(tenant.Name, tenant.Namespace)
 		t.SetFullName(tenant.Spec.Name)
-		t.SetBlueprint(tenant, blueprint)
+		t.SetBlueprint(inherited, blueprint)
 
 		events, err := store.Save(t)
 		if err != nil {
@@ -151,30 +241,316 @@ func (r *TenantReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 		results = append(results, ReconcileResourceSet(rctx, r.Client, stream))
 		results = append(results, ReconcileOrphanedResources(rctx, r.Client, stream))
 		results = append(results, ReconcileRequeueRequest(rctx, stream))
+		results = append(results, ReconcileOutputs(rctx, r.Client, &tenant, stream))
 		results = append(results, ReconcileStatus(rctx, r.Client, tenant, stream))
 
-		t.SetFullName(tenant.Spec.Name)
-		t.SetBlueprint(tenant, blueprint)
+		expiresAt, err := tenant.ExpiresAt()
+		if err != nil {
+			rctx.Log.Error(err, "failed to calculate Tenant expiry")
+			results = append(results, rctx.Error(err))
+		} else if t.SetExpiry(expiresAt, time.Now(), config.Operator.TenantExpiryWarning) {
+			return r.deleteExpired(rctx, tenant, store, t)
+		}
 
-		generator := domain.NewResourceGenerator(rctx, domain.ResourceGeneratoreServices{Client: r.Client})
+		if err := r.rotateGeneratedValues(rctx, &tenant, t); err != nil {
+			rctx.Log.Error(err, "failed to rotate generated values")
+			results = append(results, rctx.Error(err))
+		}
 
-		err = t.GenerateResources(generator, tenant, blueprint)
+		t.SetFullName(tenant.Spec.Name)
+		t.SetBlueprint(inherited, blueprint)
+
+		approved, err := r.approveResourceSet(rctx, tenant, t)
 		if err != nil {
-			rctx.Log.Error(err, "failed to generate events from Blueprint")
 			results = append(results, rctx.Error(err))
 		}
 
+		if completed, res := r.reconcileHooks(rctx, t, blueprint, corev1alpha1.HookPhasePreProvision); completed {
+			generator := domain.NewResourceGenerator(rctx, domain.ResourceGeneratoreServices{Client: r.Client}).WithBlueprintRevisions(revisions...)
+
+			inspector := domain.NewResourceInspector(rctx, domain.ResourceGeneratoreServices{Client: r.Client})
+
+			err = t.GenerateResources(generator, inspector, tenant, blueprint, time.Now())
+			if err != nil {
+				rctx.Log.Error(err, "failed to generate events from Blueprint")
+				results = append(results, rctx.Error(err))
+			}
+
+			if next, deferred := t.ActivationDeferred(); deferred {
+				rctx.Log.V(1).Info("ResourceSet activation deferred until the next maintenance window", "next-window", next.Format(time.RFC3339))
+				results = append(results, maintenanceRequeue(rctx, next, time.Now()))
+			}
+
+			if deferred := t.DeferredResourceGroups(); len(deferred) > 0 {
+				results = append(results, rctx.RequeueIn(15, fmt.Sprintf("resource groups waiting for dependencies (%s)", strings.Join(deferred, ", "))))
+			}
+		} else {
+			results = append(results, res)
+		}
+
+		if apimeta.IsStatusConditionTrue(tenant.Status.Conditions, ConditionTypeReady) {
+			_, res := r.reconcileHooks(rctx, t, blueprint, corev1alpha1.HookPhasePostReady)
+			results = append(results, res)
+		}
+
+		if expiresAt != nil {
+			results = append(results, expiryRequeue(rctx, *expiresAt, time.Now()))
+		}
+
 		events, err := store.Save(t)
 		if err != nil {
 			results = append(results, rctx.Error(err))
 		} else if events > 0 {
 			results = append(results, rctx.RequeueIn(5, "new events needs processing by the controller"))
 		}
+
+		if !approved {
+			results = append(results, rctx.RequeueIn(30, "waiting for ResourceSet to be approved"))
+		}
 	}
 
 	return rctx.Complete(results...)
 }
 
+// deleteExpired records the expiry of the tenant and deletes it
+func (r *TenantReconciler) deleteExpired(ctx reconcile.Context, tenant corev1alpha1.Tenant, store eventsource.Repository, t *domain.TenantAggregate) (ctrl.Result, error) {
+	ctx.Log.Info("Tenant expired, deleting")
+
+	if _, err := store.Save(t); err != nil {
+		return ctx.Complete(ctx.Error(err))
+	}
+
+	if r.Recorder != nil {
+		r.Recorder.Event(&tenant, corev1.EventTypeWarning, "Expired", "Tenant expired and is being deleted")
+	}
+
+	if err := r.Delete(ctx, &tenant); err != nil {
+		return ctx.Complete(ctx.Error(client.IgnoreNotFound(err)))
+	}
+
+	return ctx.Complete(ctx.RequeueIn(5, "expired Tenant is being deleted"))
+}
+
+// expiryRequeue requeues the tenant when it is about to be marked as expiring or expire within the reconcile interval
+func expiryRequeue(ctx reconcile.Context, expiresAt time.Time, now time.Time) reconcile.Result {
+	next := expiresAt.Add(-config.Operator.TenantExpiryWarning)
+	if !now.Before(next) {
+		next = expiresAt
+	}
+
+	wait := next.Sub(now)
+	if wait >= config.Operator.ReconcileInterval {
+		return ctx.Done()
+	}
+
+	return ctx.RequeueIn(int(wait.Seconds())+1, "waiting for Tenant expiry")
+}
+
+// maintenanceRequeue requeues the tenant at the start of the next maintenance window when it starts before the next reconcile
+func maintenanceRequeue(ctx reconcile.Context, next time.Time, now time.Time) reconcile.Result {
+	wait := next.Sub(now)
+	if wait >= config.Operator.ReconcileInterval {
+		return ctx.Done()
+	}
+
+	return ctx.RequeueIn(int(wait.Seconds())+1, "waiting for maintenance window")
+}
+
+// approveResourceSet records the approval of the resource set pending approval and returns false while it is not approved
+func (r *TenantReconciler) approveResourceSet(ctx reconcile.Context, tenant corev1alpha1.Tenant, t *domain.TenantAggregate) (bool, error) {
+	nn, pending := t.PendingApproval()
+	if !pending {
+		return true, nil
+	}
+
+	var rs corev1alpha1.ResourceSet
+	if err := r.Get(ctx, nn, &rs); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+
+	approvedBy, allowed, err := domain.Approver(ctx, r.Client, rs)
+	if err != nil {
+		return false, err
+	}
+	if approvedBy == "" {
+		ctx.Log.V(1).Info("ResourceSet is pending approval", "resource-set", nn.String(), "annotation", corev1alpha1.AnnotationApprovedBy)
+		return false, nil
+	}
+	if !allowed {
+		ctx.Log.Info("ResourceSet approval denied", "resource-set", nn.String(), "approved-by", approvedBy)
+		if r.Recorder != nil {
+			r.Recorder.Eventf(&tenant, corev1.EventTypeWarning, "ApprovalDenied", "%s is not allowed to approve ResourceSet %s, the %s verb on resourcesets is required", approvedBy, nn.Name, domain.ApproveVerb)
+		}
+		return false, nil
+	}
+
+	ctx.Log.Info("ResourceSet approved", "resource-set", nn.String(), "approved-by", approvedBy)
+	t.ApproveResourceSet(nn.Name, approvedBy)
+	if r.Recorder != nil {
+		r.Recorder.Eventf(&tenant, corev1.EventTypeNormal, "Approved", "ResourceSet %s approved by %s", nn.Name, approvedBy)
+	}
+	return true, nil
+}
+
+// rotateGeneratedValues removes the rotate annotation from the tenant and then the generated values it names, the
+// rotation is recorded only once the annotation is removed
+func (r *TenantReconciler) rotateGeneratedValues(ctx reconcile.Context, tenant *corev1alpha1.Tenant, t *domain.TenantAggregate) error {
+	rotated, err := domain.RotateGeneratedValues(ctx, r.Client, tenant)
+	if err != nil || len(rotated) == 0 {
+		return err
+	}
+
+	ctx.Log.Info("generated values rotated", "names", rotated)
+	t.RotateGeneratedValues(rotated)
+	if r.Recorder != nil {
+		r.Recorder.Eventf(tenant, corev1.EventTypeNormal, "Rotated", "Generated values rotated (%s)", strings.Join(rotated, ", "))
+	}
+	return nil
+}
+
+// selectBlueprint returns the name of the blueprint specified by the tenant or the highest priority blueprint selecting the tenant
+func (r *TenantReconciler) selectBlueprint(ctx reconcile.Context, tenant corev1alpha1.Tenant) (string, corev1alpha1.BlueprintSelection, error) {
+	if tenant.Spec.Blueprint != "" {
+		return tenant.Spec.Blueprint, corev1alpha1.BlueprintSelectionSpec, nil
+	}
+
+	var list corev1alpha1.BlueprintList
+	if err := r.List(ctx, &list, client.InNamespace(config.Operator.Namespace)); err != nil {
+		return "", "", err
+	}
+
+	selected, errs := tenant.SelectBlueprint(list.Items)
+	for _, err := range errs {
+		ctx.Log.Error(err, "invalid tenant selector")
+	}
+
+	if selected != nil {
+		ctx.Log.V(1).Info("Blueprint selected by tenant selector", "blueprint", selected.Name, "priority", selected.Spec.Priority)
+		return selected.Name, corev1alpha1.BlueprintSelectionSelector, nil
+	}
+
+	return tenant.Blueprint(), corev1alpha1.BlueprintSelectionDefault, nil
+}
+
+// getBlueprint fetches a blueprint and composes it with the add-on blueprints, returning the revisions of all blueprints used
+func (r *TenantReconciler) getBlueprint(ctx reconcile.Context, nn types.NamespacedName, addOns []string) (corev1alpha1.Blueprint, []string, error) {
+	resolved, err := resolveBlueprint(ctx, r.Client, nn)
+	if err != nil {
+		return resolved.Blueprint, nil, err
+	}
+	blueprint := resolved.Blueprint
+	revisions := resolved.Revisions
+
+	addOnBlueprints := make([]corev1alpha1.Blueprint, 0)
+	for _, name := range addOns {
+		addOn, err := resolveBlueprint(ctx, r.Client, types.NamespacedName{Namespace: nn.Namespace, Name: name})
+		if err != nil {
+			return blueprint, nil, err
+		}
+		addOnBlueprints = append(addOnBlueprints, addOn.Blueprint)
+		revisions = append(revisions, addOn.Revisions...)
+	}
+
+	composed, err := blueprint.Compose(addOnBlueprints...)
+	if err != nil {
+		ctx.Log.Error(err, "failed to compose blueprint from add-ons", "blueprint", nn.String(), "add-ons", addOns)
+		return blueprint, nil, err
+	}
+
+	return composed, revisions, nil
+}
+
+// getPreDeleteBlueprint returns the blueprint composed from the blueprint and add-ons of a deleted tenant. Blueprints
+// that no longer exist are left out and returned by name, so the hooks of the remaining blueprints still run.
+func (r *TenantReconciler) getPreDeleteBlueprint(ctx reconcile.Context, nn types.NamespacedName, addOns []string) (corev1alpha1.Blueprint, []string, error) {
+	missing := make([]string, 0)
+
+	blueprint := corev1alpha1.Blueprint{ObjectMeta: metav1.ObjectMeta{Namespace: nn.Namespace, Name: nn.Name}}
+	if resolved, err := resolveBlueprint(ctx, r.Client, nn); err == nil {
+		blueprint = resolved.Blueprint
+	} else if client.IgnoreNotFound(err) == nil {
+		missing = append(missing, nn.Name)
+	} else {
+		return blueprint, nil, err
+	}
+
+	addOnBlueprints := make([]corev1alpha1.Blueprint, 0)
+	for _, name := range addOns {
+		addOn, err := resolveBlueprint(ctx, r.Client, types.NamespacedName{Namespace: nn.Namespace, Name: name})
+		if err != nil {
+			if client.IgnoreNotFound(err) != nil {
+				return blueprint, nil, err
+			}
+			missing = append(missing, name)
+			continue
+		}
+		addOnBlueprints = append(addOnBlueprints, addOn.Blueprint)
+	}
+
+	composed, err := blueprint.Compose(addOnBlueprints...)
+	if err != nil {
+		return blueprint, nil, err
+	}
+	return composed, missing, nil
+}
+
+// inherit merges the labels, annotations and parameters of the parent tenants into the tenant
+func (r *TenantReconciler) inherit(ctx reconcile.Context, tenant corev1alpha1.Tenant) (corev1alpha1.Tenant, error) {
+	inherited := tenant
+	visited := map[string]bool{tenant.Name: true}
+
+	for name := tenant.Spec.Parent; name != ""; {
+		if visited[name] {
+			return tenant, fmt.Errorf("parent tenant %s creates a cycle", name)
+		}
+		visited[name] = true
+
+		var parent corev1alpha1.Tenant
+		if err := r.Get(ctx, types.NamespacedName{Namespace: tenant.Namespace, Name: name}, &parent); err != nil {
+			return tenant, err
+		}
+
+		inherited = inherited.InheritFrom(parent)
+		name = parent.Spec.Parent
+	}
+
+	return inherited, nil
+}
+
+// children returns the tenants that has the tenant as their parent
+func (r *TenantReconciler) children(ctx reconcile.Context, tenant corev1alpha1.Tenant) ([]corev1alpha1.Tenant, error) {
+	var list corev1alpha1.TenantList
+	if err := r.List(ctx, &list, client.InNamespace(tenant.Namespace), client.MatchingFields{TenantParentFieldIndexKey: tenant.Name}); err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// reconcileHooks runs the blueprint hooks of a phase and returns true when all hooks have completed
+func (r *TenantReconciler) reconcileHooks(ctx reconcile.Context, t *domain.TenantAggregate, blueprint corev1alpha1.Blueprint, phase corev1alpha1.HookPhase) (bool, reconcile.Result) {
+	runner := domain.NewHookRunner(ctx, domain.ResourceGeneratoreServices{Client: r.Client})
+
+	completed, err := t.RunHooks(runner, blueprint, phase)
+	if err != nil {
+		var hfe *domain.HookFailedError
+		if errors.As(err, &hfe) {
+			ctx.Log.Info("hook failed, rename the hook to retry or set the failure policy to Ignore to continue", "phase", phase, "hook", hfe.Name, "reason", hfe.Reason)
+			if phase == corev1alpha1.HookPhasePostReady {
+				return false, ctx.Done()
+			}
+			return false, ctx.RequeueIn(60, hfe.Error())
+		}
+		ctx.Log.Error(err, "failed to run hooks", "phase", phase)
+		return false, ctx.Error(err)
+	}
+
+	if !completed {
+		return false, ctx.RequeueIn(10, fmt.Sprintf("waiting for %s hooks to complete", phase))
+	}
+
+	return true, ctx.Done()
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *TenantReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &eventv1alpha1.EventStreamChunk{}, eventstore.StreamIdFieldIndexKey, func(o client.Object) []string {
@@ -187,7 +563,144 @@ func (r *TenantReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		return err
 	}
 
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &corev1alpha1.Tenant{}, TenantParentFieldIndexKey, func(o client.Object) []string {
+		tenant := o.(*corev1alpha1.Tenant)
+		if tenant.Spec.Parent == "" {
+			return nil
+		}
+		return []string{tenant.Spec.Parent}
+	}); err != nil {
+		return err
+	}
+
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &corev1alpha1.Tenant{}, TenantBlueprintFieldIndexKey, func(o client.Object) []string {
+		tenant := o.(*corev1alpha1.Tenant)
+		return tenant.BlueprintReferences()
+	}); err != nil {
+		return err
+	}
+
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &corev1alpha1.Blueprint{}, BlueprintTemplateFieldIndexKey, func(o client.Object) []string {
+		blueprint := o.(*corev1alpha1.Blueprint)
+		return blueprint.Templates()
+	}); err != nil {
+		return err
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&corev1alpha1.Tenant{}).
+		Watches(
+			&source.Kind{Type: &corev1alpha1.Tenant{}},
+			handler.EnqueueRequestsFromMapFunc(r.findRelatedTenants),
+		).
+		Watches(
+			&source.Kind{Type: &corev1alpha1.Blueprint{}},
+			handler.EnqueueRequestsFromMapFunc(r.findTenantsForBlueprint),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{}),
+		).
+		Watches(
+			&source.Kind{Type: &corev1alpha1.ResourceTemplate{}},
+			handler.EnqueueRequestsFromMapFunc(r.findTenantsForResourceTemplate),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{}),
+		).
 		Complete(r)
 }
+
+func (r *TenantReconciler) findTenantsForBlueprint(o client.Object) []kreconcile.Request {
+	blueprint := o.(*corev1alpha1.Blueprint)
+	if blueprint.Namespace != config.Operator.Namespace {
+		return []kreconcile.Request{}
+	}
+
+	descendants, err := blueprintDescendants(context.TODO(), r.Client.GetClient(), config.Operator.Namespace, blueprint.Name)
+	if err != nil {
+		return []kreconcile.Request{}
+	}
+
+	return r.findTenantsUsingBlueprints(corev1alpha1.BlueprintReferences(append([]corev1alpha1.Blueprint{*blueprint}, descendants...)...)...)
+}
+
+func (r *TenantReconciler) findTenantsForResourceTemplate(o client.Object) []kreconcile.Request {
+	template := o.(*corev1alpha1.ResourceTemplate)
+	if template.Namespace != config.Operator.Namespace {
+		return []kreconcile.Request{}
+	}
+
+	blueprints := &corev1alpha1.BlueprintList{}
+	err := r.Client.GetClient().List(context.TODO(), blueprints, client.InNamespace(config.Operator.Namespace), client.MatchingFields{BlueprintTemplateFieldIndexKey: template.Name})
+	if err != nil {
+		return []kreconcile.Request{}
+	}
+
+	names := make([]string, 0)
+	for _, b := range blueprints.Items {
+		names = append(names, b.Name)
+	}
+
+	descendants, err := blueprintDescendants(context.TODO(), r.Client.GetClient(), config.Operator.Namespace, names...)
+	if err != nil {
+		return []kreconcile.Request{}
+	}
+	for _, d := range descendants {
+		names = append(names, d.Name)
+	}
+
+	return r.findTenantsUsingBlueprints(names...)
+}
+
+func (r *TenantReconciler) findTenantsUsingBlueprints(names ...string) []kreconcile.Request {
+	requests := make([]kreconcile.Request, 0)
+	seen := make(map[types.NamespacedName]bool)
+
+	for _, name := range names {
+		tenants := &corev1alpha1.TenantList{}
+		err := r.Client.GetClient().List(context.TODO(), tenants, client.MatchingFields{TenantBlueprintFieldIndexKey: name})
+		if err != nil {
+			continue
+		}
+
+		for _, item := range tenants.Items {
+			nn := types.NamespacedName{
+				Name:      item.GetName(),
+				Namespace: item.GetNamespace(),
+			}
+			if seen[nn] {
+				continue
+			}
+			seen[nn] = true
+			requests = append(requests, kreconcile.Request{NamespacedName: nn})
+		}
+	}
+
+	return requests
+}
+
+func (r *TenantReconciler) findRelatedTenants(o client.Object) []kreconcile.Request {
+	tenant := o.(*corev1alpha1.Tenant)
+	requests := make([]kreconcile.Request, 0)
+
+	if tenant.Spec.Parent != "" {
+		requests = append(requests, kreconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Name:      tenant.Spec.Parent,
+				Namespace: tenant.Namespace,
+			},
+		})
+	}
+
+	children := &corev1alpha1.TenantList{}
+	err := r.Client.GetClient().List(context.TODO(), children, client.InNamespace(tenant.Namespace), client.MatchingFields{TenantParentFieldIndexKey: tenant.Name})
+	if err != nil {
+		return requests
+	}
+
+	for _, item := range children.Items {
+		requests = append(requests, kreconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Name:      item.GetName(),
+				Namespace: item.GetNamespace(),
+			},
+		})
+	}
+	return requests
+}
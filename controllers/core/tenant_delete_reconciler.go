@@ -10,6 +10,8 @@ import (
 	"github.com/kristofferahl/aeto/internal/pkg/reconcile"
 	"github.com/kristofferahl/aeto/internal/pkg/tenant"
 
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -19,6 +21,7 @@ func ReconcileDelete(ctx reconcile.Context, k8s kubernetes.Client, store eventso
 	state := deleteState{
 		Deleted:      false,
 		ResourceSets: make([]string, 0),
+		HookJobs:     make([]types.NamespacedName, 0),
 	}
 
 	handler := NewDeleteEventHandler(&state)
@@ -65,6 +68,27 @@ func ReconcileDelete(ctx reconcile.Context, k8s kubernetes.Client, store eventso
 		return ctx.RequeueIn(15, fmt.Sprintf("%d out of %d ResourceSets deleted", deletedResourceSets, len(state.ResourceSets)))
 	}
 
+	for _, job := range state.HookJobs {
+		if err := k8s.DynamicDelete(ctx, job, batchv1.SchemeGroupVersion.WithKind("Job")); err != nil {
+			ctx.Log.Error(err, "failed to delete hook Job", "job", job.String())
+			return ctx.Error(err)
+		}
+	}
+
+	if state.OutputsConfigMap.Name != "" && state.OutputsConfigMap.Namespace != "" {
+		if err := k8s.DynamicDelete(ctx, state.OutputsConfigMap, corev1.SchemeGroupVersion.WithKind("ConfigMap")); err != nil {
+			ctx.Log.Error(err, "failed to delete outputs ConfigMap", "config-map", state.OutputsConfigMap.String())
+			return ctx.Error(err)
+		}
+	}
+
+	if state.GeneratedValues.Name != "" && state.GeneratedValues.Namespace != "" {
+		if err := k8s.DynamicDelete(ctx, state.GeneratedValues, corev1.SchemeGroupVersion.WithKind("Secret")); err != nil {
+			ctx.Log.Error(err, "failed to delete generated values Secret", "secret", state.GeneratedValues.String())
+			return ctx.Error(err)
+		}
+	}
+
 	err := store.Delete(stream)
 	if err != nil {
 		ctx.Log.Error(err, "failed to delete EventStoreChunk(s)")
@@ -81,6 +105,11 @@ type DeleteEventHandler struct {
 type deleteState struct {
 	Deleted      bool
 	ResourceSets []string
+	HookJobs     []types.NamespacedName
+
+	Namespace        string
+	OutputsConfigMap types.NamespacedName
+	GeneratedValues  types.NamespacedName
 }
 
 func NewDeleteEventHandler(state *deleteState) eventsource.EventHandler {
@@ -93,6 +122,25 @@ func (h *DeleteEventHandler) On(e eventsource.Event) {
 	switch event := e.(type) {
 	case *tenant.ResourceSetCreated:
 		h.state.ResourceSets = append(h.state.ResourceSets, event.Name)
+	case *tenant.HookStarted:
+		h.state.HookJobs = append(h.state.HookJobs, types.NamespacedName{
+			Namespace: event.JobNamespace,
+			Name:      event.JobName,
+		})
+	case *tenant.ResourceNamespaceNameChanged:
+		h.state.Namespace = event.Namespace
+		if h.state.OutputsConfigMap.Name != "" {
+			h.state.OutputsConfigMap.Namespace = event.Namespace
+		}
+	case *tenant.OutputsChanged:
+		h.state.OutputsConfigMap = types.NamespacedName{}
+		if event.ConfigMap != "" {
+			h.state.OutputsConfigMap = types.NamespacedName{Namespace: h.state.Namespace, Name: event.ConfigMap}
+		}
+	case *tenant.TenantCreated:
+		h.state.GeneratedValues = tenant.GeneratedValuesSecret(types.NamespacedName{Namespace: event.Namespace, Name: event.Name})
+	case *tenant.GeneratedValuesStored:
+		h.state.GeneratedValues = types.NamespacedName{Namespace: event.Namespace, Name: event.Name}
 	case *tenant.TenantDeleted:
 		h.state.Deleted = true
 	}
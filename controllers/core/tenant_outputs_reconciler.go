@@ -0,0 +1,120 @@
+package core
+
+import (
+	"encoding/json"
+
+	corev1alpha1 "github.com/kristofferahl/aeto/apis/core/v1alpha1"
+	"github.com/kristofferahl/aeto/internal/pkg/eventsource"
+	"github.com/kristofferahl/aeto/internal/pkg/kubernetes"
+	"github.com/kristofferahl/aeto/internal/pkg/reconcile"
+	"github.com/kristofferahl/aeto/internal/pkg/tenant"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func ReconcileOutputs(ctx reconcile.Context, k8s kubernetes.Client, t *corev1alpha1.Tenant, stream eventsource.Stream) reconcile.Result {
+	state := outputsState{
+		Resources: make(tenant.ResourceList, 0),
+		Outputs:   make(tenant.OutputList, 0),
+	}
+
+	handler := NewOutputsEventHandler(&state)
+	res := eventsource.Replay(handler, stream.Events())
+	if res.Failed() {
+		ctx.Log.Error(res.Error, "failed to replay Tenant outputs from events")
+		return ctx.Error(res.Error)
+	}
+
+	resolved := true
+	outputs := make(map[string]string)
+	for _, output := range state.Outputs {
+		value, err := output.Resolve(ctx, k8s, state.Resources)
+		if err != nil {
+			ctx.Log.V(1).Info("failed to resolve Tenant output", "output", output.Name, "error", err.Error())
+			resolved = false
+			// the last resolved value is kept until the output resolves again, unless reading it is no longer allowed
+			_, resource := state.Resources.Find(output.ResourceId)
+			if previous, ok := t.Status.Outputs[output.Name]; ok && resource != nil && output.Validate(*resource) == nil {
+				outputs[output.Name] = previous
+			}
+			continue
+		}
+		outputs[output.Name] = value
+	}
+
+	t.Status.Outputs = nil
+	if len(outputs) > 0 {
+		t.Status.Outputs = outputs
+	}
+
+	if state.ConfigMap != "" && state.Namespace != "" {
+		cm := corev1.ConfigMap{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: "v1",
+				Kind:       "ConfigMap",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      state.ConfigMap,
+				Namespace: state.Namespace,
+				Labels: map[string]string{
+					"aeto.net/tenant": t.Name,
+				},
+			},
+			Data: outputs,
+		}
+		manifest, err := json.Marshal(cm)
+		if err != nil {
+			return ctx.Error(err)
+		}
+		nn := types.NamespacedName{Namespace: cm.Namespace, Name: cm.Name}
+		if err := k8s.DynamicApply(ctx, nn, string(manifest)); err != nil {
+			ctx.Log.Error(err, "failed to apply Tenant outputs ConfigMap", "config-map", nn.String())
+			return ctx.Error(err)
+		}
+	}
+
+	if !resolved {
+		return ctx.RequeueIn(15, "waiting for Tenant outputs to be resolved")
+	}
+
+	return ctx.Done()
+}
+
+type OutputsEventHandler struct {
+	state *outputsState
+}
+
+type outputsState struct {
+	Namespace string
+	ConfigMap string
+	Outputs   tenant.OutputList
+	Resources tenant.ResourceList
+}
+
+func NewOutputsEventHandler(state *outputsState) eventsource.EventHandler {
+	return &OutputsEventHandler{
+		state: state,
+	}
+}
+
+func (h *OutputsEventHandler) On(e eventsource.Event) {
+	switch event := e.(type) {
+	case *tenant.ResourceNamespaceNameChanged:
+		h.state.Namespace = event.Namespace
+	case *tenant.ResourceAdded:
+		h.state.Resources = append(h.state.Resources, event.Resource)
+	case *tenant.ResourceUpdated:
+		index, _ := h.state.Resources.Find(event.Resource.Id)
+		if index >= 0 {
+			h.state.Resources[index] = event.Resource
+		}
+	case *tenant.ResourceRemoved:
+		index, _ := h.state.Resources.Find(event.ResourceId)
+		h.state.Resources = h.state.Resources.Remove(index)
+	case *tenant.OutputsChanged:
+		h.state.Outputs = event.Outputs
+		h.state.ConfigMap = event.ConfigMap
+	}
+}
@@ -1,19 +1,27 @@
 package core
 
 import (
+	"fmt"
 	"strings"
+	"time"
 
 	corev1alpha1 "github.com/kristofferahl/aeto/apis/core/v1alpha1"
+	"github.com/kristofferahl/aeto/internal/pkg/convert"
 	"github.com/kristofferahl/aeto/internal/pkg/eventsource"
 	"github.com/kristofferahl/aeto/internal/pkg/kubernetes"
 	"github.com/kristofferahl/aeto/internal/pkg/reconcile"
 	"github.com/kristofferahl/aeto/internal/pkg/tenant"
+	"github.com/kristofferahl/aeto/internal/pkg/util"
 
 	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 )
 
+const (
+	maxPendingChanges = 20
+)
+
 func ReconcileStatus(ctx reconcile.Context, client kubernetes.Client, tenant corev1alpha1.Tenant, stream eventsource.Stream) reconcile.Result {
 	handler := NewTenantStatusEventHandler(&tenant.Status)
 	res := eventsource.Replay(handler, stream.Events())
@@ -34,6 +42,7 @@ func ReconcileStatus(ctx reconcile.Context, client kubernetes.Client, tenant cor
 		if err != nil {
 			ctx.Log.V(1).Info("failed to fetch ResourceSet, unable to check readiniess")
 		} else {
+			tenant.Status.Resources = rs.Status.Resources.Summarize()
 			rsReadyCondition := apimeta.FindStatusCondition(rs.Status.Conditions, ConditionTypeReady)
 			if rsReadyCondition != nil {
 				rsReady = true
@@ -53,6 +62,17 @@ func ReconcileStatus(ctx reconcile.Context, client kubernetes.Client, tenant cor
 				}
 				apimeta.SetStatusCondition(&tenant.Status.Conditions, readyCondition)
 			}
+			rsDriftedCondition := apimeta.FindStatusCondition(rs.Status.Conditions, ConditionTypeDrifted)
+			if rsDriftedCondition != nil {
+				driftedCondition := metav1.Condition{
+					Type:               ConditionTypeDrifted,
+					Status:             rsDriftedCondition.Status,
+					Reason:             rsDriftedCondition.Reason,
+					Message:            rsDriftedCondition.Message,
+					LastTransitionTime: rsDriftedCondition.LastTransitionTime,
+				}
+				apimeta.SetStatusCondition(&tenant.Status.Conditions, driftedCondition)
+			}
 		}
 	}
 
@@ -72,11 +92,17 @@ func ReconcileStatus(ctx reconcile.Context, client kubernetes.Client, tenant cor
 }
 
 type TenantStatusEventHandler struct {
-	state *corev1alpha1.TenantStatus
+	state     *corev1alpha1.TenantStatus
+	resources map[string]string
+	changes   []string
 }
 
 func NewTenantStatusEventHandler(state *corev1alpha1.TenantStatus) eventsource.EventHandler {
 	state.Events = 0
+	state.Hooks = nil
+	state.Adoptions = nil
+	state.ExpiresAt = nil
+	state.Maintenance = nil
 	readyCondition := metav1.Condition{
 		Type:    ConditionTypeReady,
 		Status:  metav1.ConditionFalse,
@@ -86,7 +112,8 @@ func NewTenantStatusEventHandler(state *corev1alpha1.TenantStatus) eventsource.E
 	apimeta.SetStatusCondition(&state.Conditions, readyCondition)
 
 	return &TenantStatusEventHandler{
-		state: state,
+		state:     state,
+		resources: make(map[string]string),
 	}
 }
 
@@ -116,11 +143,130 @@ func (h *TenantStatusEventHandler) On(e eventsource.Event) {
 			Namespace: event.Namespace,
 			Name:      event.Name,
 		}.String()
+		h.state.AddOns = nil
+		for _, addOn := range event.AddOns {
+			h.state.AddOns = append(h.state.AddOns, types.NamespacedName{
+				Namespace: event.Namespace,
+				Name:      addOn,
+			}.String())
+		}
 	case *tenant.ResourceSetCreated:
 		h.state.ResourceSet = types.NamespacedName{
 			Namespace: event.Namespace,
 			Name:      event.Name,
 		}.String()
+	case *tenant.ResourceAdded:
+		h.onResourceChange("+", event.Resource)
+	case *tenant.ResourceUpdated:
+		h.onResourceChange("~", event.Resource)
+	case *tenant.ResourceRemoved:
+		h.changes = append(h.changes, fmt.Sprintf("- %s", h.resources[event.ResourceId]))
+		delete(h.resources, event.ResourceId)
+	case *tenant.ResourceSetActivated:
+		if h.state.Status == PhasePendingApproval {
+			h.state.Status = ConditionTypeReconciling
+		}
+		h.state.Maintenance = nil
+		h.changes = nil
+	case *tenant.ResourceSetActivationDeferred:
+		h.state.Maintenance = &corev1alpha1.TenantMaintenanceStatus{
+			ResourceSet: h.state.ResourceSet,
+			Changes:     util.LimitStrings(h.changes, maxPendingChanges),
+		}
+		if next, err := time.Parse(time.RFC3339, event.NextWindow); err == nil {
+			h.state.Maintenance.NextWindow = &metav1.Time{Time: next}
+		}
+	case *tenant.ResourceGroupDeferred:
+		h.setDeferred(event.Name, event.WaitingFor)
+	case *tenant.ResourceGroupReleased:
+		h.setDeferred(event.Name, nil)
+	case *tenant.ResourceSetApprovalRequested:
+		approvedCondition := metav1.Condition{
+			Type:    ConditionTypeApproved,
+			Status:  metav1.ConditionFalse,
+			Reason:  "ApprovalRequested",
+			Message: fmt.Sprintf("ResourceSet %s is pending approval, approve it using the %s annotation naming a user allowed to %s resourcesets", event.Name, corev1alpha1.AnnotationApprovedBy, tenant.ApproveVerb),
+		}
+		apimeta.SetStatusCondition(&h.state.Conditions, approvedCondition)
+		h.state.Status = PhasePendingApproval
+	case *tenant.ResourceSetApproved:
+		approvedCondition := metav1.Condition{
+			Type:    ConditionTypeApproved,
+			Status:  metav1.ConditionTrue,
+			Reason:  "Approved",
+			Message: fmt.Sprintf("ResourceSet %s approved by %s", event.Name, event.ApprovedBy),
+		}
+		apimeta.SetStatusCondition(&h.state.Conditions, approvedCondition)
+		if h.state.Status == PhasePendingApproval {
+			h.state.Status = ConditionTypeReconciling
+		}
+	case *tenant.HookStarted:
+		h.state.Hooks = append(h.state.Hooks, corev1alpha1.TenantHookStatus{
+			Name:   event.Name,
+			Phase:  event.Phase,
+			Job:    types.NamespacedName{Namespace: event.JobNamespace, Name: event.JobName}.String(),
+			Status: string(tenant.HookJobRunning),
+		})
+	case *tenant.HookSucceeded:
+		h.onHook(event.Phase, event.Name, func(hs *corev1alpha1.TenantHookStatus) {
+			hs.Status = string(tenant.HookJobSucceeded)
+		})
+	case *tenant.HookFailed:
+		h.onHook(event.Phase, event.Name, func(hs *corev1alpha1.TenantHookStatus) {
+			hs.Status = string(tenant.HookJobFailed)
+			hs.Reason = event.Reason
+		})
+	case *tenant.ResourceAdoptionRequired:
+		h.onAdoption(event.ResourceId, func(as *corev1alpha1.TenantAdoptionStatus) {
+			as.Resource = resourceDescription(event.Kind, event.Namespace, event.Name)
+			as.Status = "Pending"
+			as.Diff = event.Diff
+		})
+	case *tenant.ResourceAdopted:
+		h.onAdoption(event.ResourceId, func(as *corev1alpha1.TenantAdoptionStatus) {
+			as.Resource = resourceDescription(event.Kind, event.Namespace, event.Name)
+			as.Status = "Adopted"
+			as.Diff = event.Diff
+		})
+	case *tenant.ResourceAdoptionDismissed:
+		for i, as := range h.state.Adoptions {
+			if as.Id == event.ResourceId {
+				h.state.Adoptions = append(h.state.Adoptions[:i], h.state.Adoptions[i+1:]...)
+				break
+			}
+		}
+	case *tenant.TenantExpirySet:
+		if event.ExpiresAt == "" {
+			h.state.ExpiresAt = nil
+			apimeta.RemoveStatusCondition(&h.state.Conditions, ConditionTypeExpiring)
+			break
+		}
+		if expiresAt, err := time.Parse(time.RFC3339, event.ExpiresAt); err == nil {
+			h.state.ExpiresAt = &metav1.Time{Time: expiresAt}
+		}
+		expiringCondition := metav1.Condition{
+			Type:    ConditionTypeExpiring,
+			Status:  metav1.ConditionFalse,
+			Reason:  "TenantExpirySet",
+			Message: fmt.Sprintf("Tenant expires at %s", event.ExpiresAt),
+		}
+		apimeta.SetStatusCondition(&h.state.Conditions, expiringCondition)
+	case *tenant.TenantExpiring:
+		expiringCondition := metav1.Condition{
+			Type:    ConditionTypeExpiring,
+			Status:  metav1.ConditionTrue,
+			Reason:  "TenantExpiring",
+			Message: fmt.Sprintf("Tenant expires at %s, extend the ttl using the %s annotation", event.ExpiresAt, corev1alpha1.AnnotationExtendTTL),
+		}
+		apimeta.SetStatusCondition(&h.state.Conditions, expiringCondition)
+	case *tenant.TenantExpired:
+		expiringCondition := metav1.Condition{
+			Type:    ConditionTypeExpiring,
+			Status:  metav1.ConditionTrue,
+			Reason:  "TenantExpired",
+			Message: fmt.Sprintf("Tenant expired at %s", event.ExpiresAt),
+		}
+		apimeta.SetStatusCondition(&h.state.Conditions, expiringCondition)
 	case *tenant.TenantDeleted:
 		reconcilingCondition := metav1.Condition{
 			Type:    ConditionTypeReconciling,
@@ -148,3 +294,51 @@ func (h *TenantStatusEventHandler) On(e eventsource.Event) {
 
 	h.state.Events++
 }
+
+func (h *TenantStatusEventHandler) onHook(phase string, name string, action func(hs *corev1alpha1.TenantHookStatus)) {
+	for i, hs := range h.state.Hooks {
+		if hs.Phase == phase && hs.Name == name {
+			action(&h.state.Hooks[i])
+		}
+	}
+}
+
+func (h *TenantStatusEventHandler) onResourceChange(change string, r tenant.Resource) {
+	description := r.Id
+	if u, err := convert.RawExtensionToUnstructured(r.Embedded.RawExtension); err == nil {
+		description = resourceDescription(u.GetKind(), u.GetNamespace(), u.GetName())
+	}
+	h.resources[r.Id] = description
+	h.changes = append(h.changes, fmt.Sprintf("%s %s", change, description))
+}
+
+func (h *TenantStatusEventHandler) setDeferred(name string, waitingFor []string) {
+	deferred := make([]corev1alpha1.TenantDeferredResourceGroupStatus, 0)
+	for _, d := range h.state.Deferred {
+		if d.Name != name {
+			deferred = append(deferred, d)
+		}
+	}
+	if len(waitingFor) > 0 {
+		deferred = append(deferred, corev1alpha1.TenantDeferredResourceGroupStatus{Name: name, WaitingFor: waitingFor})
+	}
+	h.state.Deferred = deferred
+}
+
+func (h *TenantStatusEventHandler) onAdoption(id string, action func(as *corev1alpha1.TenantAdoptionStatus)) {
+	for i, as := range h.state.Adoptions {
+		if as.Id == id {
+			action(&h.state.Adoptions[i])
+			return
+		}
+	}
+	h.state.Adoptions = append(h.state.Adoptions, corev1alpha1.TenantAdoptionStatus{Id: id})
+	action(&h.state.Adoptions[len(h.state.Adoptions)-1])
+}
+
+func resourceDescription(kind string, namespace string, name string) string {
+	if namespace != "" {
+		return fmt.Sprintf("%s %s/%s", kind, namespace, name)
+	}
+	return fmt.Sprintf("%s %s", kind, name)
+}
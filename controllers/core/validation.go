@@ -0,0 +1,37 @@
+package core
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	corev1alpha1 "github.com/kristofferahl/aeto/apis/core/v1alpha1"
+	"github.com/kristofferahl/aeto/internal/pkg/kubernetes"
+	"github.com/kristofferahl/aeto/internal/pkg/reconcile"
+	domain "github.com/kristofferahl/aeto/internal/pkg/tenant"
+)
+
+// templateSpec returns a func looking up the spec of resource templates, or the snapshot of pinned revisions, in the namespace
+func templateSpec(ctx reconcile.Context, k8s kubernetes.Client, namespace string) domain.TemplateSpecFunc {
+	return func(name string, revision string) (corev1alpha1.ResourceTemplateSpec, error) {
+		if revision != "" {
+			var rtr corev1alpha1.ResourceTemplateRevision
+			if err := k8s.Get(ctx, types.NamespacedName{Namespace: namespace, Name: revision}, &rtr); err != nil {
+				return corev1alpha1.ResourceTemplateSpec{}, fmt.Errorf("template revision %s not found, %v", revision, err)
+			}
+			if rtr.Spec.ResourceTemplate != name {
+				return corev1alpha1.ResourceTemplateSpec{}, fmt.Errorf("revision %s is a revision of resource template %s, not %s", revision, rtr.Spec.ResourceTemplate, name)
+			}
+			if err := domain.VerifyRevision(rtr.Name, rtr.Spec.Hash, rtr.Spec.Snapshot); err != nil {
+				return corev1alpha1.ResourceTemplateSpec{}, err
+			}
+			return rtr.Spec.Snapshot, nil
+		}
+
+		var rt corev1alpha1.ResourceTemplate
+		if err := k8s.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &rt); err != nil {
+			return corev1alpha1.ResourceTemplateSpec{}, fmt.Errorf("template %s not found, %v", name, err)
+		}
+		return rt.Spec, nil
+	}
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	corev1alpha1 "github.com/kristofferahl/aeto/apis/core/v1alpha1"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+)
+
+// authenticate validates the bearer token of the request using a TokenReview
+func (s *Server) authenticate(r *http.Request) (authenticationv1.UserInfo, error) {
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") {
+		return authenticationv1.UserInfo{}, errors.New("missing bearer token")
+	}
+
+	token := strings.TrimSpace(strings.TrimPrefix(header, "Bearer "))
+	if token == "" {
+		return authenticationv1.UserInfo{}, errors.New("missing bearer token")
+	}
+
+	review := authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{
+			Token: token,
+		},
+	}
+	if err := s.Client.Create(r.Context(), &review); err != nil {
+		return authenticationv1.UserInfo{}, err
+	}
+
+	if !review.Status.Authenticated {
+		return authenticationv1.UserInfo{}, fmt.Errorf("token not authenticated, %s", review.Status.Error)
+	}
+
+	return review.Status.User, nil
+}
+
+// authorize checks if the user is allowed to perform the verb on Tenants using a SubjectAccessReview
+func (s *Server) authorize(r *http.Request, user authenticationv1.UserInfo, verb string, namespace string, name string) (bool, error) {
+	extra := make(map[string]authorizationv1.ExtraValue)
+	for k, v := range user.Extra {
+		extra[k] = authorizationv1.ExtraValue(v)
+	}
+
+	review := authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:   user.Username,
+			UID:    user.UID,
+			Groups: user.Groups,
+			Extra:  extra,
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Group:     corev1alpha1.GroupVersion.Group,
+				Version:   corev1alpha1.GroupVersion.Version,
+				Resource:  "tenants",
+				Namespace: namespace,
+				Name:      name,
+				Verb:      verb,
+			},
+		},
+	}
+	if err := s.Client.Create(r.Context(), &review); err != nil {
+		return false, err
+	}
+
+	return review.Status.Allowed, nil
+}
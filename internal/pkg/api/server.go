@@ -0,0 +1,181 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+//+kubebuilder:rbac:groups=authentication.k8s.io,resources=tokenreviews,verbs=create
+//+kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
+
+const (
+	// PathPrefix is the path prefix of the tenant api
+	PathPrefix = "/apis/v1"
+
+	shutdownTimeout = 10 * time.Second
+	maxBodySize     = 1 << 20 // 1MiB
+)
+
+var _ manager.Runnable = &Server{}
+var _ manager.LeaderElectionRunnable = &Server{}
+
+// TLSOptions defines the certificate served by the api, bearer tokens must not be sent in clear text so serving plain
+// http requires Insecure to be set
+type TLSOptions struct {
+	CertFile string
+	KeyFile  string
+	Insecure bool
+}
+
+// Server serves the self-service tenant api, creating, listing, getting and deleting Tenants on behalf of authenticated users
+type Server struct {
+	Address string
+	TLS     TLSOptions
+	Client  client.Client
+	Log     logr.Logger
+}
+
+// NewServer returns a tenant api server listening on the address, an error is returned when no certificate is
+// specified and the api is not explicitly insecure
+func NewServer(address string, tls TLSOptions, c client.Client, log logr.Logger) (*Server, error) {
+	if (tls.CertFile == "" || tls.KeyFile == "") && !tls.Insecure {
+		return nil, fmt.Errorf("a certificate and key is required to serve the tenant api")
+	}
+
+	return &Server{
+		Address: address,
+		TLS:     tls,
+		Client:  c,
+		Log:     log,
+	}, nil
+}
+
+// Start serves the api until the context is cancelled
+func (s *Server) Start(ctx context.Context) error {
+	mux := http.NewServeMux()
+	mux.Handle(PathPrefix+"/", s)
+
+	srv := &http.Server{
+		Addr:    s.Address,
+		Handler: mux,
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		var err error
+		if s.TLS.CertFile != "" && s.TLS.KeyFile != "" {
+			s.Log.Info("starting tenant api", "address", s.Address)
+			err = srv.ListenAndServeTLS(s.TLS.CertFile, s.TLS.KeyFile)
+		} else {
+			s.Log.Info("starting tenant api without tls, bearer tokens are sent in clear text", "address", s.Address)
+			err = srv.ListenAndServe()
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errs <- err
+		}
+		close(errs)
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+		s.Log.Info("shutting down tenant api")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		return srv.Shutdown(shutdownCtx)
+	}
+}
+
+// NeedLeaderElection returns false as the api is served by all replicas
+func (s *Server) NeedLeaderElection() bool {
+	return false
+}
+
+// ServeHTTP routes requests for /apis/v1/namespaces/{namespace}/tenants[/{name}]
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
+
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, PathPrefix), "/"), "/")
+	if len(parts) < 3 || len(parts) > 4 || parts[0] != "namespaces" || parts[1] == "" || parts[2] != "tenants" {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	namespace := parts[1]
+	name := ""
+	if len(parts) == 4 {
+		name = parts[3]
+	}
+
+	user, err := s.authenticate(r)
+	if err != nil {
+		s.Log.V(1).Info("authentication failed", "error", err.Error())
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	verb, ok := verbFor(r.Method, name)
+	if !ok {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	allowed, err := s.authorize(r, user, verb, namespace, name)
+	if err != nil {
+		s.Log.Error(err, "authorization failed", "user", user.Username)
+		writeError(w, http.StatusInternalServerError, "authorization failed")
+		return
+	}
+	if !allowed {
+		writeError(w, http.StatusForbidden, "forbidden")
+		return
+	}
+
+	s.Log.V(1).Info("handling request", "user", user.Username, "verb", verb, "namespace", namespace, "name", name)
+
+	switch verb {
+	case "list":
+		s.list(w, r, namespace)
+	case "get":
+		s.get(w, r, namespace, name)
+	case "create":
+		s.create(w, r, namespace)
+	case "delete":
+		s.delete(w, r, namespace, name)
+	}
+}
+
+// verbFor returns the kubernetes verb of the http method
+func verbFor(method string, name string) (string, bool) {
+	switch {
+	case method == http.MethodGet && name == "":
+		return "list", true
+	case method == http.MethodGet:
+		return "get", true
+	case method == http.MethodPost && name == "":
+		return "create", true
+	case method == http.MethodDelete && name != "":
+		return "delete", true
+	}
+	return "", false
+}
+
+func writeJson(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJson(w, status, ErrorResponse{Error: message})
+}
@@ -0,0 +1,238 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	corev1alpha1 "github.com/kristofferahl/aeto/apis/core/v1alpha1"
+)
+
+// reviewingClient answers TokenReviews of the listed tokens and SubjectAccessReviews of the listed user verbs
+type reviewingClient struct {
+	client.Client
+	tokens  map[string]string
+	allowed map[string]bool
+	reviews []authorizationv1.SubjectAccessReview
+}
+
+func (c *reviewingClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	switch review := obj.(type) {
+	case *authenticationv1.TokenReview:
+		user, ok := c.tokens[review.Spec.Token]
+		review.Status.Authenticated = ok
+		review.Status.User = authenticationv1.UserInfo{Username: user}
+		if !ok {
+			review.Status.Error = "invalid token"
+		}
+		return nil
+	case *authorizationv1.SubjectAccessReview:
+		review.Status.Allowed = c.allowed[review.Spec.User+" "+review.Spec.ResourceAttributes.Verb]
+		c.reviews = append(c.reviews, *review)
+		return nil
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+var _ = Describe("Server", func() {
+	var (
+		c      *reviewingClient
+		server *httptest.Server
+	)
+
+	request := func(method string, path string, token string, body string) (int, string) {
+		req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+		Expect(err).NotTo(HaveOccurred())
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		res, err := server.Client().Do(req)
+		Expect(err).NotTo(HaveOccurred())
+		defer res.Body.Close()
+
+		b, err := io.ReadAll(res.Body)
+		Expect(err).NotTo(HaveOccurred())
+		return res.StatusCode, string(b)
+	}
+
+	tenant := func(name string, fullName string) *corev1alpha1.Tenant {
+		return &corev1alpha1.Tenant{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "tenants", Name: name},
+			Spec: corev1alpha1.TenantSpec{
+				Name:       fullName,
+				Parameters: []corev1alpha1.ParameterValue{{Name: "size", Value: "small"}},
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(corev1alpha1.AddToScheme(scheme)).To(Succeed())
+
+		c = &reviewingClient{
+			Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(tenant("acme", "Acme"), tenant("globex", "Globex")).Build(),
+			tokens: map[string]string{"jane-token": "jane", "john-token": "john"},
+			allowed: map[string]bool{
+				"jane list":   true,
+				"jane get":    true,
+				"jane create": true,
+				"jane delete": true,
+				"john list":   true,
+			},
+		}
+
+		s, err := NewServer("0", TLSOptions{Insecure: true}, c, logr.Discard())
+		Expect(err).NotTo(HaveOccurred())
+		mux := http.NewServeMux()
+		mux.Handle(PathPrefix+"/", s)
+		server = httptest.NewServer(mux)
+		DeferCleanup(server.Close)
+	})
+
+	It("should require a certificate unless insecure", func() {
+		_, err := NewServer("0", TLSOptions{}, c, logr.Discard())
+		Expect(err).To(MatchError("a certificate and key is required to serve the tenant api"))
+	})
+
+	DescribeTable("routing",
+		func(method string, path string, status int) {
+			code, _ := request(method, path, "jane-token", "")
+			Expect(code).To(Equal(status))
+		},
+		Entry("list", http.MethodGet, "/apis/v1/namespaces/tenants/tenants", http.StatusOK),
+		Entry("list with trailing slash", http.MethodGet, "/apis/v1/namespaces/tenants/tenants/", http.StatusOK),
+		Entry("get", http.MethodGet, "/apis/v1/namespaces/tenants/tenants/acme", http.StatusOK),
+		Entry("get missing", http.MethodGet, "/apis/v1/namespaces/tenants/tenants/initech", http.StatusNotFound),
+		Entry("unknown resource", http.MethodGet, "/apis/v1/namespaces/tenants/blueprints", http.StatusNotFound),
+		Entry("missing namespace", http.MethodGet, "/apis/v1/namespaces//tenants", http.StatusNotFound),
+		Entry("too deep", http.MethodGet, "/apis/v1/namespaces/tenants/tenants/acme/status", http.StatusNotFound),
+		Entry("root", http.MethodGet, "/apis/v1/", http.StatusNotFound),
+		Entry("delete collection", http.MethodDelete, "/apis/v1/namespaces/tenants/tenants", http.StatusMethodNotAllowed),
+		Entry("post to name", http.MethodPost, "/apis/v1/namespaces/tenants/tenants/acme", http.StatusMethodNotAllowed),
+		Entry("put", http.MethodPut, "/apis/v1/namespaces/tenants/tenants/acme", http.StatusMethodNotAllowed),
+	)
+
+	Describe("authentication", func() {
+		It("should reject requests without a bearer token", func() {
+			code, body := request(http.MethodGet, "/apis/v1/namespaces/tenants/tenants", "", "")
+			Expect(code).To(Equal(http.StatusUnauthorized))
+			Expect(body).To(MatchJSON(`{"error":"unauthorized"}`))
+			Expect(c.reviews).To(BeEmpty())
+		})
+
+		It("should reject tokens denied by the TokenReview", func() {
+			code, body := request(http.MethodGet, "/apis/v1/namespaces/tenants/tenants", "stolen-token", "")
+			Expect(code).To(Equal(http.StatusUnauthorized))
+			Expect(body).To(MatchJSON(`{"error":"unauthorized"}`))
+			Expect(c.reviews).To(BeEmpty())
+		})
+	})
+
+	Describe("authorization", func() {
+		It("should reject verbs denied by the SubjectAccessReview", func() {
+			code, body := request(http.MethodGet, "/apis/v1/namespaces/tenants/tenants/acme", "john-token", "")
+			Expect(code).To(Equal(http.StatusForbidden))
+			Expect(body).To(MatchJSON(`{"error":"forbidden"}`))
+		})
+
+		It("should review the verb on the tenant of the request", func() {
+			code, _ := request(http.MethodDelete, "/apis/v1/namespaces/tenants/tenants/acme", "john-token", "")
+			Expect(code).To(Equal(http.StatusForbidden))
+
+			Expect(c.reviews).To(HaveLen(1))
+			Expect(c.reviews[0].Spec.User).To(Equal("john"))
+			Expect(*c.reviews[0].Spec.ResourceAttributes).To(Equal(authorizationv1.ResourceAttributes{
+				Group:     "core.aeto.net",
+				Version:   "v1alpha1",
+				Resource:  "tenants",
+				Namespace: "tenants",
+				Name:      "acme",
+				Verb:      "delete",
+			}))
+
+			var t corev1alpha1.Tenant
+			Expect(c.Get(context.Background(), types.NamespacedName{Namespace: "tenants", Name: "acme"}, &t)).To(Succeed())
+		})
+
+		It("should allow verbs allowed by the SubjectAccessReview", func() {
+			code, body := request(http.MethodGet, "/apis/v1/namespaces/tenants/tenants", "john-token", "")
+			Expect(code).To(Equal(http.StatusOK))
+
+			var res TenantListResponse
+			Expect(json.Unmarshal([]byte(body), &res)).To(Succeed())
+			Expect(res.Items).To(HaveLen(2))
+		})
+	})
+
+	Describe("get", func() {
+		It("should return the tenant", func() {
+			code, body := request(http.MethodGet, "/apis/v1/namespaces/tenants/tenants/acme", "jane-token", "")
+			Expect(code).To(Equal(http.StatusOK))
+			Expect(body).To(MatchJSON(`{"name":"acme","namespace":"tenants","fullName":"Acme","parameters":{"size":"small"},"status":{"ready":false}}`))
+		})
+	})
+
+	Describe("create", func() {
+		It("should create the tenant", func() {
+			code, body := request(http.MethodPost, "/apis/v1/namespaces/tenants/tenants", "jane-token", `{"name":"initech","blueprint":"default","parameters":{"b":"2","a":"1"},"labels":{"team":"x"}}`)
+			Expect(code).To(Equal(http.StatusCreated))
+			Expect(body).To(MatchJSON(`{"name":"initech","namespace":"tenants","fullName":"initech","blueprint":"default","parameters":{"a":"1","b":"2"},"status":{"ready":false}}`))
+
+			var t corev1alpha1.Tenant
+			Expect(c.Get(context.Background(), types.NamespacedName{Namespace: "tenants", Name: "initech"}, &t)).To(Succeed())
+			Expect(t.Labels).To(Equal(map[string]string{"team": "x"}))
+			Expect(t.Spec.Name).To(Equal("initech"))
+			Expect(t.Spec.Blueprint).To(Equal("default"))
+			Expect(t.Spec.Parameters).To(Equal([]corev1alpha1.ParameterValue{{Name: "a", Value: "1"}, {Name: "b", Value: "2"}}))
+		})
+
+		DescribeTable("invalid requests",
+			func(body string, status int, expected string) {
+				code, res := request(http.MethodPost, "/apis/v1/namespaces/tenants/tenants", "jane-token", body)
+				Expect(code).To(Equal(status))
+				Expect(res).To(MatchJSON(fmt.Sprintf(`{"error":%q}`, expected)))
+			},
+			Entry("invalid json", `{"name":`, http.StatusBadRequest, "invalid request body"),
+			Entry("missing name", `{"fullName":"Initech"}`, http.StatusBadRequest, "name is required"),
+			Entry("existing tenant", `{"name":"acme"}`, http.StatusConflict, "tenant already exists"),
+			Entry("too large", fmt.Sprintf(`{"name":"initech","fullName":%q}`, strings.Repeat("a", maxBodySize)), http.StatusBadRequest, "invalid request body"),
+		)
+	})
+
+	Describe("delete", func() {
+		It("should delete the tenant", func() {
+			code, _ := request(http.MethodDelete, "/apis/v1/namespaces/tenants/tenants/acme", "jane-token", "")
+			Expect(code).To(Equal(http.StatusAccepted))
+
+			var t corev1alpha1.Tenant
+			err := c.Get(context.Background(), types.NamespacedName{Namespace: "tenants", Name: "acme"}, &t)
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should fail when the tenant does not exist", func() {
+			code, body := request(http.MethodDelete, "/apis/v1/namespaces/tenants/tenants/initech", "jane-token", "")
+			Expect(code).To(Equal(http.StatusNotFound))
+			Expect(body).To(MatchJSON(`{"error":"tenant not found"}`))
+		})
+	})
+})
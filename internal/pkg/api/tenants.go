@@ -0,0 +1,192 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	corev1alpha1 "github.com/kristofferahl/aeto/apis/core/v1alpha1"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// TenantRequest defines a tenant to create
+type TenantRequest struct {
+	Name       string            `json:"name"`
+	FullName   string            `json:"fullName,omitempty"`
+	Blueprint  string            `json:"blueprint,omitempty"`
+	AddOns     []string          `json:"addOns,omitempty"`
+	Parameters map[string]string `json:"parameters,omitempty"`
+	Labels     map[string]string `json:"labels,omitempty"`
+}
+
+// TenantResponse defines a tenant and its observed status
+type TenantResponse struct {
+	Name       string               `json:"name"`
+	Namespace  string               `json:"namespace"`
+	FullName   string               `json:"fullName"`
+	Blueprint  string               `json:"blueprint,omitempty"`
+	AddOns     []string             `json:"addOns,omitempty"`
+	Parameters map[string]string    `json:"parameters,omitempty"`
+	Status     TenantStatusResponse `json:"status"`
+}
+
+// TenantStatusResponse defines the observed status of a tenant
+type TenantStatusResponse struct {
+	Phase     string            `json:"phase,omitempty"`
+	Ready     bool              `json:"ready"`
+	Namespace string            `json:"namespace,omitempty"`
+	Blueprint string            `json:"blueprint,omitempty"`
+	Outputs   map[string]string `json:"outputs,omitempty"`
+}
+
+// TenantListResponse defines a list of tenants
+type TenantListResponse struct {
+	Items []TenantResponse `json:"items"`
+}
+
+// ErrorResponse defines an error
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+func (s *Server) list(w http.ResponseWriter, r *http.Request, namespace string) {
+	var tenants corev1alpha1.TenantList
+	if err := s.Client.List(r.Context(), &tenants, client.InNamespace(namespace)); err != nil {
+		s.writeClientError(w, err)
+		return
+	}
+
+	res := TenantListResponse{
+		Items: make([]TenantResponse, 0),
+	}
+	for _, tenant := range tenants.Items {
+		res.Items = append(res.Items, newTenantResponse(tenant))
+	}
+
+	writeJson(w, http.StatusOK, res)
+}
+
+func (s *Server) get(w http.ResponseWriter, r *http.Request, namespace string, name string) {
+	var tenant corev1alpha1.Tenant
+	if err := s.Client.Get(r.Context(), types.NamespacedName{Namespace: namespace, Name: name}, &tenant); err != nil {
+		s.writeClientError(w, err)
+		return
+	}
+
+	writeJson(w, http.StatusOK, newTenantResponse(tenant))
+}
+
+func (s *Server) create(w http.ResponseWriter, r *http.Request, namespace string) {
+	var req TenantRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.Name == "" {
+		writeError(w, http.StatusBadRequest, "name is required")
+		return
+	}
+
+	fullName := req.FullName
+	if fullName == "" {
+		fullName = req.Name
+	}
+
+	tenant := corev1alpha1.Tenant{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      req.Name,
+			Namespace: namespace,
+			Labels:    req.Labels,
+		},
+		Spec: corev1alpha1.TenantSpec{
+			Name:       fullName,
+			Blueprint:  req.Blueprint,
+			AddOns:     req.AddOns,
+			Parameters: parameterValues(req.Parameters),
+		},
+	}
+
+	if err := s.Client.Create(r.Context(), &tenant); err != nil {
+		s.writeClientError(w, err)
+		return
+	}
+
+	s.Log.Info("created Tenant", "tenant", tenant.NamespacedName().String())
+	writeJson(w, http.StatusCreated, newTenantResponse(tenant))
+}
+
+func (s *Server) delete(w http.ResponseWriter, r *http.Request, namespace string, name string) {
+	tenant := corev1alpha1.Tenant{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+	}
+
+	if err := s.Client.Delete(r.Context(), &tenant); err != nil {
+		s.writeClientError(w, err)
+		return
+	}
+
+	s.Log.Info("deleted Tenant", "tenant", tenant.NamespacedName().String())
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *Server) writeClientError(w http.ResponseWriter, err error) {
+	switch {
+	case apierrors.IsNotFound(err):
+		writeError(w, http.StatusNotFound, "tenant not found")
+	case apierrors.IsAlreadyExists(err):
+		writeError(w, http.StatusConflict, "tenant already exists")
+	case apierrors.IsInvalid(err), apierrors.IsBadRequest(err):
+		writeError(w, http.StatusBadRequest, err.Error())
+	default:
+		s.Log.Error(err, "tenant api request failed")
+		writeError(w, http.StatusInternalServerError, "internal error")
+	}
+}
+
+func newTenantResponse(tenant corev1alpha1.Tenant) TenantResponse {
+	parameters := make(map[string]string)
+	for _, p := range tenant.Spec.Parameters {
+		if p.ValueFrom == nil {
+			parameters[p.Name] = p.Value
+		}
+	}
+
+	return TenantResponse{
+		Name:       tenant.Name,
+		Namespace:  tenant.Namespace,
+		FullName:   tenant.Spec.Name,
+		Blueprint:  tenant.Spec.Blueprint,
+		AddOns:     tenant.Spec.AddOns,
+		Parameters: parameters,
+		Status: TenantStatusResponse{
+			Phase:     tenant.Status.Status,
+			Ready:     apimeta.IsStatusConditionTrue(tenant.Status.Conditions, "Ready"),
+			Namespace: tenant.Status.Namespace,
+			Blueprint: tenant.Status.Blueprint,
+			Outputs:   tenant.Status.Outputs,
+		},
+	}
+}
+
+// parameterValues returns the parameters as parameter values sorted by name
+func parameterValues(parameters map[string]string) []corev1alpha1.ParameterValue {
+	names := make([]string, 0)
+	for name := range parameters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	values := make([]corev1alpha1.ParameterValue, 0)
+	for _, name := range names {
+		values = append(values, corev1alpha1.ParameterValue{Name: name, Value: parameters[name]})
+	}
+	return values
+}
@@ -11,4 +11,7 @@ type OperatorConfig struct {
 	ReconcileInterval     time.Duration
 	Namespace             string
 	MaxTenantResourceSets int
+	MaxRevisionHistory    int
+	TenantExpiryWarning   time.Duration
+	ChartRoot             string
 }
@@ -74,22 +74,31 @@ func YamlToStringSlice(source string) ([]string, error) {
 	return res, nil
 }
 
-// RawExtensionToResourceIdentifier converts runtime.RawExtension to tenant.ResourceIdentifier
-func RawExtensionToResourceIdentifier(raw runtime.RawExtension) (common.ResourceIdentifier, error) {
+// RawExtensionToUnstructured converts runtime.RawExtension to unstructured.Unstructured
+func RawExtensionToUnstructured(raw runtime.RawExtension) (*unstructured.Unstructured, error) {
 	var obj runtime.Object
 	var scope conversion.Scope // While not actually used within the function, need to pass in
 
 	err := runtime.Convert_runtime_RawExtension_To_runtime_Object(&raw, &obj, scope)
 	if err != nil {
-		return common.ResourceIdentifier{}, err
+		return nil, err
 	}
 
 	innerObj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&obj)
+	if err != nil {
+		return nil, err
+	}
+
+	return &unstructured.Unstructured{Object: innerObj}, nil
+}
+
+// RawExtensionToResourceIdentifier converts runtime.RawExtension to tenant.ResourceIdentifier
+func RawExtensionToResourceIdentifier(raw runtime.RawExtension) (common.ResourceIdentifier, error) {
+	u, err := RawExtensionToUnstructured(raw)
 	if err != nil {
 		return common.ResourceIdentifier{}, err
 	}
 
-	u := unstructured.Unstructured{Object: innerObj}
 	return common.ResourceIdentifier{
 		NamespacedName: types.NamespacedName{
 			Namespace: u.GetNamespace(),
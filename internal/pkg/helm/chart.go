@@ -0,0 +1,97 @@
+package helm
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/engine"
+	"helm.sh/helm/v3/pkg/releaseutil"
+)
+
+// RenderOptions defines the release and values used to render a chart
+type RenderOptions struct {
+	ReleaseName string
+	Namespace   string
+	Values      map[string]interface{}
+	IncludeCRDs bool
+}
+
+// RenderResult holds the rendered manifests of a chart
+type RenderResult struct {
+	// Manifests is the rendered yaml documents in the order helm installs them, custom resource definitions first
+	Manifests []string
+
+	// SkippedHooks is the names of the rendered helm hooks, hooks are not part of the result
+	SkippedHooks []string
+}
+
+// LoadArchive loads a packaged chart (.tgz)
+func LoadArchive(archive []byte) (*chart.Chart, error) {
+	c, err := loader.LoadArchive(bytes.NewReader(archive))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load chart archive, %v", err)
+	}
+	return c, nil
+}
+
+// Render renders the templates of a chart the way helm renders them on install, using the default capabilities and
+// without access to the cluster (the lookup function returns empty results)
+func Render(c *chart.Chart, opts RenderOptions) (RenderResult, error) {
+	result := RenderResult{
+		Manifests:    make([]string, 0),
+		SkippedHooks: make([]string, 0),
+	}
+
+	if err := chartutil.ProcessDependencies(c, opts.Values); err != nil {
+		return result, fmt.Errorf("failed to process dependencies of chart %s, %v", c.Name(), err)
+	}
+
+	values, err := chartutil.ToRenderValues(c, opts.Values, chartutil.ReleaseOptions{
+		Name:      opts.ReleaseName,
+		Namespace: opts.Namespace,
+		Revision:  1,
+		IsInstall: true,
+	}, chartutil.DefaultCapabilities)
+	if err != nil {
+		return result, fmt.Errorf("invalid values for chart %s, %v", c.Name(), err)
+	}
+
+	rendered, err := engine.Render(c, values)
+	if err != nil {
+		return result, fmt.Errorf("failed to render chart %s, %v", c.Name(), err)
+	}
+
+	for name := range rendered {
+		if strings.HasSuffix(name, "NOTES.txt") {
+			delete(rendered, name)
+		}
+	}
+
+	hooks, manifests, err := releaseutil.SortManifests(rendered, chartutil.DefaultCapabilities.APIVersions, releaseutil.InstallOrder)
+	if err != nil {
+		return result, fmt.Errorf("failed to sort manifests of chart %s, %v", c.Name(), err)
+	}
+
+	if opts.IncludeCRDs {
+		for _, crd := range c.CRDObjects() {
+			if path.Ext(crd.Filename) == ".yaml" || path.Ext(crd.Filename) == ".yml" {
+				result.Manifests = append(result.Manifests, string(crd.File.Data))
+			}
+		}
+	}
+
+	for _, m := range manifests {
+		result.Manifests = append(result.Manifests, m.Content)
+	}
+
+	for _, h := range hooks {
+		result.SkippedHooks = append(result.SkippedHooks, fmt.Sprintf("%s/%s", h.Kind, h.Name))
+	}
+
+	return result, nil
+}
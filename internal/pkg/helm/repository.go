@@ -0,0 +1,186 @@
+package helm
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	yaml "github.com/ghodss/yaml"
+
+	"github.com/kristofferahl/aeto/internal/pkg/config"
+)
+
+const (
+	fetchTimeout = 30 * time.Second
+)
+
+var (
+	httpClient = &http.Client{Timeout: fetchTimeout}
+
+	// maxArchiveSize is the maximum size in bytes of an index or chart archive read from a repository
+	maxArchiveSize int64 = 20 * 1024 * 1024
+
+	// archives caches the chart archives fetched from repositories, a chart version is immutable
+	archives   = make(map[string][]byte)
+	archivesMu sync.Mutex
+)
+
+type indexFile struct {
+	Entries map[string][]indexEntry `json:"entries"`
+}
+
+type indexEntry struct {
+	Version string   `json:"version"`
+	URLs    []string `json:"urls"`
+	Digest  string   `json:"digest"`
+}
+
+// FetchArchive returns the packaged chart of a chart version from the index of a chart repository. Repositories are
+// read over http(s) or from the local filesystem (file://) below the configured chart root. The chart archive must be
+// served from the same scheme and host as the repository. Archives are cached for the lifetime of the process.
+func FetchArchive(repositoryURL string, name string, version string) ([]byte, error) {
+	key := fmt.Sprintf("%s|%s|%s", repositoryURL, name, version)
+
+	archivesMu.Lock()
+	archive, ok := archives[key]
+	archivesMu.Unlock()
+	if ok {
+		return archive, nil
+	}
+
+	base, err := url.Parse(strings.TrimSuffix(repositoryURL, "/") + "/")
+	if err != nil {
+		return nil, fmt.Errorf("invalid chart repository url %s, %v", repositoryURL, err)
+	}
+
+	b, err := fetch(base.ResolveReference(&url.URL{Path: "index.yaml"}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch index of chart repository %s, %v", repositoryURL, err)
+	}
+
+	var index indexFile
+	if err := yaml.Unmarshal(b, &index); err != nil {
+		return nil, fmt.Errorf("invalid index of chart repository %s, %v", repositoryURL, err)
+	}
+
+	var entry *indexEntry
+	for _, e := range index.Entries[name] {
+		e := e
+		if e.Version == version {
+			entry = &e
+			break
+		}
+	}
+	if entry == nil {
+		return nil, fmt.Errorf("chart %s version %s not found in chart repository %s", name, version, repositoryURL)
+	}
+	if len(entry.URLs) == 0 {
+		return nil, fmt.Errorf("chart %s version %s in chart repository %s has no urls", name, version, repositoryURL)
+	}
+
+	ref, err := url.Parse(entry.URLs[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid url of chart %s version %s, %v", name, version, err)
+	}
+
+	archiveURL := base.ResolveReference(ref)
+	if archiveURL.Scheme != base.Scheme || archiveURL.Host != base.Host {
+		return nil, fmt.Errorf("chart %s version %s is served from %s://%s, expected %s://%s of chart repository %s", name, version, archiveURL.Scheme, archiveURL.Host, base.Scheme, base.Host, repositoryURL)
+	}
+
+	archive, err = fetch(archiveURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch chart %s version %s, %v", name, version, err)
+	}
+
+	if entry.Digest != "" {
+		if digest := fmt.Sprintf("%x", sha256.Sum256(archive)); digest != entry.Digest {
+			return nil, fmt.Errorf("chart %s version %s has digest %s, expected %s", name, version, digest, entry.Digest)
+		}
+	}
+
+	archivesMu.Lock()
+	archives[key] = archive
+	archivesMu.Unlock()
+
+	return archive, nil
+}
+
+func fetch(u *url.URL) ([]byte, error) {
+	switch u.Scheme {
+	case "file":
+		p, err := chartRootPath(u.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		f, err := os.Open(p)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		return readLimited(u, f)
+	case "http", "https":
+		res, err := httpClient.Get(u.String())
+		if err != nil {
+			return nil, err
+		}
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("%s returned %s", u.String(), res.Status)
+		}
+		return readLimited(u, res.Body)
+	default:
+		return nil, fmt.Errorf("unsupported scheme %s", u.Scheme)
+	}
+}
+
+// chartRootPath returns the path of a file, failing unless the file is below the configured chart root
+func chartRootPath(p string) (string, error) {
+	if config.Operator.ChartRoot == "" {
+		return "", fmt.Errorf("file chart repositories are disabled, no chart root is configured")
+	}
+
+	root, err := filepath.EvalSymlinks(config.Operator.ChartRoot)
+	if err != nil {
+		return "", fmt.Errorf("invalid chart root %s, %v", config.Operator.ChartRoot, err)
+	}
+	root, err = filepath.Abs(root)
+	if err != nil {
+		return "", fmt.Errorf("invalid chart root %s, %v", config.Operator.ChartRoot, err)
+	}
+
+	resolved, err := filepath.EvalSymlinks(filepath.Clean(p))
+	if err != nil {
+		return "", err
+	}
+	resolved, err = filepath.Abs(resolved)
+	if err != nil {
+		return "", err
+	}
+
+	if rel, err := filepath.Rel(root, resolved); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside of the chart root %s", p, config.Operator.ChartRoot)
+	}
+	return resolved, nil
+}
+
+func readLimited(u *url.URL, r io.Reader) ([]byte, error) {
+	b, err := io.ReadAll(io.LimitReader(r, maxArchiveSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(b)) > maxArchiveSize {
+		return nil, fmt.Errorf("%s exceeds the maximum size of %d bytes", u.String(), maxArchiveSize)
+	}
+	return b, nil
+}
@@ -0,0 +1,154 @@
+package helm
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kristofferahl/aeto/internal/pkg/config"
+)
+
+var _ = Describe("FetchArchive", func() {
+	archive := []byte("chart archive")
+	digest := fmt.Sprintf("%x", sha256.Sum256(archive))
+
+	index := func(url string) []byte {
+		return []byte(fmt.Sprintf("entries:\n  app:\n    - version: 1.0.0\n      urls: [%q]\n      digest: %s\n", url, digest))
+	}
+
+	BeforeEach(func() {
+		size := maxArchiveSize
+		DeferCleanup(func() {
+			maxArchiveSize = size
+			config.Operator.ChartRoot = ""
+		})
+	})
+
+	Describe("http repositories", func() {
+		var (
+			server *httptest.Server
+			files  map[string][]byte
+		)
+
+		BeforeEach(func() {
+			files = map[string][]byte{"/charts/app-1.0.0.tgz": archive}
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, ok := files[r.URL.Path]
+				if !ok {
+					http.NotFound(w, r)
+					return
+				}
+				_, _ = w.Write(b)
+			}))
+			DeferCleanup(server.Close)
+		})
+
+		It("should fetch archives relative to the repository", func() {
+			files["/charts/index.yaml"] = index("app-1.0.0.tgz")
+			Expect(FetchArchive(server.URL+"/charts", "app", "1.0.0")).To(Equal(archive))
+		})
+
+		It("should fetch archives from the host of the repository", func() {
+			files["/index.yaml"] = index(server.URL + "/charts/app-1.0.0.tgz")
+			Expect(FetchArchive(server.URL, "app", "1.0.0")).To(Equal(archive))
+		})
+
+		It("should fail when the archive is served from another host", func() {
+			files["/index.yaml"] = index("http://charts.example.com/app-1.0.0.tgz")
+			_, err := FetchArchive(server.URL, "app", "1.0.0")
+			Expect(err).To(MatchError(ContainSubstring("chart app version 1.0.0 is served from http://charts.example.com")))
+		})
+
+		It("should fail when the archive is served from another scheme", func() {
+			files["/index.yaml"] = index("file:///charts/app-1.0.0.tgz")
+			_, err := FetchArchive(server.URL, "app", "1.0.0")
+			Expect(err).To(MatchError(ContainSubstring("chart app version 1.0.0 is served from file://")))
+		})
+
+		It("should fail when the archive exceeds the maximum size", func() {
+			maxArchiveSize = 512
+			files["/index.yaml"] = index("charts/app-1.0.0.tgz")
+			files["/charts/app-1.0.0.tgz"] = make([]byte, 1024)
+			_, err := FetchArchive(server.URL, "app", "1.0.0")
+			Expect(err).To(MatchError(fmt.Sprintf("failed to fetch chart app version 1.0.0, %s/charts/app-1.0.0.tgz exceeds the maximum size of 512 bytes", server.URL)))
+		})
+
+		It("should fail when the digest does not match", func() {
+			files["/index.yaml"] = []byte("entries:\n  app:\n    - version: 1.0.0\n      urls: [charts/app-1.0.0.tgz]\n      digest: abc\n")
+			_, err := FetchArchive(server.URL, "app", "1.0.0")
+			Expect(err).To(MatchError(fmt.Sprintf("chart app version 1.0.0 has digest %s, expected abc", digest)))
+		})
+
+		It("should fail when the version is not in the index", func() {
+			files["/index.yaml"] = index("charts/app-1.0.0.tgz")
+			_, err := FetchArchive(server.URL, "app", "2.0.0")
+			Expect(err).To(MatchError(fmt.Sprintf("chart app version 2.0.0 not found in chart repository %s", server.URL)))
+		})
+	})
+
+	Describe("file repositories", func() {
+		var root, outside string
+
+		write := func(dir, name string, b []byte) {
+			Expect(os.WriteFile(filepath.Join(dir, name), b, 0644)).To(Succeed())
+		}
+
+		BeforeEach(func() {
+			var err error
+			root, err = os.MkdirTemp("", "charts")
+			Expect(err).NotTo(HaveOccurred())
+			outside, err = os.MkdirTemp("", "outside")
+			Expect(err).NotTo(HaveOccurred())
+			DeferCleanup(os.RemoveAll, root)
+			DeferCleanup(os.RemoveAll, outside)
+
+			write(root, "index.yaml", index("app-1.0.0.tgz"))
+			write(root, "app-1.0.0.tgz", archive)
+			write(outside, "index.yaml", index("app-1.0.0.tgz"))
+			write(outside, "app-1.0.0.tgz", archive)
+			config.Operator.ChartRoot = root
+		})
+
+		It("should fetch archives below the chart root", func() {
+			Expect(FetchArchive("file://"+root, "app", "1.0.0")).To(Equal(archive))
+		})
+
+		It("should fail when no chart root is configured", func() {
+			config.Operator.ChartRoot = ""
+			_, err := FetchArchive("file://"+outside, "app", "1.0.0")
+			Expect(err).To(MatchError(ContainSubstring("file chart repositories are disabled")))
+		})
+
+		It("should fail when the repository is outside of the chart root", func() {
+			_, err := FetchArchive("file://"+outside, "app", "1.0.0")
+			Expect(err).To(MatchError(ContainSubstring("is outside of the chart root")))
+		})
+
+		It("should fail when the archive is outside of the chart root", func() {
+			write(root, "index.yaml", index(filepath.Join(outside, "app-1.0.0.tgz")))
+			_, err := FetchArchive("file://"+root, "app", "1.0.0")
+			Expect(err).To(MatchError(ContainSubstring("is outside of the chart root")))
+		})
+
+		It("should fail when a symlink leaves the chart root", func() {
+			Expect(os.Mkdir(filepath.Join(root, "linked"), 0755)).To(Succeed())
+			write(filepath.Join(root, "linked"), "index.yaml", index("app-1.0.0.tgz"))
+			Expect(os.Symlink(filepath.Join(outside, "app-1.0.0.tgz"), filepath.Join(root, "linked", "app-1.0.0.tgz"))).To(Succeed())
+			_, err := FetchArchive("file://"+filepath.Join(root, "linked"), "app", "1.0.0")
+			Expect(err).To(MatchError(ContainSubstring("is outside of the chart root")))
+		})
+
+		It("should fail when the archive exceeds the maximum size", func() {
+			maxArchiveSize = 512
+			write(root, "app-1.0.0.tgz", make([]byte, 1024))
+			_, err := FetchArchive("file://"+root, "app", "1.0.0")
+			Expect(err).To(MatchError(fmt.Sprintf("failed to fetch chart app version 1.0.0, file://%s/app-1.0.0.tgz exceeds the maximum size of 512 bytes", root)))
+		})
+	})
+})
@@ -0,0 +1,14 @@
+package helm
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestHelm(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "Helm Suite")
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/kristofferahl/aeto/internal/pkg/reconcile"
 
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -30,6 +31,9 @@ var (
 	defaultUpdateOptions = client.UpdateOptions{
 		FieldManager: FieldManagerName,
 	}
+	defaultPatchOptions = client.PatchOptions{
+		FieldManager: FieldManagerName,
+	}
 	defaultDeleteOptions = client.DeleteOptions{}
 )
 
@@ -38,7 +42,7 @@ type Client struct {
 	client    client.Client
 	dynamic   dynamic.Interface
 	discovery *discovery.DiscoveryClient
-	mapper    *restmapper.DeferredDiscoveryRESTMapper
+	mapper    meta.RESTMapper
 }
 
 // NewClient returns a new Client.
@@ -52,6 +56,12 @@ func NewClient(client client.Client, dynamic dynamic.Interface, discovery *disco
 	}
 }
 
+// WithRESTMapper returns a copy of the client mapping kinds to resources with the specified mapper instead of discovery
+func (c Client) WithRESTMapper(mapper meta.RESTMapper) Client {
+	c.mapper = mapper
+	return c
+}
+
 // GetClient returns the underlying client.Client
 func (c Client) GetClient() client.Client {
 	return c.client
@@ -104,6 +114,18 @@ func (c Client) Update(ctx reconcile.Context, obj client.Object) error {
 	return nil
 }
 
+// Patch patches the given obj in the Kubernetes cluster. obj must be a
+// struct pointer so that obj can be updated with the content returned by the Server.
+func (c Client) Patch(ctx reconcile.Context, obj client.Object, patch client.Patch) error {
+	okt := ObjectKeyType(client.ObjectKeyFromObject(obj), obj)
+	c.logDebug(ctx, "patching %s", okt)
+	if err := c.client.Patch(ctx.Context, obj, patch, &defaultPatchOptions); err != nil {
+		c.logError(ctx, err, "failed to patch %s", okt)
+		return err
+	}
+	return nil
+}
+
 // Update updates the fields corresponding to the status subresource for the
 // given obj. obj must be a struct pointer so that obj can be updated
 // with the content returned by the Server.
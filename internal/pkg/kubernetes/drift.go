@@ -0,0 +1,204 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Drift returns the sorted paths of the fields of the desired resource that have drifted in the live resource. The
+// fields compared are the fields owned by the aeto field manager according to the managed fields of the live resource.
+// Fields of the desired resource no longer owned by aeto have been taken over by another field manager and are
+// reported as drifted. A resource without managed fields of aeto is reported as drifted as a whole (.).
+func Drift(desired *unstructured.Unstructured, live *unstructured.Unstructured) ([]string, error) {
+	var entry *metav1.ManagedFieldsEntry
+	for _, mf := range live.GetManagedFields() {
+		mf := mf
+		if mf.Manager == FieldManagerName && mf.Operation == metav1.ManagedFieldsOperationApply && mf.Subresource == "" {
+			entry = &mf
+			break
+		}
+	}
+	if entry == nil || entry.FieldsV1 == nil {
+		return []string{"."}, nil
+	}
+
+	fields := make(map[string]interface{})
+	if err := json.Unmarshal(entry.FieldsV1.Raw, &fields); err != nil {
+		return nil, fmt.Errorf("invalid managed fields of %s %s, %v", live.GetKind(), live.GetName(), err)
+	}
+
+	diff := make([]string, 0)
+	driftOwned("", fields, desired.Object, live.Object, &diff)
+	driftUnowned("", fields, desired.Object, &diff)
+	sort.Strings(diff)
+	return diff, nil
+}
+
+// driftOwned compares the desired and live values of the fields in the field set
+func driftOwned(path string, fields map[string]interface{}, desired interface{}, live interface{}, diff *[]string) {
+	for key, sub := range fields {
+		subFields, _ := sub.(map[string]interface{})
+
+		var p string
+		var dv, lv interface{}
+		var dok, lok bool
+
+		switch {
+		case strings.HasPrefix(key, "f:"):
+			name := strings.TrimPrefix(key, "f:")
+			p = path + "." + name
+			dm, _ := desired.(map[string]interface{})
+			lm, _ := live.(map[string]interface{})
+			dv, dok = dm[name]
+			lv, lok = lm[name]
+		case strings.HasPrefix(key, "k:"):
+			var k map[string]interface{}
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(key, "k:")), &k); err != nil {
+				continue
+			}
+			p = path + formatKey(k)
+			dv, dok = findItem(desired, func(item interface{}) bool { return matchesKey(item, k) })
+			lv, lok = findItem(live, func(item interface{}) bool { return matchesKey(item, k) })
+		case strings.HasPrefix(key, "v:"):
+			var v interface{}
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(key, "v:")), &v); err != nil {
+				continue
+			}
+			p = fmt.Sprintf("%s[%s]", path, strings.TrimPrefix(key, "v:"))
+			dv, dok = findItem(desired, func(item interface{}) bool { return equalJson(item, v) })
+			lv, lok = findItem(live, func(item interface{}) bool { return equalJson(item, v) })
+		case strings.HasPrefix(key, "i:"):
+			i, err := strconv.Atoi(strings.TrimPrefix(key, "i:"))
+			if err != nil {
+				continue
+			}
+			p = fmt.Sprintf("%s[%d]", path, i)
+			dv, dok = itemAt(desired, i)
+			lv, lok = itemAt(live, i)
+		default:
+			continue
+		}
+
+		if !dok {
+			// Owned but no longer desired, the field is removed on the next apply
+			continue
+		}
+		if !lok {
+			*diff = append(*diff, p)
+			continue
+		}
+		if isLeaf(subFields) {
+			if !equalJson(dv, lv) {
+				*diff = append(*diff, p)
+			}
+			continue
+		}
+		driftOwned(p, subFields, dv, lv, diff)
+	}
+}
+
+// driftUnowned returns the fields of the desired object missing from the field set, lists are compared as a whole
+func driftUnowned(path string, fields map[string]interface{}, desired interface{}, diff *[]string) {
+	dm, ok := desired.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	for name, dv := range dm {
+		p := path + "." + name
+		if dv == nil {
+			continue
+		}
+		switch p {
+		case ".apiVersion", ".kind", ".status", ".metadata.name", ".metadata.namespace":
+			continue
+		}
+
+		sub, ok := fields["f:"+name]
+		if !ok {
+			*diff = append(*diff, p)
+			continue
+		}
+		if subFields, _ := sub.(map[string]interface{}); !isLeaf(subFields) {
+			driftUnowned(p, subFields, dv, diff)
+		}
+	}
+}
+
+// isLeaf returns true when the field set has no children, a field set with only . owns the presence of a list item
+func isLeaf(fields map[string]interface{}) bool {
+	for key := range fields {
+		if key != "." {
+			return false
+		}
+	}
+	return true
+}
+
+func findItem(list interface{}, match func(item interface{}) bool) (interface{}, bool) {
+	items, ok := list.([]interface{})
+	if !ok {
+		return nil, false
+	}
+	for _, item := range items {
+		if match(item) {
+			return item, true
+		}
+	}
+	return nil, false
+}
+
+func itemAt(list interface{}, i int) (interface{}, bool) {
+	items, ok := list.([]interface{})
+	if !ok || i < 0 || i >= len(items) {
+		return nil, false
+	}
+	return items[i], true
+}
+
+// matchesKey returns true when the list item has the values of the key
+func matchesKey(item interface{}, key map[string]interface{}) bool {
+	m, ok := item.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	for k, v := range key {
+		if !equalJson(m[k], v) {
+			return false
+		}
+	}
+	return true
+}
+
+// formatKey formats the key of a list item as [k=v,...], sorted by name
+func formatKey(key map[string]interface{}) string {
+	names := make([]string, 0, len(key))
+	for k := range key {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, 0, len(names))
+	for _, k := range names {
+		pairs = append(pairs, fmt.Sprintf("%s=%v", k, key[k]))
+	}
+	return "[" + strings.Join(pairs, ",") + "]"
+}
+
+// equalJson compares values by their json representation, numbers decoded from json and numbers of unstructured
+// objects are of different types
+func equalJson(a interface{}, b interface{}) bool {
+	if reflect.DeepEqual(a, b) {
+		return true
+	}
+	ab, aerr := json.Marshal(a)
+	bb, berr := json.Marshal(b)
+	return aerr == nil && berr == nil && string(ab) == string(bb)
+}
@@ -0,0 +1,107 @@
+package kubernetes
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const deploymentFields = `{
+	"f:metadata": {"f:labels": {"f:app": {}}},
+	"f:spec": {
+		"f:replicas": {},
+		"f:template": {"f:spec": {"f:containers": {
+			"k:{\"name\":\"app\"}": {
+				".": {},
+				"f:image": {},
+				"f:name": {},
+				"f:ports": {"k:{\"containerPort\":80,\"protocol\":\"TCP\"}": {".": {}, "f:containerPort": {}, "f:protocol": {}}}
+			}
+		}}}
+	}
+}`
+
+func testDeployment() *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":      "app",
+			"namespace": "default",
+			"labels":    map[string]interface{}{"app": "app"},
+		},
+		"spec": map[string]interface{}{
+			"replicas": int64(2),
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{
+							"name":  "app",
+							"image": "nginx:1.21",
+							"ports": []interface{}{
+								map[string]interface{}{"containerPort": int64(80), "protocol": "TCP"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}}
+}
+
+func testContainer(u *unstructured.Unstructured) map[string]interface{} {
+	containers, _, _ := unstructured.NestedSlice(u.Object, "spec", "template", "spec", "containers")
+	return containers[0].(map[string]interface{})
+}
+
+func setContainer(u *unstructured.Unstructured, container map[string]interface{}) {
+	unstructured.SetNestedSlice(u.Object, []interface{}{container}, "spec", "template", "spec", "containers")
+}
+
+var _ = Describe("Drift", func() {
+	DescribeTable("fields owned by aeto",
+		func(manager string, fields string, change func(live *unstructured.Unstructured), expected []string) {
+			desired := testDeployment()
+			live := testDeployment()
+			live.SetManagedFields([]metav1.ManagedFieldsEntry{{
+				Manager:   manager,
+				Operation: metav1.ManagedFieldsOperationApply,
+				FieldsV1:  &metav1.FieldsV1{Raw: []byte(fields)},
+			}})
+			if change != nil {
+				change(live)
+			}
+
+			drift, err := Drift(desired, live)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(drift).To(Equal(expected))
+		},
+		Entry("no changes", FieldManagerName, deploymentFields, nil, []string{}),
+		Entry("defaulted fields are ignored", FieldManagerName, deploymentFields, func(live *unstructured.Unstructured) {
+			c := testContainer(live)
+			c["terminationMessagePath"] = "/dev/termination-log"
+			setContainer(live, c)
+			unstructured.SetNestedField(live.Object, int64(10), "spec", "revisionHistoryLimit")
+		}, []string{}),
+		Entry("changed owned field", FieldManagerName, deploymentFields, func(live *unstructured.Unstructured) {
+			unstructured.SetNestedField(live.Object, int64(3), "spec", "replicas")
+		}, []string{".spec.replicas"}),
+		Entry("changed field of a keyed list item", FieldManagerName, deploymentFields, func(live *unstructured.Unstructured) {
+			c := testContainer(live)
+			c["image"] = "nginx:1.22"
+			setContainer(live, c)
+		}, []string{".spec.template.spec.containers[name=app].image"}),
+		Entry("removed keyed list item", FieldManagerName, deploymentFields, func(live *unstructured.Unstructured) {
+			c := testContainer(live)
+			c["ports"] = []interface{}{}
+			setContainer(live, c)
+		}, []string{".spec.template.spec.containers[name=app].ports[containerPort=80,protocol=TCP]"}),
+		Entry("removed label", FieldManagerName, deploymentFields, func(live *unstructured.Unstructured) {
+			live.SetLabels(nil)
+		}, []string{".metadata.labels"}),
+		Entry("field taken over by another manager", FieldManagerName, `{"f:metadata": {"f:labels": {"f:app": {}}}, "f:spec": {"f:template": {"f:spec": {"f:containers": {}}}}}`, nil, []string{".spec.replicas"}),
+		Entry("not managed by aeto", "kubectl", deploymentFields, nil, []string{"."}),
+	)
+})
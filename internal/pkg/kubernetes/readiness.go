@@ -0,0 +1,44 @@
+package kubernetes
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PaesslerAG/jsonpath"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// ReadinessConditionSource is the source of the readiness of resources with a Ready condition
+	ReadinessConditionSource = "condition"
+
+	// ReadinessFieldSource is the source of the readiness of resources with a ready status field
+	ReadinessFieldSource = "field"
+)
+
+// ResourceReady checks the readiness of a resource using its Ready condition or ready status field. Checked is false when
+// the resource reports neither.
+func ResourceReady(u *unstructured.Unstructured) (checked bool, ready bool, source string) {
+	status, found, err := unstructured.NestedMap(u.Object, "status")
+	if err != nil || !found {
+		return false, false, ""
+	}
+
+	readyCondition, err := jsonpath.Get("$.conditions[?(@.type == \"Ready\")].status", status)
+	if err == nil {
+		results := readyCondition.([]interface{})
+		if len(results) == 1 {
+			return true, strings.ToLower(fmt.Sprintf("%s", results[0])) == "true", ReadinessConditionSource
+		}
+	}
+
+	readyField, err := jsonpath.Get("$.ready", status)
+	if err == nil {
+		readyStatus := strings.ToLower(fmt.Sprintf("%v", readyField))
+		if readyStatus == "true" || readyStatus == "false" {
+			return true, readyStatus == "true", ReadinessFieldSource
+		}
+	}
+
+	return false, false, ""
+}
@@ -0,0 +1,51 @@
+package kubernetes
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func withStatus(status map[string]interface{}) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "example.com/v1",
+		"kind":       "Example",
+		"metadata":   map[string]interface{}{"name": "example"},
+	}}
+	if status != nil {
+		u.Object["status"] = status
+	}
+	return u
+}
+
+func readyCondition(status string) map[string]interface{} {
+	return map[string]interface{}{"type": "Ready", "status": status}
+}
+
+var _ = Describe("Readiness", func() {
+	DescribeTable("ResourceReady",
+		func(u *unstructured.Unstructured, checked bool, ready bool, source string) {
+			c, r, s := ResourceReady(u)
+			Expect(c).To(Equal(checked))
+			Expect(r).To(Equal(ready))
+			Expect(s).To(Equal(source))
+		},
+		Entry("without status", withStatus(nil), false, false, ""),
+		Entry("without ready condition or field", withStatus(map[string]interface{}{"phase": "Running"}), false, false, ""),
+		Entry("ready condition true", withStatus(map[string]interface{}{
+			"conditions": []interface{}{map[string]interface{}{"type": "Synced", "status": "False"}, readyCondition("True")},
+		}), true, true, ReadinessConditionSource),
+		Entry("ready condition false", withStatus(map[string]interface{}{
+			"conditions": []interface{}{readyCondition("False")},
+		}), true, false, ReadinessConditionSource),
+		Entry("ready condition takes precedence over the ready field", withStatus(map[string]interface{}{
+			"conditions": []interface{}{readyCondition("False")},
+			"ready":      true,
+		}), true, false, ReadinessConditionSource),
+		Entry("ready field true", withStatus(map[string]interface{}{"ready": true}), true, true, ReadinessFieldSource),
+		Entry("ready field false", withStatus(map[string]interface{}{"ready": false}), true, false, ReadinessFieldSource),
+		Entry("ready field as string", withStatus(map[string]interface{}{"ready": "True"}), true, true, ReadinessFieldSource),
+		Entry("ready field that is not a bool", withStatus(map[string]interface{}{"ready": "1/1"}), false, false, ""),
+	)
+})
@@ -0,0 +1,131 @@
+package kubernetes
+
+import (
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/kristofferahl/aeto/internal/pkg/reconcile"
+)
+
+var secretReferenceMarker = regexp.MustCompile(`\$\(aeto:(secret|secret-b64):([^/)]+)/([^/)]+)/([^/)]+)\)`)
+
+// SecretReference defines a key of a Secret
+type SecretReference struct {
+	Namespace string
+	Name      string
+	Key       string
+}
+
+// SecretValues holds values read from Secrets, keyed by their reference
+type SecretValues map[SecretReference]string
+
+// Marker returns the placeholder used in place of the value of the referenced Secret key
+func (r SecretReference) Marker() string {
+	return fmt.Sprintf("$(aeto:secret:%s/%s/%s)", r.Namespace, r.Name, r.Key)
+}
+
+// Base64Marker returns the placeholder used in place of the base64 encoded value of the referenced Secret key
+func (r SecretReference) Base64Marker() string {
+	return fmt.Sprintf("$(aeto:secret-b64:%s/%s/%s)", r.Namespace, r.Name, r.Key)
+}
+
+func (r SecretReference) String() string {
+	return fmt.Sprintf("%s/%s/%s", r.Namespace, r.Name, r.Key)
+}
+
+// ContainsSecretReference returns true when the string contains a marker referencing a Secret key
+func ContainsSecretReference(s string) bool {
+	return secretReferenceMarker.MatchString(s)
+}
+
+// ParseSecretReference returns the reference of a string consisting of a single marker, false when it is not a marker
+func ParseSecretReference(s string) (SecretReference, bool) {
+	m := secretReferenceMarker.FindStringSubmatch(s)
+	if m == nil || m[0] != s || m[1] != "secret" {
+		return SecretReference{}, false
+	}
+	return SecretReference{Namespace: m[2], Name: m[3], Key: m[4]}, true
+}
+
+// Referenced returns the references of the values found as markers in the string, sorted by reference
+func (sv SecretValues) Referenced(s string) []SecretReference {
+	refs := make([]SecretReference, 0)
+	for ref := range sv {
+		if strings.Contains(s, ref.Marker()) || strings.Contains(s, ref.Base64Marker()) {
+			refs = append(refs, ref)
+		}
+	}
+	sort.Slice(refs, func(i, j int) bool {
+		return refs[i].String() < refs[j].String()
+	})
+	return refs
+}
+
+// ResolveSecretReferences replaces the markers referencing Secret keys found in string fields of the object with the
+// values of the Secrets
+func (c Client) ResolveSecretReferences(ctx reconcile.Context, obj map[string]interface{}) error {
+	secrets := make(map[types.NamespacedName]*corev1.Secret)
+	var err error
+
+	resolve := func(s string) string {
+		return secretReferenceMarker.ReplaceAllStringFunc(s, func(marker string) string {
+			if err != nil {
+				return marker
+			}
+			m := secretReferenceMarker.FindStringSubmatch(marker)
+			nn := types.NamespacedName{Namespace: m[2], Name: m[3]}
+
+			secret, ok := secrets[nn]
+			if !ok {
+				secret = &corev1.Secret{}
+				if e := c.Get(ctx, nn, secret); e != nil {
+					err = fmt.Errorf("failed to resolve secret reference %s, %v", SecretReference{Namespace: m[2], Name: m[3], Key: m[4]}.String(), e)
+					return marker
+				}
+				secrets[nn] = secret
+			}
+
+			value, ok := secret.Data[m[4]]
+			if !ok {
+				err = fmt.Errorf("failed to resolve secret reference %s, key not found", SecretReference{Namespace: m[2], Name: m[3], Key: m[4]}.String())
+				return marker
+			}
+
+			if m[1] == "secret-b64" {
+				return base64.StdEncoding.EncodeToString(value)
+			}
+			return string(value)
+		})
+	}
+
+	for key, value := range obj {
+		obj[key] = replaceStrings(value, resolve)
+	}
+	return err
+}
+
+// replaceStrings applies the replace func to all strings of a value decoded from json
+func replaceStrings(value interface{}, replace func(string) string) interface{} {
+	switch v := value.(type) {
+	case string:
+		return replace(v)
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = replaceStrings(item, replace)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = replaceStrings(item, replace)
+		}
+		return v
+	default:
+		return value
+	}
+}
@@ -0,0 +1,33 @@
+package kubernetes
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SecretValues", func() {
+	password := SecretReference{Namespace: "default", Name: "db", Key: "password"}
+	user := SecretReference{Namespace: "default", Name: "db", Key: "user"}
+	values := SecretValues{password: "s3cr3t-pass", user: "s3cr3t"}
+
+	DescribeTable("ParseSecretReference",
+		func(s string, expected SecretReference, ok bool) {
+			ref, found := ParseSecretReference(s)
+			Expect(found).To(Equal(ok))
+			Expect(ref).To(Equal(expected))
+		},
+		Entry("marker", "$(aeto:secret:default/db/user)", user, true),
+		Entry("base64 marker", "$(aeto:secret-b64:default/db/user)", SecretReference{}, false),
+		Entry("marker inside a string", "--user=$(aeto:secret:default/db/user)", SecretReference{}, false),
+		Entry("not a marker", "s3cr3t", SecretReference{}, false),
+	)
+
+	DescribeTable("Referenced",
+		func(s string, expected []SecretReference) {
+			Expect(values.Referenced(s)).To(Equal(expected))
+		},
+		Entry("no markers", "s3cr3t", []SecretReference{}),
+		Entry("sorted references", "$(aeto:secret:default/db/user) $(aeto:secret-b64:default/db/password)", []SecretReference{password, user}),
+		Entry("unknown references are ignored", "$(aeto:secret:default/other/key)", []SecretReference{}),
+	)
+})
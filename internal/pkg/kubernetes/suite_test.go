@@ -0,0 +1,14 @@
+package kubernetes
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestKubernetes(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "Kubernetes Suite")
+}
@@ -0,0 +1,151 @@
+package schedule
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kristofferahl/aeto/internal/pkg/util"
+)
+
+var (
+	weekdays      = []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}
+	windowPattern = regexp.MustCompile(`^([a-zA-Z]{3})-([a-zA-Z]{3}) (\d\d):(\d\d)-(\d\d):(\d\d) (?P<tz>[a-zA-Z/_]+)$`)
+)
+
+// Window is a recurring weekly time window, used by maintenance windows and SavingsPolicy schedules, e.g. "MON-FRI 22:00-23:59 Europe/Stockholm"
+type Window struct {
+	Pattern  string
+	days     map[time.Weekday]bool
+	from     int
+	to       int
+	location *time.Location
+}
+
+// ParseWindow parses a weekday and time range pattern with a time zone
+func ParseWindow(pattern string) (Window, error) {
+	match := windowPattern.FindStringSubmatch(pattern)
+	if len(match) != 8 {
+		return Window{}, fmt.Errorf("invalid time range %s, expected a pattern like MON-FRI 22:00-23:59 Europe/Stockholm", pattern)
+	}
+
+	fromDay := util.IndexOfString(strings.ToUpper(match[1]), weekdays)
+	toDay := util.IndexOfString(strings.ToUpper(match[2]), weekdays)
+	if fromDay == -1 || toDay == -1 {
+		return Window{}, fmt.Errorf("invalid weekday range %s-%s in time range %s", match[1], match[2], pattern)
+	}
+
+	from, err := minuteOfDay(match[3], match[4])
+	if err != nil {
+		return Window{}, fmt.Errorf("invalid time range %s, %w", pattern, err)
+	}
+	to, err := minuteOfDay(match[5], match[6])
+	if err != nil {
+		return Window{}, fmt.Errorf("invalid time range %s, %w", pattern, err)
+	}
+	if to < from {
+		return Window{}, fmt.Errorf("invalid time range %s, the end time must not be before the start time", pattern)
+	}
+
+	location, err := time.LoadLocation(match[7])
+	if err != nil {
+		return Window{}, fmt.Errorf("invalid time range %s, %w", pattern, err)
+	}
+
+	days := make(map[time.Weekday]bool)
+	for i := fromDay; ; i = (i + 1) % 7 {
+		days[time.Weekday(i)] = true
+		if i == toDay {
+			break
+		}
+	}
+
+	return Window{
+		Pattern:  pattern,
+		days:     days,
+		from:     from,
+		to:       to,
+		location: location,
+	}, nil
+}
+
+// Contains returns true when the time is inside the window, the end time of the window is inclusive
+func (w Window) Contains(t time.Time) bool {
+	local := t.In(w.location)
+	minute := local.Hour()*60 + local.Minute()
+	return w.days[local.Weekday()] && minute >= w.from && minute <= w.to
+}
+
+// Next returns the start and end of the window containing the time or, when outside the window, of the next window
+func (w Window) Next(t time.Time) (start time.Time, end time.Time) {
+	local := t.In(w.location)
+	for i := 0; i <= 7; i++ {
+		day := local.AddDate(0, 0, i)
+		if !w.days[day.Weekday()] {
+			continue
+		}
+		start = time.Date(day.Year(), day.Month(), day.Day(), w.from/60, w.from%60, 0, 0, w.location)
+		end = time.Date(day.Year(), day.Month(), day.Day(), w.to/60, w.to%60, 0, 0, w.location).Add(time.Minute)
+		if t.Before(end) {
+			return start, end
+		}
+	}
+	return time.Time{}, time.Time{}
+}
+
+// Windows is a list of recurring weekly time windows
+type Windows []Window
+
+// ParseWindows parses a list of weekday and time range patterns
+func ParseWindows(patterns []string) (Windows, error) {
+	windows := make(Windows, 0)
+	for _, pattern := range patterns {
+		w, err := ParseWindow(pattern)
+		if err != nil {
+			return nil, err
+		}
+		windows = append(windows, w)
+	}
+	return windows, nil
+}
+
+// Contains returns true when the time is inside any of the windows
+func (ws Windows) Contains(t time.Time) bool {
+	for _, w := range ws {
+		if w.Contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// Next returns the start of the earliest window containing the time or starting after it
+func (ws Windows) Next(t time.Time) (next time.Time) {
+	for _, w := range ws {
+		start, _ := w.Next(t)
+		if start.IsZero() {
+			continue
+		}
+		if next.IsZero() || start.Before(next) {
+			next = start
+		}
+	}
+	return next
+}
+
+func minuteOfDay(hour string, minute string) (int, error) {
+	h, err := strconv.Atoi(hour)
+	if err != nil {
+		return 0, err
+	}
+	m, err := strconv.Atoi(minute)
+	if err != nil {
+		return 0, err
+	}
+	if h > 23 || m > 59 {
+		return 0, fmt.Errorf("time %s:%s is out of range", hour, minute)
+	}
+	return h*60 + m, nil
+}
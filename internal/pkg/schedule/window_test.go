@@ -0,0 +1,103 @@
+package schedule
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// 2024-01-01 is a monday
+func at(day int, hour int, minute int) time.Time {
+	return time.Date(2024, time.January, day, hour, minute, 0, 0, time.UTC)
+}
+
+var _ = Describe("Window", func() {
+	DescribeTable("ParseWindow",
+		func(pattern string, valid bool) {
+			_, err := ParseWindow(pattern)
+			if valid {
+				Expect(err).ToNot(HaveOccurred())
+			} else {
+				Expect(err).To(HaveOccurred())
+			}
+		},
+		Entry("weekday range", "MON-FRI 22:00-23:59 UTC", true),
+		Entry("lower case weekdays", "mon-fri 22:00-23:59 UTC", true),
+		Entry("range wrapping the week", "SAT-MON 00:00-06:00 UTC", true),
+		Entry("time zone with region", "MON-FRI 22:00-23:59 Europe/Stockholm", true),
+		Entry("single day", "MON 22:00-23:59 UTC", false),
+		Entry("invalid weekday", "MON-FOO 22:00-23:59 UTC", false),
+		Entry("hour out of range", "MON-FRI 22:00-24:00 UTC", false),
+		Entry("minute out of range", "MON-FRI 22:00-23:60 UTC", false),
+		Entry("end before start", "MON-FRI 23:00-22:00 UTC", false),
+		Entry("unknown time zone", "MON-FRI 22:00-23:59 Nowhere/Nothing", false),
+		Entry("missing time zone", "MON-FRI 22:00-23:59", false),
+	)
+
+	DescribeTable("Contains",
+		func(pattern string, t time.Time, expected bool) {
+			w, err := ParseWindow(pattern)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(w.Contains(t)).To(Equal(expected))
+		},
+		Entry("inside the window", "MON-FRI 22:00-23:59 UTC", at(1, 22, 30), true),
+		Entry("at the start", "MON-FRI 22:00-23:59 UTC", at(1, 22, 0), true),
+		Entry("the end is inclusive", "MON-FRI 22:00-23:59 UTC", at(5, 23, 59), true),
+		Entry("before the start", "MON-FRI 22:00-23:59 UTC", at(1, 21, 59), false),
+		Entry("outside the weekdays", "MON-FRI 22:00-23:59 UTC", at(6, 22, 30), false),
+		Entry("range wrapping the week", "SAT-MON 00:00-06:00 UTC", at(7, 3, 0), true),
+		Entry("last day of a range wrapping the week", "SUN-MON 00:00-06:00 UTC", at(8, 6, 0), true),
+		Entry("outside a range wrapping the week", "SUN-MON 00:00-06:00 UTC", at(9, 3, 0), false),
+		Entry("every day of the week", "MON-SUN 00:00-23:59 UTC", at(7, 12, 0), true),
+		Entry("in the time zone of the window", "MON-FRI 22:00-23:59 Europe/Stockholm", at(1, 21, 30), true),
+		Entry("outside the time zone of the window", "MON-FRI 22:00-23:59 Europe/Stockholm", at(1, 23, 0), false),
+		Entry("in the daylight saving time of the window", "MON-FRI 22:00-23:59 Europe/Stockholm", time.Date(2024, time.July, 1, 20, 30, 0, 0, time.UTC), true),
+		Entry("outside the daylight saving time of the window", "MON-FRI 22:00-23:59 Europe/Stockholm", time.Date(2024, time.July, 1, 22, 0, 0, 0, time.UTC), false),
+		Entry("on the weekday of the time zone of the window", "SAT-SUN 00:00-01:00 Europe/Stockholm", at(5, 23, 30), true),
+	)
+
+	DescribeTable("Next",
+		func(pattern string, t time.Time, start time.Time, end time.Time) {
+			w, err := ParseWindow(pattern)
+			Expect(err).ToNot(HaveOccurred())
+			s, e := w.Next(t)
+			Expect(s.Equal(start)).To(BeTrue(), "expected start %s, got %s", start, s)
+			Expect(e.Equal(end)).To(BeTrue(), "expected end %s, got %s", end, e)
+		},
+		Entry("later the same day", "MON-FRI 22:00-23:59 UTC", at(1, 12, 0), at(1, 22, 0), at(2, 0, 0)),
+		Entry("inside the window", "MON-FRI 22:00-23:59 UTC", at(1, 22, 30), at(1, 22, 0), at(2, 0, 0)),
+		Entry("after the window", "MON-FRI 22:00-23:59 UTC", at(2, 0, 0), at(2, 22, 0), at(3, 0, 0)),
+		Entry("next week", "MON-FRI 22:00-23:59 UTC", at(6, 12, 0), at(8, 22, 0), at(9, 0, 0)),
+		Entry("single day a week from now", "MON-MON 01:00-02:00 UTC", at(1, 3, 0), at(8, 1, 0), at(8, 2, 1)),
+	)
+})
+
+var _ = Describe("Windows", func() {
+	It("should fail when any pattern is invalid", func() {
+		_, err := ParseWindows([]string{"MON-FRI 22:00-23:59 UTC", "invalid"})
+		Expect(err).To(HaveOccurred())
+	})
+
+	DescribeTable("Contains",
+		func(patterns []string, t time.Time, expected bool) {
+			ws, err := ParseWindows(patterns)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ws.Contains(t)).To(Equal(expected))
+		},
+		Entry("no windows", []string{}, at(1, 12, 0), false),
+		Entry("inside any window", []string{"MON-FRI 22:00-23:59 UTC", "SAT-SUN 10:00-12:00 UTC"}, at(6, 11, 0), true),
+		Entry("outside all windows", []string{"MON-FRI 22:00-23:59 UTC", "SAT-SUN 10:00-12:00 UTC"}, at(6, 13, 0), false),
+	)
+
+	DescribeTable("Next",
+		func(patterns []string, t time.Time, expected time.Time) {
+			ws, err := ParseWindows(patterns)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ws.Next(t).Equal(expected)).To(BeTrue())
+		},
+		Entry("no windows", []string{}, at(1, 12, 0), time.Time{}),
+		Entry("earliest window", []string{"MON-FRI 22:00-23:59 UTC", "MON-FRI 13:00-14:00 UTC"}, at(1, 12, 0), at(1, 13, 0)),
+		Entry("start of the current window", []string{"MON-FRI 22:00-23:59 UTC", "MON-FRI 13:00-14:00 UTC"}, at(1, 13, 30), at(1, 13, 0)),
+	)
+})
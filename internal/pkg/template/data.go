@@ -16,6 +16,7 @@ type Data struct {
 	Labels       map[string]string
 	Annotations  map[string]string
 	Parameters   []*corev1alpha1.Parameter
+	Params       map[string]interface{}
 	Utils        UtilityFunctions
 }
 
@@ -52,6 +53,36 @@ func (d Data) Bool(name string) (bool, error) {
 	return strconv.ParseBool(v)
 }
 
+// List returns the value of a named list parameter
+func (d Data) List(name string) ([]interface{}, error) {
+	v, err := d.typedValue(name, corev1alpha1.ParameterTypeList)
+	if err != nil {
+		return nil, err
+	}
+	return v.([]interface{}), nil
+}
+
+// Object returns the value of a named object parameter
+func (d Data) Object(name string) (map[string]interface{}, error) {
+	v, err := d.typedValue(name, corev1alpha1.ParameterTypeObject)
+	if err != nil {
+		return nil, err
+	}
+	return v.(map[string]interface{}), nil
+}
+
+func (d Data) typedValue(name string, t corev1alpha1.ParameterType) (interface{}, error) {
+	for _, p := range d.Parameters {
+		if p.Name == name {
+			if p.Type != t {
+				return nil, fmt.Errorf("parameter %s is not of type %s", name, t)
+			}
+			return p.TypedValue()
+		}
+	}
+	return nil, fmt.Errorf("parameter %s not found", name)
+}
+
 type UtilityFunctions struct {
 	String
 	Slice
@@ -0,0 +1,206 @@
+package template
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"text/template"
+
+	yaml "github.com/ghodss/yaml"
+)
+
+const (
+	dnsLabelMaxLength     = 63
+	dnsSubdomainMaxLength = 253
+)
+
+var (
+	dnsLabelInvalidChars     = regexp.MustCompile(`[^a-z0-9-]+`)
+	dnsSubdomainInvalidChars = regexp.MustCompile(`[^a-z0-9.-]+`)
+	dnsRepeatedDashes        = regexp.MustCompile(`-{2,}`)
+)
+
+// FuncMap returns the functions available in resource templates. Arguments follow the conventions of sprig, the value
+// being operated on is the last argument so that functions can be used in pipelines, e.g. {{ .Name | trunc 10 | upper }}.
+//
+// All functions are deterministic, the same input always produces the same output, which keeps the resource generation
+// sum stable between reconciles. Functions returning the current time, random values or reading the environment are
+// intentionally not provided.
+//
+//	Strings:   lower, upper, trim, trimPrefix, trimSuffix, replace, trunc, quote, contains, hasPrefix, hasSuffix, split, join
+//	Defaults:  default, empty
+//	Encoding:  sha256sum, b64enc, b64dec, toJson, toYaml, indent, nindent
+//	Regex:     regexMatch, regexReplace
+//	Lists:     list, dict
+//	DNS names: dnsLabel, dnsSubdomain
+func FuncMap() template.FuncMap {
+	return template.FuncMap{
+		"lower":      strings.ToLower,
+		"upper":      strings.ToUpper,
+		"trim":       strings.TrimSpace,
+		"trimPrefix": func(prefix string, s string) string { return strings.TrimPrefix(s, prefix) },
+		"trimSuffix": func(suffix string, s string) string { return strings.TrimSuffix(s, suffix) },
+		"replace":    func(old string, new string, s string) string { return strings.ReplaceAll(s, old, new) },
+		"trunc":      trunc,
+		"quote":      func(s string) string { return fmt.Sprintf("%q", s) },
+		"contains":   func(substr string, s string) bool { return strings.Contains(s, substr) },
+		"hasPrefix":  func(prefix string, s string) bool { return strings.HasPrefix(s, prefix) },
+		"hasSuffix":  func(suffix string, s string) bool { return strings.HasSuffix(s, suffix) },
+		"split":      func(sep string, s string) []string { return strings.Split(s, sep) },
+		"join":       join,
+
+		"default": defaultValue,
+		"empty":   empty,
+
+		"sha256sum": func(s string) string { return fmt.Sprintf("%x", sha256.Sum256([]byte(s))) },
+		"b64enc":    func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) },
+		"b64dec":    b64dec,
+		"toJson":    toJson,
+		"toYaml":    toYaml,
+		"indent":    indent,
+		"nindent":   func(spaces int, s string) string { return "\n" + indent(spaces, s) },
+
+		"regexMatch":   regexMatch,
+		"regexReplace": regexReplace,
+
+		"list": func(values ...interface{}) []interface{} { return values },
+		"dict": dict,
+
+		"dnsLabel":     func(s string) string { return dnsName(s, dnsLabelInvalidChars, dnsLabelMaxLength) },
+		"dnsSubdomain": func(s string) string { return dnsName(s, dnsSubdomainInvalidChars, dnsSubdomainMaxLength) },
+	}
+}
+
+// trunc returns the first n characters of s, or the last n characters when n is negative
+func trunc(n int, s string) string {
+	runes := []rune(s)
+	if n >= 0 {
+		if n > len(runes) {
+			return s
+		}
+		return string(runes[:n])
+	}
+	if -n > len(runes) {
+		return s
+	}
+	return string(runes[len(runes)+n:])
+}
+
+// join joins the string representation of the items of a list using the separator
+func join(sep string, list interface{}) (string, error) {
+	v := reflect.ValueOf(list)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return "", fmt.Errorf("join expects a list, got %T", list)
+	}
+	items := make([]string, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		items = append(items, fmt.Sprintf("%v", v.Index(i).Interface()))
+	}
+	return strings.Join(items, sep), nil
+}
+
+// defaultValue returns the default value when the value is missing or empty
+func defaultValue(d interface{}, given ...interface{}) interface{} {
+	if len(given) == 0 || empty(given[0]) {
+		return d
+	}
+	return given[0]
+}
+
+// empty returns true for nil and zero values, empty strings, lists and maps
+func empty(given interface{}) bool {
+	if given == nil {
+		return true
+	}
+	v := reflect.ValueOf(given)
+	switch v.Kind() {
+	case reflect.Array, reflect.Slice, reflect.Map, reflect.String:
+		return v.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	default:
+		return v.IsZero()
+	}
+}
+
+func b64dec(s string) (string, error) {
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return "", fmt.Errorf("b64dec failed, %v", err)
+	}
+	return string(b), nil
+}
+
+// toJson encodes the value as json, map keys are sorted
+func toJson(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("toJson failed, %v", err)
+	}
+	return string(b), nil
+}
+
+// toYaml encodes the value as yaml without a trailing newline, map keys are sorted
+func toYaml(v interface{}) (string, error) {
+	b, err := yaml.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("toYaml failed, %v", err)
+	}
+	return strings.TrimSuffix(string(b), "\n"), nil
+}
+
+// indent prefixes every line of s with the number of spaces
+func indent(spaces int, s string) string {
+	pad := strings.Repeat(" ", spaces)
+	return pad + strings.ReplaceAll(s, "\n", "\n"+pad)
+}
+
+func regexMatch(pattern string, s string) (bool, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return false, fmt.Errorf("regexMatch failed, %v", err)
+	}
+	return re.MatchString(s), nil
+}
+
+// regexReplace replaces all matches of the pattern, the replacement may refer to capture groups using $1
+func regexReplace(pattern string, replacement string, s string) (string, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", fmt.Errorf("regexReplace failed, %v", err)
+	}
+	return re.ReplaceAllString(s, replacement), nil
+}
+
+// dict creates a map from a list of alternating string keys and values
+func dict(pairs ...interface{}) (map[string]interface{}, error) {
+	if len(pairs)%2 != 0 {
+		return nil, fmt.Errorf("dict expects an even number of arguments, got %d", len(pairs))
+	}
+	d := make(map[string]interface{}, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		key, ok := pairs[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("dict keys must be strings, got %T", pairs[i])
+		}
+		d[key] = pairs[i+1]
+	}
+	return d, nil
+}
+
+// dnsName lowercases s, replaces invalid characters with dashes and truncates it to the max length, making sure it
+// starts and ends with an alphanumeric character
+func dnsName(s string, invalid *regexp.Regexp, max int) string {
+	name := strings.ToLower(s)
+	name = invalid.ReplaceAllString(name, "-")
+	name = dnsRepeatedDashes.ReplaceAllString(name, "-")
+	name = strings.Trim(name, "-.")
+	if len(name) > max {
+		name = strings.TrimRight(name[:max], "-.")
+	}
+	return name
+}
@@ -0,0 +1,84 @@
+package template
+
+import (
+	"bytes"
+	"strings"
+	"text/template"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func execute(text string, data interface{}) (string, error) {
+	t, err := template.New("test").Option("missingkey=error").Funcs(FuncMap()).Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+var _ = Describe("FuncMap", func() {
+	data := map[string]interface{}{
+		"Name":   "My Tenant",
+		"Empty":  "",
+		"Zero":   0,
+		"Items":  []string{"a", "b", "c"},
+		"Labels": map[string]interface{}{"team": "a", "app": "b"},
+	}
+
+	DescribeTable("functions",
+		func(text string, expected string) {
+			Expect(execute(text, data)).To(Equal(expected))
+		},
+		Entry("lower", `{{ .Name | lower }}`, "my tenant"),
+		Entry("upper", `{{ .Name | upper }}`, "MY TENANT"),
+		Entry("trim", `{{ "  a  " | trim }}`, "a"),
+		Entry("trimPrefix", `{{ .Name | trimPrefix "My " }}`, "Tenant"),
+		Entry("trimSuffix", `{{ .Name | trimSuffix " Tenant" }}`, "My"),
+		Entry("replace", `{{ .Name | replace " " "-" }}`, "My-Tenant"),
+		Entry("trunc", `{{ .Name | trunc 2 }}`, "My"),
+		Entry("trunc from the end", `{{ .Name | trunc -6 }}`, "Tenant"),
+		Entry("trunc longer than the string", `{{ .Name | trunc 20 }}`, "My Tenant"),
+		Entry("quote", `{{ .Name | quote }}`, `"My Tenant"`),
+		Entry("contains", `{{ .Name | contains "Ten" }}`, "true"),
+		Entry("hasPrefix", `{{ .Name | hasPrefix "Ten" }}`, "false"),
+		Entry("hasSuffix", `{{ .Name | hasSuffix "ant" }}`, "true"),
+		Entry("split and join", `{{ "a,b" | split "," | join "+" }}`, "a+b"),
+		Entry("join", `{{ .Items | join "," }}`, "a,b,c"),
+		Entry("default of empty string", `{{ .Empty | default "x" }}`, "x"),
+		Entry("default of zero", `{{ .Zero | default 1 }}`, "1"),
+		Entry("default of value", `{{ .Name | default "x" }}`, "My Tenant"),
+		Entry("empty", `{{ empty .Items }} {{ empty .Empty }}`, "false true"),
+		Entry("sha256sum", `{{ "a" | sha256sum }}`, "ca978112ca1bbdcafac231b39a23dc4da786eff8147c4e72b9807785afee48bb"),
+		Entry("b64enc", `{{ "a" | b64enc }}`, "YQ=="),
+		Entry("b64dec", `{{ "YQ==" | b64dec }}`, "a"),
+		Entry("toJson with sorted keys", `{{ .Labels | toJson }}`, `{"app":"b","team":"a"}`),
+		Entry("toYaml with sorted keys", `{{ .Labels | toYaml }}`, "app: b\nteam: a"),
+		Entry("indent", `{{ .Labels | toYaml | indent 2 }}`, "  app: b\n  team: a"),
+		Entry("nindent", `{{ .Labels | toYaml | nindent 2 }}`, "\n  app: b\n  team: a"),
+		Entry("regexMatch", `{{ .Name | regexMatch "^My" }}`, "true"),
+		Entry("regexReplace", `{{ .Name | regexReplace "(\\w+) (\\w+)" "$2-$1" }}`, "Tenant-My"),
+		Entry("list", `{{ list 1 "a" | toJson }}`, `[1,"a"]`),
+		Entry("dict", `{{ dict "a" 1 "b" "c" | toJson }}`, `{"a":1,"b":"c"}`),
+		Entry("dnsLabel", `{{ "--My_Tenant..Name--" | dnsLabel }}`, "my-tenant-name"),
+		Entry("dnsSubdomain", `{{ "My_Tenant.Example.com" | dnsSubdomain }}`, "my-tenant.example.com"),
+		Entry("dnsLabel truncated", `{{ "`+strings.Repeat("a", 62)+`-b" | dnsLabel }}`, strings.Repeat("a", 62)),
+	)
+
+	DescribeTable("errors",
+		func(text string, expectedErr string) {
+			_, err := execute(text, data)
+			Expect(err).To(MatchError(ContainSubstring(expectedErr)))
+		},
+		Entry("join of a string", `{{ .Name | join "," }}`, "join expects a list, got string"),
+		Entry("b64dec of invalid input", `{{ "!" | b64dec }}`, "b64dec failed"),
+		Entry("regexMatch with invalid pattern", `{{ .Name | regexMatch "(" }}`, "regexMatch failed"),
+		Entry("regexReplace with invalid pattern", `{{ .Name | regexReplace "(" "" }}`, "regexReplace failed"),
+		Entry("dict with odd number of arguments", `{{ dict "a" }}`, "dict expects an even number of arguments, got 1"),
+		Entry("dict with non string key", `{{ dict 1 "a" }}`, "dict keys must be strings, got int"),
+	)
+})
@@ -0,0 +1,14 @@
+package template
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestTemplate(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "Template Suite")
+}
@@ -38,11 +38,17 @@ func NewYamlTemplate(template string, format InputFormat) (yamlTemplate, error)
 	}, nil
 }
 
+// Parse parses the yaml template without executing it
+func (t yamlTemplate) Parse() error {
+	_, err := t.parse()
+	return err
+}
+
 // Execute parses and executes a yaml template given the specified data
 func (t yamlTemplate) Execute(data Data) (string, error) {
-	tmpl, err := template.New("resource").Parse(t.yaml)
+	tmpl, err := t.parse()
 	if err != nil {
-		return "", fmt.Errorf("failed to parse template, %v", err)
+		return "", err
 	}
 
 	var buf bytes.Buffer
@@ -54,3 +60,11 @@ func (t yamlTemplate) Execute(data Data) (string, error) {
 	str := buf.String()
 	return str, nil
 }
+
+func (t yamlTemplate) parse() (*template.Template, error) {
+	tmpl, err := template.New("resource").Funcs(FuncMap()).Parse(t.yaml)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template, %v", err)
+	}
+	return tmpl, nil
+}
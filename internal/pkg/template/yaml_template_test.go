@@ -0,0 +1,32 @@
+package template
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("YamlTemplate", func() {
+	DescribeTable("Parse",
+		func(text string, format InputFormat, expectedErr string) {
+			tmpl, err := NewYamlTemplate(text, format)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = tmpl.Parse()
+			if expectedErr == "" {
+				Expect(err).NotTo(HaveOccurred())
+			} else {
+				Expect(err).To(MatchError(expectedErr))
+			}
+		},
+		Entry("valid yaml", "name: {{ .Name }}", InputFormatYaml, ""),
+		Entry("valid json", `{"name": "{{ .Name }}"}`, InputFormatJson, ""),
+		Entry("unclosed action", "name: {{ .Name", InputFormatYaml, "failed to parse template, template: resource:1: unclosed action"),
+		Entry("unexpected end", `{"name": "{{ end }}"}`, InputFormatJson, "failed to parse template, template: resource:1: unexpected {{end}}"),
+	)
+
+	It("should not execute the template when parsing", func() {
+		tmpl, err := NewYamlTemplate("name: {{ .Missing.Field }}", InputFormatYaml)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tmpl.Parse()).To(Succeed())
+	})
+})
@@ -3,10 +3,15 @@ package tenant
 import (
 	"fmt"
 	"reflect"
+	"sort"
+	"time"
 
 	"github.com/kristofferahl/aeto/apis/core/v1alpha1"
 	"github.com/kristofferahl/aeto/internal/pkg/config"
 	"github.com/kristofferahl/aeto/internal/pkg/eventsource"
+	"github.com/kristofferahl/aeto/internal/pkg/schedule"
+
+	"k8s.io/apimachinery/pkg/types"
 )
 
 type TenantAggregate struct {
@@ -21,15 +26,22 @@ type State struct {
 
 	BlueprintName      string
 	BlueprintNamespace string
+	BlueprintAddOns    []string
 
 	Labels      map[string]string
 	Annotations map[string]string
+	DriftPolicy string
+	Parameters  []v1alpha1.ParameterValue
+
+	TenantLabels      map[string]string
+	TenantAnnotations map[string]string
 
 	TenantPrefixedName      string
 	TenantPrefixedNamespace string
 
-	ResourceGenerationFailed bool
-	ResourceGenerationSum    string
+	ResourceGenerationFailed    bool
+	ResourceGenerationSum       string
+	ResourceGenerationRevisions []string
 
 	ResourceSetVersion   int
 	ResourceSetName      string
@@ -38,6 +50,29 @@ type State struct {
 
 	ResourceSetActive map[string]bool
 
+	ResourceSetPendingApproval string
+	ResourceSetApprovals       map[string]string
+	ResourceSetDeferred        string
+	ResourceSetNextWindow      string
+
+	ResourceGroupsDeferred map[string][]string
+	ResourceGroupsReleased map[string]bool
+
+	Outputs          OutputList
+	OutputsConfigMap string
+
+	GeneratedValuesName      string
+	GeneratedValuesNamespace string
+
+	Hooks map[string]HookState
+
+	Adopted         map[string]bool
+	PendingAdoption map[string][]string
+
+	ExpiresAt string
+	Expiring  bool
+	Expired   bool
+
 	Deleted bool
 }
 
@@ -45,7 +80,13 @@ func NewTenant(id string) *TenantAggregate {
 	a := &TenantAggregate{
 		root: eventsource.AggregateRoot{},
 		state: State{
-			ResourceSetActive: make(map[string]bool),
+			ResourceSetActive:      make(map[string]bool),
+			ResourceSetApprovals:   make(map[string]string),
+			ResourceGroupsDeferred: make(map[string][]string),
+			ResourceGroupsReleased: make(map[string]bool),
+			Hooks:                  make(map[string]HookState),
+			Adopted:                make(map[string]bool),
+			PendingAdoption:        make(map[string][]string),
 		},
 	}
 	a.root.
@@ -72,8 +113,9 @@ func (a *TenantAggregate) SetFullName(name string) {
 }
 
 func (a *TenantAggregate) SetBlueprint(tenant v1alpha1.Tenant, blueprint v1alpha1.Blueprint) {
-	if a.state.BlueprintName != blueprint.Name || a.state.BlueprintNamespace != blueprint.Namespace {
-		a.root.Apply(&BlueprintSet{Name: blueprint.Name, Namespace: blueprint.Namespace})
+	addOnsChanged := (len(a.state.BlueprintAddOns) > 0 || len(tenant.Spec.AddOns) > 0) && !reflect.DeepEqual(a.state.BlueprintAddOns, tenant.Spec.AddOns)
+	if a.state.BlueprintName != blueprint.Name || a.state.BlueprintNamespace != blueprint.Namespace || addOnsChanged {
+		a.root.Apply(&BlueprintSet{Name: blueprint.Name, Namespace: blueprint.Namespace, AddOns: tenant.Spec.AddOns})
 	}
 
 	name := blueprint.Spec.ResourceNamePrefix + a.state.TenantName
@@ -92,24 +134,66 @@ func (a *TenantAggregate) SetBlueprint(tenant v1alpha1.Tenant, blueprint v1alpha
 	if !reflect.DeepEqual(a.state.Annotations, commonAnnotations) {
 		a.root.Apply(&AnnotationsChanged{Annotations: commonAnnotations})
 	}
+
+	tenantLabels := make(map[string]string)
+	for k, v := range tenant.Labels {
+		tenantLabels[k] = v
+	}
+	tenantAnnotations := make(map[string]string)
+	for k, v := range tenant.Annotations {
+		if !v1alpha1.IsControlAnnotation(k) {
+			tenantAnnotations[k] = v
+		}
+	}
+	if (len(a.state.TenantLabels) > 0 || len(tenantLabels) > 0) && !reflect.DeepEqual(a.state.TenantLabels, tenantLabels) ||
+		(len(a.state.TenantAnnotations) > 0 || len(tenantAnnotations) > 0) && !reflect.DeepEqual(a.state.TenantAnnotations, tenantAnnotations) {
+		a.root.Apply(&TenantMetadataChanged{Labels: tenantLabels, Annotations: tenantAnnotations})
+	}
+
+	if (len(a.state.Parameters) > 0 || len(tenant.Spec.Parameters) > 0) && !reflect.DeepEqual(a.state.Parameters, tenant.Spec.Parameters) {
+		a.root.Apply(&ParametersChanged{Parameters: tenant.Spec.Parameters})
+	}
+
+	if a.state.DriftPolicy != string(blueprint.Spec.DriftPolicy) {
+		a.root.Apply(&DriftPolicyChanged{Policy: string(blueprint.Spec.DriftPolicy)})
+	}
 }
 
-func (a *TenantAggregate) GenerateResources(g ResourceGenerator, t v1alpha1.Tenant, b v1alpha1.Blueprint) error {
+func (a *TenantAggregate) GenerateResources(g ResourceGenerator, i ResourceInspector, t v1alpha1.Tenant, b v1alpha1.Blueprint, now time.Time) error {
 	res, err := g.Generate(a.state, b)
+	if err == nil {
+		// adoption is only decided on a complete result, a partial result would dismiss pending adoptions of failing
+		// resource groups
+		adopted, aerr := a.adoptResources(i, t, res.ResourceGroups.Resources())
+		if aerr != nil {
+			return aerr
+		}
+		if adopted {
+			// Adopted resources are generated with a retain policy
+			res, err = g.Generate(a.state, b)
+		}
+	}
+	a.deferResourceGroups(res)
+
 	resourcesChanged := a.state.ResourceGenerationSum != res.Sum
+	revisionsChanged := !reflect.DeepEqual(a.state.ResourceGenerationRevisions, res.Revisions)
 	if err != nil {
-		if !a.state.ResourceGenerationFailed || resourcesChanged {
-			a.root.Apply(&ResourceGenererationFailed{Sum: res.Sum})
+		if !a.state.ResourceGenerationFailed || resourcesChanged || revisionsChanged {
+			a.root.Apply(&ResourceGenererationFailed{Sum: res.Sum, Revisions: res.Revisions})
 		}
 		if len(res.ResourceGroups) == 0 {
 			return err
 		}
 	} else {
-		if a.state.ResourceGenerationFailed || resourcesChanged {
-			a.root.Apply(&ResourceGenererationSuccessful{Sum: res.Sum})
+		if a.state.ResourceGenerationFailed || resourcesChanged || revisionsChanged {
+			a.root.Apply(&ResourceGenererationSuccessful{Sum: res.Sum, Revisions: res.Revisions})
 		}
 	}
 
+	if res.GeneratedValues.Name != "" && (a.state.GeneratedValuesName != res.GeneratedValues.Name || a.state.GeneratedValuesNamespace != res.GeneratedValues.Namespace) {
+		a.root.Apply(&GeneratedValuesStored{Name: res.GeneratedValues.Name, Namespace: res.GeneratedValues.Namespace})
+	}
+
 	if resourcesChanged {
 		a.root.Apply(&ResourceSetVersionChanged{Version: a.state.ResourceSetVersion + 1})
 		a.root.Apply(&ResourceSetCreated{Name: fmt.Sprintf("rs-%s-%06d", a.state.TenantName, a.state.ResourceSetVersion), Namespace: config.Operator.Namespace})
@@ -119,6 +203,9 @@ func (a *TenantAggregate) GenerateResources(g ResourceGenerator, t v1alpha1.Tena
 		rg := rg
 		for _, r := range rg.Resources {
 			r := r
+			if _, pending := a.state.PendingAdoption[r.Id]; pending {
+				continue
+			}
 			_, existing := a.state.Resources.Find(r.Id)
 			if existing != nil {
 				if existing.Sum != r.Sum || existing.Order != r.Order {
@@ -126,7 +213,8 @@ func (a *TenantAggregate) GenerateResources(g ResourceGenerator, t v1alpha1.Tena
 						Resource: r,
 					})
 				}
-			} else {
+			} else if err == nil {
+				// new resources are added once generation succeeds, they are checked for adoption first
 				a.root.Apply(&ResourceAdded{
 					Resource: r,
 				})
@@ -143,6 +231,33 @@ func (a *TenantAggregate) GenerateResources(g ResourceGenerator, t v1alpha1.Tena
 		}
 	}
 
+	outputs := res.ResourceGroups.Outputs()
+	outputsChanged := (len(a.state.Outputs) > 0 || len(outputs) > 0) && !reflect.DeepEqual(a.state.Outputs, outputs)
+	if outputsChanged || a.state.OutputsConfigMap != b.Spec.OutputsConfigMap {
+		a.root.Apply(&OutputsChanged{Outputs: outputs, ConfigMap: b.Spec.OutputsConfigMap})
+	}
+
+	if !a.state.ResourceSetActive[a.state.ResourceSetName] && t.ApprovalPolicy(b) == v1alpha1.ApprovalPolicyManual && a.state.ResourceSetApprovals[a.state.ResourceSetName] == "" {
+		// The currently active resource set remains active until the new resource set is approved
+		if a.state.ResourceSetPendingApproval != a.state.ResourceSetName {
+			a.root.Apply(&ResourceSetApprovalRequested{Name: a.state.ResourceSetName})
+		}
+		return nil
+	}
+
+	windows, err := schedule.ParseWindows(t.MaintenanceWindows(b))
+	if err != nil {
+		return err
+	}
+	if len(windows) > 0 && !a.state.ResourceSetActive[a.state.ResourceSetName] && !windows.Contains(now) {
+		// The currently active resource set remains active until the next maintenance window
+		next := windows.Next(now).UTC().Format(time.RFC3339)
+		if a.state.ResourceSetDeferred != a.state.ResourceSetName || a.state.ResourceSetNextWindow != next {
+			a.root.Apply(&ResourceSetActivationDeferred{Name: a.state.ResourceSetName, NextWindow: next})
+		}
+		return nil
+	}
+
 	for rsn, active := range a.state.ResourceSetActive {
 		if active && rsn != a.state.ResourceSetName {
 			a.root.Apply(&ResourceSetDeactivated{Name: rsn})
@@ -156,12 +271,261 @@ func (a *TenantAggregate) GenerateResources(g ResourceGenerator, t v1alpha1.Tena
 	return nil
 }
 
+// deferResourceGroups records the resource groups waiting for their dependencies and releases resource groups no longer waiting
+func (a *TenantAggregate) deferResourceGroups(res ResourceGenerationResult) {
+	deferred := make(map[string]bool)
+	for _, d := range res.Deferred {
+		deferred[d.Name] = true
+		if !reflect.DeepEqual(a.state.ResourceGroupsDeferred[d.Name], d.WaitingFor) {
+			a.root.Apply(&ResourceGroupDeferred{Name: d.Name, WaitingFor: d.WaitingFor})
+		}
+	}
+
+	for _, name := range res.Released {
+		if !a.state.ResourceGroupsReleased[name] {
+			a.root.Apply(&ResourceGroupReleased{Name: name})
+		}
+	}
+
+	// Resource groups removed from the blueprint, or no longer depending on other resource groups, are released
+	stale := make([]string, 0)
+	for name := range a.state.ResourceGroupsDeferred {
+		if !deferred[name] {
+			stale = append(stale, name)
+		}
+	}
+	sort.Strings(stale)
+	for _, name := range stale {
+		a.root.Apply(&ResourceGroupReleased{Name: name})
+	}
+}
+
+// DeferredResourceGroups returns the resource groups waiting for the resource groups they depend on to become ready
+func (a *TenantAggregate) DeferredResourceGroups() []string {
+	names := make([]string, 0)
+	for name := range a.state.ResourceGroupsDeferred {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// PendingApproval returns the namespaced name of the resource set pending approval and true when there is one
+func (a *TenantAggregate) PendingApproval() (types.NamespacedName, bool) {
+	if a.state.ResourceSetPendingApproval == "" {
+		return types.NamespacedName{}, false
+	}
+	return types.NamespacedName{
+		Namespace: a.state.ResourceSetNamespace,
+		Name:      a.state.ResourceSetPendingApproval,
+	}, true
+}
+
+// ActivationDeferred returns the start of the next maintenance window and true when the activation of a resource set is deferred
+func (a *TenantAggregate) ActivationDeferred() (time.Time, bool) {
+	if a.state.ResourceSetDeferred == "" {
+		return time.Time{}, false
+	}
+	next, err := time.Parse(time.RFC3339, a.state.ResourceSetNextWindow)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return next, true
+}
+
+// ApproveResourceSet records the approval of the resource set pending approval
+func (a *TenantAggregate) ApproveResourceSet(name string, approvedBy string) {
+	if name != "" && name == a.state.ResourceSetPendingApproval && a.state.ResourceSetApprovals[name] == "" {
+		a.root.Apply(&ResourceSetApproved{Name: name, ApprovedBy: approvedBy})
+	}
+}
+
+// adoptResources detects pre-existing resources matching new resources and adopts them when allowed by the tenant
+func (a *TenantAggregate) adoptResources(i ResourceInspector, t v1alpha1.Tenant, resources ResourceList) (adopted bool, err error) {
+	for _, r := range resources {
+		if _, existing := a.state.Resources.Find(r.Id); existing != nil || a.state.Adopted[r.Id] {
+			continue
+		}
+
+		er, err := i.Existing(r)
+		if err != nil {
+			return adopted, err
+		}
+
+		if er == nil {
+			if _, pending := a.state.PendingAdoption[r.Id]; pending {
+				a.root.Apply(&ResourceAdoptionDismissed{ResourceId: r.Id})
+			}
+			continue
+		}
+
+		if t.Spec.AdoptionPolicy == v1alpha1.AdoptionPolicyAdopt {
+			a.root.Apply(&ResourceAdopted{ResourceId: r.Id, Kind: er.Kind, Namespace: er.Namespace, Name: er.Name, Diff: er.Diff})
+			adopted = true
+			continue
+		}
+
+		if diff, pending := a.state.PendingAdoption[r.Id]; !pending || !reflect.DeepEqual(diff, er.Diff) {
+			a.root.Apply(&ResourceAdoptionRequired{ResourceId: r.Id, Kind: er.Kind, Namespace: er.Namespace, Name: er.Name, Diff: er.Diff})
+		}
+	}
+
+	for id := range a.state.PendingAdoption {
+		if _, found := resources.Find(id); found == nil {
+			a.root.Apply(&ResourceAdoptionDismissed{ResourceId: id})
+		}
+	}
+
+	return adopted, nil
+}
+
+// RotateGeneratedValues records the rotation of generated values
+func (a *TenantAggregate) RotateGeneratedValues(names []string) {
+	if len(names) > 0 {
+		a.root.Apply(&GeneratedValuesRotated{Names: names})
+	}
+}
+
+// Preview generates resources from the blueprint and compares them to the current resources of the tenant without applying any events
+func (a *TenantAggregate) Preview(g ResourceGenerator, b v1alpha1.Blueprint) (ResourcePreview, error) {
+	preview := ResourcePreview{}
+
+	res, err := g.Generate(a.state, b)
+	if err != nil {
+		return preview, err
+	}
+
+	generated := res.ResourceGroups.Resources()
+	for _, r := range generated {
+		_, existing := a.state.Resources.Find(r.Id)
+		if existing == nil {
+			change, err := newResourceChange(r, nil)
+			if err != nil {
+				return preview, err
+			}
+			preview.Added = append(preview.Added, change)
+			continue
+		}
+		if existing.Sum != r.Sum {
+			change, err := newResourceChange(r, existing)
+			if err != nil {
+				return preview, err
+			}
+			preview.Updated = append(preview.Updated, change)
+		}
+	}
+
+	for _, sr := range a.state.Resources {
+		sr := sr
+		if _, found := generated.Find(sr.Id); found == nil {
+			change, err := newResourceChange(sr, &sr)
+			if err != nil {
+				return preview, err
+			}
+			preview.Removed = append(preview.Removed, change)
+		}
+	}
+
+	return preview, nil
+}
+
+// RunHooks runs the blueprint hooks of a phase one at a time and returns true when all hooks have completed
+func (a *TenantAggregate) RunHooks(r HookRunner, b v1alpha1.Blueprint, phase v1alpha1.HookPhase) (completed bool, err error) {
+	if phase == v1alpha1.HookPhasePreProvision && a.state.ResourceSetVersion > 0 {
+		// The tenant has already been provisioned
+		return true, nil
+	}
+
+	if phase == v1alpha1.HookPhasePreDelete && a.state.Deleted {
+		// The tenant has already been marked as deleted
+		return true, nil
+	}
+
+	for _, hook := range b.PhaseHooks(phase) {
+		key := hookKey(string(phase), hook.Name)
+		hs, started := a.state.Hooks[key]
+
+		if !started {
+			job, err := r.Start(a.state, b, hook)
+			if err != nil {
+				return false, err
+			}
+			a.root.Apply(&HookStarted{Name: hook.Name, Phase: string(phase), JobName: job.Name, JobNamespace: job.Namespace})
+			return false, nil
+		}
+
+		if !hs.Completed {
+			status, reason, err := r.Status(hs.Job())
+			if err != nil {
+				return false, err
+			}
+			switch status {
+			case HookJobRunning:
+				return false, nil
+			case HookJobSucceeded:
+				a.root.Apply(&HookSucceeded{Name: hook.Name, Phase: string(phase)})
+			case HookJobFailed:
+				a.root.Apply(&HookFailed{Name: hook.Name, Phase: string(phase), Reason: reason})
+			}
+			hs = a.state.Hooks[key]
+		}
+
+		if hs.Failed && hook.FailurePolicy != v1alpha1.HookFailurePolicyIgnore {
+			return false, &HookFailedError{Name: hook.Name, Phase: string(phase), Reason: hs.Reason}
+		}
+	}
+
+	return true, nil
+}
+
+// SetExpiry records the expiry of the tenant and returns true when the tenant has expired
+func (a *TenantAggregate) SetExpiry(expiresAt *time.Time, now time.Time, warning time.Duration) (expired bool) {
+	value := ""
+	if expiresAt != nil {
+		value = expiresAt.Format(time.RFC3339)
+	}
+
+	if a.state.ExpiresAt != value {
+		a.root.Apply(&TenantExpirySet{ExpiresAt: value})
+	}
+
+	if expiresAt == nil {
+		return false
+	}
+
+	if !now.Before(*expiresAt) {
+		if !a.state.Expired {
+			a.root.Apply(&TenantExpired{ExpiresAt: value})
+		}
+		return true
+	}
+
+	if !now.Before(expiresAt.Add(-warning)) && !a.state.Expiring {
+		a.root.Apply(&TenantExpiring{ExpiresAt: value})
+	}
+
+	return false
+}
+
 func (a *TenantAggregate) Delete() {
 	if !a.state.Deleted {
 		a.root.Apply(&TenantDeleted{})
 	}
 }
 
+// Blueprint returns the namespaced name of the blueprint currently in use
+func (a *TenantAggregate) Blueprint() types.NamespacedName {
+	return types.NamespacedName{
+		Namespace: a.state.BlueprintNamespace,
+		Name:      a.state.BlueprintName,
+	}
+}
+
+// AddOns returns the names of the add-on blueprints currently in use
+func (a *TenantAggregate) AddOns() []string {
+	return a.state.BlueprintAddOns
+}
+
 func (a *TenantAggregate) Id() string {
 	return a.root.Id()
 }
@@ -191,19 +555,29 @@ func (s *State) On(e eventsource.Event) {
 	case *BlueprintSet:
 		s.BlueprintName = event.Name
 		s.BlueprintNamespace = event.Namespace
+		s.BlueprintAddOns = event.AddOns
 	case *LabelsChanged:
 		s.Labels = event.Labels
 	case *AnnotationsChanged:
 		s.Annotations = event.Annotations
+	case *TenantMetadataChanged:
+		s.TenantLabels = event.Labels
+		s.TenantAnnotations = event.Annotations
+	case *DriftPolicyChanged:
+		s.DriftPolicy = event.Policy
+	case *ParametersChanged:
+		s.Parameters = event.Parameters
 	case *ResourceNamespaceNameChanged:
 		s.TenantPrefixedName = event.Name
 		s.TenantPrefixedNamespace = event.Namespace
 	case *ResourceGenererationFailed:
 		s.ResourceGenerationFailed = true
 		s.ResourceGenerationSum = event.Sum
+		s.ResourceGenerationRevisions = event.Revisions
 	case *ResourceGenererationSuccessful:
 		s.ResourceGenerationFailed = false
 		s.ResourceGenerationSum = event.Sum
+		s.ResourceGenerationRevisions = event.Revisions
 	case *ResourceSetVersionChanged:
 		s.ResourceSetVersion = event.Version
 	case *ResourceSetCreated:
@@ -219,8 +593,69 @@ func (s *State) On(e eventsource.Event) {
 		s.Resources = s.Resources.Remove(index)
 	case *ResourceSetActivated:
 		s.ResourceSetActive[event.Name] = true
+		if s.ResourceSetPendingApproval == event.Name {
+			s.ResourceSetPendingApproval = ""
+		}
+		if s.ResourceSetDeferred == event.Name {
+			s.ResourceSetDeferred = ""
+			s.ResourceSetNextWindow = ""
+		}
 	case *ResourceSetDeactivated:
 		s.ResourceSetActive[event.Name] = false
+	case *ResourceSetApprovalRequested:
+		s.ResourceSetPendingApproval = event.Name
+	case *ResourceSetActivationDeferred:
+		s.ResourceSetDeferred = event.Name
+		s.ResourceSetNextWindow = event.NextWindow
+	case *ResourceGroupDeferred:
+		s.ResourceGroupsDeferred[event.Name] = event.WaitingFor
+	case *ResourceGroupReleased:
+		delete(s.ResourceGroupsDeferred, event.Name)
+		s.ResourceGroupsReleased[event.Name] = true
+	case *ResourceSetApproved:
+		s.ResourceSetApprovals[event.Name] = event.ApprovedBy
+		if s.ResourceSetPendingApproval == event.Name {
+			s.ResourceSetPendingApproval = ""
+		}
+	case *OutputsChanged:
+		s.Outputs = event.Outputs
+		s.OutputsConfigMap = event.ConfigMap
+	case *GeneratedValuesStored:
+		s.GeneratedValuesName = event.Name
+		s.GeneratedValuesNamespace = event.Namespace
+	case *HookStarted:
+		s.Hooks[hookKey(event.Phase, event.Name)] = HookState{
+			Name:         event.Name,
+			Phase:        event.Phase,
+			JobName:      event.JobName,
+			JobNamespace: event.JobNamespace,
+		}
+	case *HookSucceeded:
+		hs := s.Hooks[hookKey(event.Phase, event.Name)]
+		hs.Completed = true
+		hs.Failed = false
+		s.Hooks[hookKey(event.Phase, event.Name)] = hs
+	case *HookFailed:
+		hs := s.Hooks[hookKey(event.Phase, event.Name)]
+		hs.Completed = true
+		hs.Failed = true
+		hs.Reason = event.Reason
+		s.Hooks[hookKey(event.Phase, event.Name)] = hs
+	case *ResourceAdoptionRequired:
+		s.PendingAdoption[event.ResourceId] = event.Diff
+	case *ResourceAdopted:
+		delete(s.PendingAdoption, event.ResourceId)
+		s.Adopted[event.ResourceId] = true
+	case *ResourceAdoptionDismissed:
+		delete(s.PendingAdoption, event.ResourceId)
+	case *TenantExpirySet:
+		s.ExpiresAt = event.ExpiresAt
+		s.Expiring = false
+		s.Expired = false
+	case *TenantExpiring:
+		s.Expiring = true
+	case *TenantExpired:
+		s.Expired = true
 	case *TenantDeleted:
 		s.Deleted = true
 	}
@@ -0,0 +1,166 @@
+package tenant
+
+import (
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	corev1alpha1 "github.com/kristofferahl/aeto/apis/core/v1alpha1"
+	"github.com/kristofferahl/aeto/internal/pkg/config"
+	"github.com/kristofferahl/aeto/internal/pkg/eventsource"
+	"github.com/kristofferahl/aeto/internal/pkg/kubernetes"
+	"github.com/kristofferahl/aeto/internal/pkg/reconcile"
+)
+
+var _ = Describe("TenantAggregate", func() {
+	Describe("adoption", func() {
+		var (
+			ctx      reconcile.Context
+			services ResourceGeneratoreServices
+			tenant   corev1alpha1.Tenant
+			a        *TenantAggregate
+		)
+
+		template := func(name string, parameters corev1alpha1.ResourceTemplateParameterList) *corev1alpha1.ResourceTemplate {
+			return &corev1alpha1.ResourceTemplate{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "aeto", Name: name},
+				Spec: corev1alpha1.ResourceTemplateSpec{
+					Rules: corev1alpha1.ResourceTemplateRules{
+						Name:      corev1alpha1.ResourceNameKeep,
+						Namespace: corev1alpha1.ResourceNamespaceKeep,
+					},
+					Parameters: parameters,
+					Raw:        []string{fmt.Sprintf("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: %s\n  namespace: acme\ndata:\n  a: \"2\"\n", name)},
+				},
+			}
+		}
+
+		blueprint := func(failing bool) corev1alpha1.Blueprint {
+			app := corev1alpha1.BlueprintResourceGroup{Name: "app", Template: "app"}
+			if failing {
+				app.Parameters = []corev1alpha1.ParameterValue{
+					{Name: "password", ValueFrom: &corev1alpha1.ValueRef{SecretKeyRef: &corev1alpha1.KeyValueRef{Namespace: "aeto", Name: "missing", Key: "password"}}},
+				}
+			}
+			return corev1alpha1.Blueprint{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "aeto", Name: "default"},
+				Spec: corev1alpha1.BlueprintSpec{
+					Resources: []corev1alpha1.BlueprintResourceGroup{
+						app,
+						{Name: "other", Template: "other"},
+					},
+				},
+			}
+		}
+
+		generate := func(failing bool) []string {
+			g := NewResourceGenerator(ctx, services).DryRun()
+			i := NewResourceInspector(ctx, services)
+			_ = a.GenerateResources(g, i, tenant, blueprint(failing), time.Now())
+
+			events := make([]string, 0)
+			a.root.CommitEvents(a.root.Version()+1, func(e eventsource.Event) {
+				events = append(events, fmt.Sprintf("%T", e))
+			})
+			return events
+		}
+
+		BeforeEach(func() {
+			config.Operator.Namespace = "aeto"
+
+			scheme := runtime.NewScheme()
+			Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+			Expect(corev1alpha1.AddToScheme(scheme)).To(Succeed())
+
+			mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{corev1.SchemeGroupVersion})
+			mapper.Add(corev1.SchemeGroupVersion.WithKind("ConfigMap"), meta.RESTScopeNamespace)
+
+			existing := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "acme", Name: "app"},
+				Data:       map[string]string{"a": "1"},
+			}
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+				template("app", corev1alpha1.ResourceTemplateParameterList{{Name: "password", Type: corev1alpha1.ParameterTypeString}}),
+				template("other", nil),
+			).Build()
+
+			ctx = reconcile.NewContext("test", ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "aeto", Name: "acme"}}, logf.Log)
+			services = ResourceGeneratoreServices{Client: kubernetes.NewClient(c, dynamicfake.NewSimpleDynamicClient(scheme, existing), nil).WithRESTMapper(mapper)}
+			tenant = corev1alpha1.Tenant{ObjectMeta: metav1.ObjectMeta{Namespace: "aeto", Name: "acme"}}
+
+			a = NewTenant("acme")
+			a.Create("acme", "aeto")
+			a.root.CommitEvents(1, func(e eventsource.Event) {})
+		})
+
+		It("should require adoption of pre-existing resources", func() {
+			events := generate(false)
+			Expect(events).To(ContainElement("*tenant.ResourceAdoptionRequired"))
+			Expect(a.state.PendingAdoption).To(HaveLen(1))
+			Expect(a.state.Resources).To(HaveLen(1))
+		})
+
+		It("should keep pending adoptions when generation fails", func() {
+			generate(false)
+			Expect(a.state.PendingAdoption).To(HaveLen(1))
+
+			events := generate(true)
+			Expect(events).To(ContainElement("*tenant.ResourceGenererationFailed"))
+			Expect(events).NotTo(ContainElement("*tenant.ResourceAdoptionDismissed"))
+			Expect(a.state.PendingAdoption).To(HaveLen(1))
+		})
+
+		It("should not add new resources when generation fails", func() {
+			events := generate(true)
+			Expect(events).To(ContainElement("*tenant.ResourceGenererationFailed"))
+			Expect(events).NotTo(ContainElement("*tenant.ResourceAdded"))
+			Expect(a.state.Resources).To(BeEmpty())
+		})
+
+		It("should not find pre-existing resources of kinds that are not served", func() {
+			r := Resource{Id: "widget"}
+			r.Embedded.Raw = []byte(`{"apiVersion":"example.com/v1","kind":"Widget","metadata":{"name":"app","namespace":"acme"}}`)
+
+			existing, err := NewResourceInspector(ctx, services).Existing(r)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(existing).To(BeNil())
+		})
+	})
+
+	Describe("SetBlueprint", func() {
+		It("should not change the resources of the tenant when deletion protection changes", func() {
+			blueprint := corev1alpha1.Blueprint{ObjectMeta: metav1.ObjectMeta{Namespace: "aeto", Name: "default"}}
+			tenant := corev1alpha1.Tenant{ObjectMeta: metav1.ObjectMeta{Namespace: "aeto", Name: "acme", Annotations: map[string]string{"owner": "platform"}}}
+
+			a := NewTenant("acme")
+			a.Create("acme", "aeto")
+			a.SetBlueprint(tenant, blueprint)
+			a.root.CommitEvents(1, func(e eventsource.Event) {})
+
+			tenant.Annotations[corev1alpha1.AnnotationDeletionProtection] = "true"
+			a.SetBlueprint(tenant, blueprint)
+
+			events := make([]string, 0)
+			a.root.CommitEvents(a.root.Version()+1, func(e eventsource.Event) {
+				events = append(events, fmt.Sprintf("%T", e))
+			})
+			Expect(events).To(BeEmpty())
+			Expect(a.state.Annotations).NotTo(HaveKey(corev1alpha1.AnnotationDeletionProtection))
+			Expect(a.state.TenantAnnotations).To(Equal(map[string]string{"owner": "platform"}))
+		})
+	})
+})
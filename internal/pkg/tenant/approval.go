@@ -0,0 +1,41 @@
+package tenant
+
+import (
+	authorizationv1 "k8s.io/api/authorization/v1"
+
+	corev1alpha1 "github.com/kristofferahl/aeto/apis/core/v1alpha1"
+	"github.com/kristofferahl/aeto/internal/pkg/kubernetes"
+	"github.com/kristofferahl/aeto/internal/pkg/reconcile"
+)
+
+// ApproveVerb is the verb on resourcesets a user must be allowed to approve a ResourceSet
+const ApproveVerb = "approve"
+
+// Approver returns the user approving a ResourceSet, using the approved-by annotation, and true when the user is allowed
+// the approve verb on the ResourceSet according to a SubjectAccessReview. The user is empty when the ResourceSet has
+// not been approved.
+func Approver(ctx reconcile.Context, c kubernetes.Client, rs corev1alpha1.ResourceSet) (string, bool, error) {
+	user := rs.Annotations[corev1alpha1.AnnotationApprovedBy]
+	if user == "" {
+		return "", false, nil
+	}
+
+	review := authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User: user,
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Group:     corev1alpha1.GroupVersion.Group,
+				Version:   corev1alpha1.GroupVersion.Version,
+				Resource:  "resourcesets",
+				Namespace: rs.Namespace,
+				Name:      rs.Name,
+				Verb:      ApproveVerb,
+			},
+		},
+	}
+	if err := c.Create(ctx, &review); err != nil {
+		return user, false, err
+	}
+
+	return user, review.Status.Allowed, nil
+}
@@ -0,0 +1,207 @@
+package tenant
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	corev1alpha1 "github.com/kristofferahl/aeto/apis/core/v1alpha1"
+	"github.com/kristofferahl/aeto/internal/pkg/config"
+	"github.com/kristofferahl/aeto/internal/pkg/eventsource"
+	"github.com/kristofferahl/aeto/internal/pkg/kubernetes"
+	"github.com/kristofferahl/aeto/internal/pkg/reconcile"
+)
+
+// reviewingClient answers SubjectAccessReviews, allowing the listed users
+type reviewingClient struct {
+	client.Client
+	allowed []string
+	reviews []authorizationv1.SubjectAccessReview
+}
+
+func (c *reviewingClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if review, ok := obj.(*authorizationv1.SubjectAccessReview); ok {
+		for _, user := range c.allowed {
+			review.Status.Allowed = review.Status.Allowed || user == review.Spec.User
+		}
+		c.reviews = append(c.reviews, *review)
+		return nil
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+var _ = Describe("Approval", func() {
+	var (
+		ctx reconcile.Context
+		c   *reviewingClient
+	)
+
+	BeforeEach(func() {
+		config.Operator.Namespace = "aeto"
+
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(corev1alpha1.AddToScheme(scheme)).To(Succeed())
+
+		template := &corev1alpha1.ResourceTemplate{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "aeto", Name: "app"},
+			Spec: corev1alpha1.ResourceTemplateSpec{
+				Rules: corev1alpha1.ResourceTemplateRules{
+					Name:      corev1alpha1.ResourceNameKeep,
+					Namespace: corev1alpha1.ResourceNamespaceKeep,
+				},
+				Raw: []string{"apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\n  namespace: acme\n"},
+			},
+		}
+		c = &reviewingClient{
+			Client:  fake.NewClientBuilder().WithScheme(scheme).WithObjects(template).Build(),
+			allowed: []string{"jane"},
+		}
+		ctx = reconcile.NewContext("test", ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "aeto", Name: "acme"}}, logf.Log)
+	})
+
+	Describe("Approver", func() {
+		resourceSet := func(approvedBy string) corev1alpha1.ResourceSet {
+			rs := corev1alpha1.ResourceSet{ObjectMeta: metav1.ObjectMeta{Namespace: "aeto", Name: "rs-acme-000001"}}
+			if approvedBy != "" {
+				rs.Annotations = map[string]string{corev1alpha1.AnnotationApprovedBy: approvedBy}
+			}
+			return rs
+		}
+
+		It("should not review ResourceSets that are not approved", func() {
+			user, allowed, err := Approver(ctx, kubernetes.NewClient(c, nil, nil), resourceSet(""))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(user).To(BeEmpty())
+			Expect(allowed).To(BeFalse())
+			Expect(c.reviews).To(BeEmpty())
+		})
+
+		It("should allow users allowed to approve the ResourceSet", func() {
+			user, allowed, err := Approver(ctx, kubernetes.NewClient(c, nil, nil), resourceSet("jane"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(user).To(Equal("jane"))
+			Expect(allowed).To(BeTrue())
+
+			Expect(c.reviews).To(HaveLen(1))
+			Expect(c.reviews[0].Spec.User).To(Equal("jane"))
+			Expect(*c.reviews[0].Spec.ResourceAttributes).To(Equal(authorizationv1.ResourceAttributes{
+				Group:     "core.aeto.net",
+				Version:   "v1alpha1",
+				Resource:  "resourcesets",
+				Namespace: "aeto",
+				Name:      "rs-acme-000001",
+				Verb:      ApproveVerb,
+			}))
+		})
+
+		It("should deny other users", func() {
+			user, allowed, err := Approver(ctx, kubernetes.NewClient(c, nil, nil), resourceSet("john"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(user).To(Equal("john"))
+			Expect(allowed).To(BeFalse())
+		})
+	})
+
+	Describe("ResourceSet approval", func() {
+		var (
+			a         *TenantAggregate
+			tenant    corev1alpha1.Tenant
+			blueprint corev1alpha1.Blueprint
+		)
+
+		events := func() []string {
+			names := make([]string, 0)
+			a.root.CommitEvents(a.root.Version()+1, func(e eventsource.Event) {
+				names = append(names, fmt.Sprintf("%T", e))
+			})
+			return names
+		}
+
+		generate := func() []string {
+			// no kinds are served, resources are never adopted
+			services := ResourceGeneratoreServices{Client: kubernetes.NewClient(c, nil, nil).WithRESTMapper(meta.NewDefaultRESTMapper(nil))}
+			Expect(a.GenerateResources(NewResourceGenerator(ctx, services).DryRun(), NewResourceInspector(ctx, services), tenant, blueprint, time.Now())).To(Succeed())
+			return events()
+		}
+
+		BeforeEach(func() {
+			tenant = corev1alpha1.Tenant{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "aeto", Name: "acme"},
+				Spec:       corev1alpha1.TenantSpec{ApprovalPolicy: corev1alpha1.ApprovalPolicyManual},
+			}
+			blueprint = corev1alpha1.Blueprint{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "aeto", Name: "default"},
+				Spec: corev1alpha1.BlueprintSpec{
+					Resources: []corev1alpha1.BlueprintResourceGroup{{Name: "app", Template: "app"}},
+				},
+			}
+
+			a = NewTenant("acme")
+			a.Create("acme", "aeto")
+			events()
+		})
+
+		It("should request approval of new ResourceSets", func() {
+			Expect(generate()).To(ContainElement("*tenant.ResourceSetApprovalRequested"))
+
+			nn, pending := a.PendingApproval()
+			Expect(pending).To(BeTrue())
+			Expect(nn).To(Equal(types.NamespacedName{Namespace: "aeto", Name: a.state.ResourceSetName}))
+			Expect(a.state.ResourceSetActive[nn.Name]).To(BeFalse())
+
+			Expect(generate()).To(BeEmpty())
+		})
+
+		It("should activate approved ResourceSets", func() {
+			generate()
+			nn, _ := a.PendingApproval()
+
+			a.ApproveResourceSet(nn.Name, "jane")
+			Expect(events()).To(Equal([]string{"*tenant.ResourceSetApproved"}))
+			Expect(a.state.ResourceSetApprovals[nn.Name]).To(Equal("jane"))
+			_, pending := a.PendingApproval()
+			Expect(pending).To(BeFalse())
+
+			Expect(generate()).To(ContainElement("*tenant.ResourceSetActivated"))
+			Expect(a.state.ResourceSetActive[nn.Name]).To(BeTrue())
+		})
+
+		It("should ignore approvals of other ResourceSets", func() {
+			generate()
+			nn, _ := a.PendingApproval()
+
+			a.ApproveResourceSet("rs-acme-999999", "jane")
+			a.ApproveResourceSet("", "jane")
+			Expect(events()).To(BeEmpty())
+			_, pending := a.PendingApproval()
+			Expect(pending).To(BeTrue())
+
+			a.ApproveResourceSet(nn.Name, "jane")
+			a.ApproveResourceSet(nn.Name, "john")
+			Expect(events()).To(Equal([]string{"*tenant.ResourceSetApproved"}))
+			Expect(a.state.ResourceSetApprovals[nn.Name]).To(Equal("jane"))
+		})
+
+		It("should activate ResourceSets without approval when the policy is automatic", func() {
+			tenant.Spec.ApprovalPolicy = corev1alpha1.ApprovalPolicyAutomatic
+			events := generate()
+			Expect(events).NotTo(ContainElement("*tenant.ResourceSetApprovalRequested"))
+			Expect(events).To(ContainElement("*tenant.ResourceSetActivated"))
+		})
+	})
+})
@@ -1,6 +1,7 @@
 package tenant
 
 import (
+	"github.com/kristofferahl/aeto/apis/core/v1alpha1"
 	"github.com/kristofferahl/aeto/internal/pkg/eventsource"
 )
 
@@ -12,6 +13,9 @@ func Events() []eventsource.Event {
 		&BlueprintSet{},
 		&LabelsChanged{},
 		&AnnotationsChanged{},
+		&TenantMetadataChanged{},
+		&DriftPolicyChanged{},
+		&ParametersChanged{},
 		&ResourceNamespaceNameChanged{},
 		&ResourceGenererationFailed{},
 		&ResourceGenererationSuccessful{},
@@ -22,6 +26,23 @@ func Events() []eventsource.Event {
 		&ResourceRemoved{},
 		&ResourceSetActivated{},
 		&ResourceSetDeactivated{},
+		&ResourceSetApprovalRequested{},
+		&ResourceSetApproved{},
+		&ResourceSetActivationDeferred{},
+		&ResourceGroupDeferred{},
+		&ResourceGroupReleased{},
+		&OutputsChanged{},
+		&GeneratedValuesStored{},
+		&GeneratedValuesRotated{},
+		&HookStarted{},
+		&HookSucceeded{},
+		&HookFailed{},
+		&ResourceAdoptionRequired{},
+		&ResourceAdopted{},
+		&ResourceAdoptionDismissed{},
+		&TenantExpirySet{},
+		&TenantExpiring{},
+		&TenantExpired{},
 		&TenantDeleted{},
 	}
 }
@@ -52,8 +73,9 @@ type TenantFullNameSet struct {
 
 type BlueprintSet struct {
 	eventsource.EventModel
-	Name      string `json:"name"`
-	Namespace string `json:"namespace"`
+	Name      string   `json:"name"`
+	Namespace string   `json:"namespace"`
+	AddOns    []string `json:"addOns,omitempty"`
 }
 
 type LabelsChanged struct {
@@ -66,6 +88,26 @@ type AnnotationsChanged struct {
 	Annotations map[string]string `json:"annotations"`
 }
 
+// TenantMetadataChanged represents a change of the labels and annotations of the Tenant, excluding the annotations
+// controlling the operator
+type TenantMetadataChanged struct {
+	eventsource.EventModel
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// DriftPolicyChanged represents a change of the drift policy for the tenant resources
+type DriftPolicyChanged struct {
+	eventsource.EventModel
+	Policy string `json:"policy"`
+}
+
+// ParametersChanged represents a change of the parameter values that applies to all templates of the tenant
+type ParametersChanged struct {
+	eventsource.EventModel
+	Parameters []v1alpha1.ParameterValue `json:"parameters"`
+}
+
 type ResourceNamespaceNameChanged struct {
 	eventsource.EventModel
 	Name      string `json:"name"`
@@ -74,12 +116,14 @@ type ResourceNamespaceNameChanged struct {
 
 type ResourceGenererationFailed struct {
 	eventsource.EventModel
-	Sum string `json:"sum"`
+	Sum       string   `json:"sum"`
+	Revisions []string `json:"revisions,omitempty"`
 }
 
 type ResourceGenererationSuccessful struct {
 	eventsource.EventModel
-	Sum string `json:"sum"`
+	Sum       string   `json:"sum"`
+	Revisions []string `json:"revisions,omitempty"`
 }
 
 type ResourceSetVersionChanged struct {
@@ -118,6 +162,124 @@ type ResourceSetDeactivated struct {
 	Name string `json:"name"`
 }
 
+// ResourceSetApprovalRequested represents a resource set that must be approved before it is activated
+type ResourceSetApprovalRequested struct {
+	eventsource.EventModel
+	Name string `json:"name"`
+}
+
+// ResourceSetActivationDeferred represents a resource set waiting for the next maintenance window to be activated
+type ResourceSetActivationDeferred struct {
+	eventsource.EventModel
+	Name       string `json:"name"`
+	NextWindow string `json:"nextWindow"`
+}
+
+// ResourceGroupDeferred represents a resource group waiting for the resource groups it depends on to become ready
+type ResourceGroupDeferred struct {
+	eventsource.EventModel
+	Name       string   `json:"name"`
+	WaitingFor []string `json:"waitingFor"`
+}
+
+// ResourceGroupReleased represents a resource group no longer waiting for the resource groups it depends on, it is not deferred again
+type ResourceGroupReleased struct {
+	eventsource.EventModel
+	Name string `json:"name"`
+}
+
+// ResourceSetApproved represents the approval of a resource set
+type ResourceSetApproved struct {
+	eventsource.EventModel
+	Name       string `json:"name"`
+	ApprovedBy string `json:"approvedBy"`
+}
+
+// OutputsChanged represents a change of the outputs published by the tenant
+type OutputsChanged struct {
+	eventsource.EventModel
+	Outputs   OutputList `json:"outputs"`
+	ConfigMap string     `json:"configMap,omitempty"`
+}
+
+// GeneratedValuesStored represents the Secret holding the generated values of a tenant
+type GeneratedValuesStored struct {
+	eventsource.EventModel
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
+
+// GeneratedValuesRotated represents generated values being removed to be generated again
+type GeneratedValuesRotated struct {
+	eventsource.EventModel
+	Names []string `json:"names"`
+}
+
+type HookStarted struct {
+	eventsource.EventModel
+	Name         string `json:"name"`
+	Phase        string `json:"phase"`
+	JobName      string `json:"jobName"`
+	JobNamespace string `json:"jobNamespace"`
+}
+
+type HookSucceeded struct {
+	eventsource.EventModel
+	Name  string `json:"name"`
+	Phase string `json:"phase"`
+}
+
+type HookFailed struct {
+	eventsource.EventModel
+	Name   string `json:"name"`
+	Phase  string `json:"phase"`
+	Reason string `json:"reason"`
+}
+
+// ResourceAdoptionRequired represents a pre-existing resource that must be adopted before it can be managed by the tenant
+type ResourceAdoptionRequired struct {
+	eventsource.EventModel
+	ResourceId string   `json:"resourceId"`
+	Kind       string   `json:"kind"`
+	Namespace  string   `json:"namespace"`
+	Name       string   `json:"name"`
+	Diff       []string `json:"diff"`
+}
+
+// ResourceAdopted represents a pre-existing resource taken over by the tenant
+type ResourceAdopted struct {
+	eventsource.EventModel
+	ResourceId string   `json:"resourceId"`
+	Kind       string   `json:"kind"`
+	Namespace  string   `json:"namespace"`
+	Name       string   `json:"name"`
+	Diff       []string `json:"diff"`
+}
+
+// ResourceAdoptionDismissed represents a pre-existing resource that no longer requires adoption
+type ResourceAdoptionDismissed struct {
+	eventsource.EventModel
+	ResourceId string `json:"resourceId"`
+}
+
+// TenantExpirySet represents a change of the tenant expiry, an empty value means the tenant never expires
+type TenantExpirySet struct {
+	eventsource.EventModel
+	ExpiresAt string `json:"expiresAt"`
+}
+
+// TenantExpiring represents a tenant about to expire
+type TenantExpiring struct {
+	eventsource.EventModel
+	ExpiresAt string `json:"expiresAt"`
+}
+
+// TenantExpired represents a tenant that has expired
+type TenantExpired struct {
+	eventsource.EventModel
+	ExpiresAt string `json:"expiresAt"`
+}
+
 type TenantDeleted struct {
 	eventsource.EventModel
 }
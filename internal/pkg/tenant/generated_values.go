@@ -0,0 +1,282 @@
+package tenant
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	corev1alpha1 "github.com/kristofferahl/aeto/apis/core/v1alpha1"
+	"github.com/kristofferahl/aeto/internal/pkg/kubernetes"
+	"github.com/kristofferahl/aeto/internal/pkg/reconcile"
+)
+
+const (
+	generatedPasswordCharset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_.~"
+	generatedPasswordLength  = 32
+	generatedStringCharset   = "abcdefghijklmnopqrstuvwxyz0123456789"
+	generatedStringLength    = 8
+)
+
+// GeneratedValuesSecret returns the namespaced name of the Secret holding the generated values of a tenant, the Secret
+// is kept next to the Tenant and owned by it
+func GeneratedValuesSecret(tenant types.NamespacedName) types.NamespacedName {
+	return types.NamespacedName{
+		Namespace: tenant.Namespace,
+		Name:      fmt.Sprintf("%s-generated-values", tenant.Name),
+	}
+}
+
+// RotateGeneratedValues removes the generated values named by the rotate annotation of the tenant, new values are
+// generated the next time resources are generated. The annotation is removed first, with a patch that fails when the
+// tenant has changed, so that values are never rotated twice for the same annotation.
+func RotateGeneratedValues(ctx reconcile.Context, c kubernetes.Client, tenant *corev1alpha1.Tenant) ([]string, error) {
+	value := tenant.Annotations[corev1alpha1.AnnotationRotateGeneratedValues]
+	if value == "" {
+		return nil, nil
+	}
+
+	names := make([]string, 0)
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	updated := tenant.DeepCopy()
+	delete(updated.Annotations, corev1alpha1.AnnotationRotateGeneratedValues)
+	if err := c.Patch(ctx, updated, client.MergeFromWithOptions(tenant, client.MergeFromWithOptimisticLock{})); err != nil {
+		return nil, err
+	}
+	tenant.ObjectMeta = updated.ObjectMeta
+
+	store := NewGeneratedValueStore(ctx, c, types.NamespacedName{Namespace: tenant.Namespace, Name: tenant.Name})
+	return store.Rotate(names)
+}
+
+// NewGeneratedValueStore returns a store of generated values backed by the Secret of the tenant
+func NewGeneratedValueStore(ctx reconcile.Context, client kubernetes.Client, tenant types.NamespacedName) *GeneratedValueStore {
+	return &GeneratedValueStore{
+		ctx:    ctx,
+		client: client,
+		tenant: tenant,
+		nn:     GeneratedValuesSecret(tenant),
+	}
+}
+
+// GeneratedValueStore generates values once and stores them in a Secret so that the same value is used on every render.
+// The Secret is read the first time a value is requested.
+type GeneratedValueStore struct {
+	ctx    reconcile.Context
+	client kubernetes.Client
+	tenant types.NamespacedName
+	nn     types.NamespacedName
+	dryRun bool
+	secret *corev1.Secret
+	stored bool
+}
+
+// Get returns the stored value or generates and stores a new value
+func (s *GeneratedValueStore) Get(ref corev1alpha1.GeneratedValueRef) (string, error) {
+	if err := s.load(); err != nil {
+		return "", err
+	}
+
+	if value, ok := s.secret.Data[ref.Name]; ok {
+		return string(value), nil
+	}
+
+	value, err := generateValue(ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate value %s, %v", ref.Name, err)
+	}
+
+	if s.secret.Data == nil {
+		s.secret.Data = make(map[string][]byte)
+	}
+	s.secret.Data[ref.Name] = []byte(value)
+
+	if s.dryRun {
+		return value, nil
+	}
+
+	s.ctx.Log.Info("storing generated value", "secret", s.nn.String(), "key", ref.Name)
+	if err := s.save(); err != nil {
+		delete(s.secret.Data, ref.Name)
+		return "", fmt.Errorf("failed to store generated value %s, %v", ref.Name, err)
+	}
+
+	return value, nil
+}
+
+// Reference returns the reference to the Secret key holding the generated value
+func (s *GeneratedValueStore) Reference(ref corev1alpha1.GeneratedValueRef) kubernetes.SecretReference {
+	return kubernetes.SecretReference{
+		Namespace: s.nn.Namespace,
+		Name:      s.nn.Name,
+		Key:       ref.Name,
+	}
+}
+
+// Stored returns true when the Secret has been read and exists
+func (s *GeneratedValueStore) Stored() bool {
+	return s.stored
+}
+
+// Rotate removes the named values, or all values when names contains *, so that new values are generated on the next
+// render. The names of the removed values are returned.
+func (s *GeneratedValueStore) Rotate(names []string) ([]string, error) {
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+
+	rotated := make([]string, 0)
+	for key := range s.secret.Data {
+		for _, name := range names {
+			if name == "*" || name == key {
+				rotated = append(rotated, key)
+				break
+			}
+		}
+	}
+	sort.Strings(rotated)
+
+	if len(rotated) == 0 || s.dryRun {
+		return rotated, nil
+	}
+
+	for _, key := range rotated {
+		delete(s.secret.Data, key)
+	}
+	if err := s.save(); err != nil {
+		s.secret = nil
+		return nil, fmt.Errorf("failed to rotate generated values, %v", err)
+	}
+
+	return rotated, nil
+}
+
+func (s *GeneratedValueStore) load() error {
+	if s.secret != nil {
+		return nil
+	}
+
+	owner, err := s.owner()
+	if err != nil {
+		return err
+	}
+
+	secret := &corev1.Secret{}
+	if err := s.client.Get(s.ctx, s.nn, secret); err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: s.nn.Namespace,
+				Name:      s.nn.Name,
+			},
+			Type: corev1.SecretTypeOpaque,
+		}
+	} else {
+		s.stored = true
+
+		// A Secret owned by another Tenant with the same name is left over from a deleted Tenant, its values must
+		// not be reused
+		if owner != nil && !ownedBy(secret, *owner) && len(secret.OwnerReferences) > 0 {
+			s.ctx.Log.Info("discarding generated values of a previous tenant", "secret", s.nn.String())
+			secret.Data = nil
+		}
+	}
+
+	if owner != nil && !ownedBy(secret, *owner) {
+		secret.OwnerReferences = []metav1.OwnerReference{*owner}
+	}
+
+	s.secret = secret
+	return nil
+}
+
+// owner returns the owner reference of the Secret, nil when the Tenant does not exist when running dry
+func (s *GeneratedValueStore) owner() (*metav1.OwnerReference, error) {
+	var tenant corev1alpha1.Tenant
+	if err := s.client.Get(s.ctx, s.tenant, &tenant); err != nil {
+		if errors.IsNotFound(err) && s.dryRun {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to fetch owner of generated values, %v", err)
+	}
+
+	return &metav1.OwnerReference{
+		APIVersion: corev1alpha1.GroupVersion.String(),
+		Kind:       "Tenant",
+		Name:       tenant.Name,
+		UID:        tenant.UID,
+	}, nil
+}
+
+func (s *GeneratedValueStore) save() error {
+	if s.stored {
+		return s.client.Update(s.ctx, s.secret)
+	}
+
+	if err := s.client.Create(s.ctx, s.secret); err != nil {
+		return err
+	}
+	s.stored = true
+	return nil
+}
+
+// ownedBy returns true when the owner is among the owners of the Secret
+func ownedBy(secret *corev1.Secret, owner metav1.OwnerReference) bool {
+	for _, ref := range secret.OwnerReferences {
+		if ref.UID == owner.UID {
+			return true
+		}
+	}
+	return false
+}
+
+// generateValue returns a new random value of the type
+func generateValue(ref corev1alpha1.GeneratedValueRef) (string, error) {
+	switch ref.Type {
+	case corev1alpha1.GeneratedValueUUID:
+		return string(uuid.NewUUID()), nil
+	case corev1alpha1.GeneratedValueString:
+		return randomString(ref.Length, generatedStringLength, ref.Charset, generatedStringCharset)
+	case corev1alpha1.GeneratedValuePassword, "":
+		return randomString(ref.Length, generatedPasswordLength, ref.Charset, generatedPasswordCharset)
+	default:
+		return "", fmt.Errorf("unsupported type %s", ref.Type)
+	}
+}
+
+// randomString returns a string of characters from the charset using a cryptographically secure source
+func randomString(length int, defaultLength int, charset string, defaultCharset string) (string, error) {
+	if length <= 0 {
+		length = defaultLength
+	}
+	if charset == "" {
+		charset = defaultCharset
+	}
+
+	chars := []rune(charset)
+	max := big.NewInt(int64(len(chars)))
+	result := make([]rune, length)
+	for i := range result {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		result[i] = chars[n.Int64()]
+	}
+	return string(result), nil
+}
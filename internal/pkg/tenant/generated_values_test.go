@@ -0,0 +1,225 @@
+package tenant
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	corev1alpha1 "github.com/kristofferahl/aeto/apis/core/v1alpha1"
+	"github.com/kristofferahl/aeto/internal/pkg/kubernetes"
+	"github.com/kristofferahl/aeto/internal/pkg/reconcile"
+)
+
+var _ = Describe("Generated values", func() {
+	DescribeTable("generateValue",
+		func(ref corev1alpha1.GeneratedValueRef, length int, charset string) {
+			value, err := generateValue(ref)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(value).To(HaveLen(length))
+			for _, c := range value {
+				Expect(charset).To(ContainSubstring(string(c)))
+			}
+		},
+		Entry("password by default", corev1alpha1.GeneratedValueRef{Name: "a"}, generatedPasswordLength, generatedPasswordCharset),
+		Entry("string", corev1alpha1.GeneratedValueRef{Name: "a", Type: corev1alpha1.GeneratedValueString}, generatedStringLength, generatedStringCharset),
+		Entry("custom length", corev1alpha1.GeneratedValueRef{Name: "a", Length: 100}, 100, generatedPasswordCharset),
+		Entry("custom charset", corev1alpha1.GeneratedValueRef{Name: "a", Type: corev1alpha1.GeneratedValueString, Charset: "ab"}, generatedStringLength, "ab"),
+		Entry("multi-byte charset", corev1alpha1.GeneratedValueRef{Name: "a", Type: corev1alpha1.GeneratedValueString, Charset: "åäö", Length: 4}, len("åäöå"), "åäö"),
+		Entry("uuid", corev1alpha1.GeneratedValueRef{Name: "a", Type: corev1alpha1.GeneratedValueUUID}, 36, "0123456789abcdef-"),
+	)
+
+	It("should fail for unsupported types", func() {
+		_, err := generateValue(corev1alpha1.GeneratedValueRef{Name: "a", Type: "number"})
+		Expect(err).To(MatchError("unsupported type number"))
+	})
+
+	It("should not repeat random strings", func() {
+		a, err := randomString(0, generatedPasswordLength, "", generatedPasswordCharset)
+		Expect(err).NotTo(HaveOccurred())
+		b, err := randomString(0, generatedPasswordLength, "", generatedPasswordCharset)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(a).NotTo(Equal(b))
+	})
+
+	Describe("GeneratedValueStore", func() {
+		var (
+			ctx    reconcile.Context
+			c      client.Client
+			nn     types.NamespacedName
+			tenant *corev1alpha1.Tenant
+			ref    corev1alpha1.GeneratedValueRef
+		)
+
+		newStore := func(dryRun bool) *GeneratedValueStore {
+			s := NewGeneratedValueStore(ctx, kubernetes.NewClient(c, nil, nil), nn)
+			s.dryRun = dryRun
+			return s
+		}
+
+		secret := func() *corev1.Secret {
+			var s corev1.Secret
+			Expect(c.Get(ctx.Context, GeneratedValuesSecret(nn), &s)).To(Succeed())
+			return &s
+		}
+
+		BeforeEach(func() {
+			scheme := runtime.NewScheme()
+			Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+			Expect(corev1alpha1.AddToScheme(scheme)).To(Succeed())
+
+			nn = types.NamespacedName{Namespace: "aeto", Name: "acme"}
+			tenant = &corev1alpha1.Tenant{ObjectMeta: metav1.ObjectMeta{Namespace: nn.Namespace, Name: nn.Name, UID: "tenant-uid"}}
+			c = fake.NewClientBuilder().WithScheme(scheme).WithObjects(tenant).Build()
+			ctx = reconcile.NewContext("test", ctrl.Request{NamespacedName: nn}, logf.Log)
+			ref = corev1alpha1.GeneratedValueRef{Name: "db-password"}
+		})
+
+		It("should store generated values in a Secret owned by the tenant", func() {
+			s := newStore(false)
+			value, err := s.Get(ref)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(s.Stored()).To(BeTrue())
+
+			stored := secret()
+			Expect(string(stored.Data[ref.Name])).To(Equal(value))
+			Expect(stored.OwnerReferences).To(HaveLen(1))
+			Expect(stored.OwnerReferences[0].UID).To(Equal(tenant.UID))
+			Expect(s.Reference(ref)).To(Equal(kubernetes.SecretReference{Namespace: "aeto", Name: "acme-generated-values", Key: ref.Name}))
+		})
+
+		It("should reuse stored values", func() {
+			first, err := newStore(false).Get(ref)
+			Expect(err).NotTo(HaveOccurred())
+
+			second, err := newStore(false).Get(ref)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(second).To(Equal(first))
+		})
+
+		It("should not store values when running dry", func() {
+			s := newStore(true)
+			_, err := s.Get(ref)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(s.Stored()).To(BeFalse())
+
+			var list corev1.SecretList
+			Expect(c.List(ctx.Context, &list)).To(Succeed())
+			Expect(list.Items).To(BeEmpty())
+		})
+
+		It("should not reuse values of a Secret owned by another tenant", func() {
+			Expect(c.Create(ctx.Context, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:       nn.Namespace,
+					Name:            GeneratedValuesSecret(nn).Name,
+					OwnerReferences: []metav1.OwnerReference{{APIVersion: "core.aeto.net/v1alpha1", Kind: "Tenant", Name: nn.Name, UID: "previous-uid"}},
+				},
+				Data: map[string][]byte{ref.Name: []byte("previous")},
+			})).To(Succeed())
+
+			value, err := newStore(false).Get(ref)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(value).NotTo(Equal("previous"))
+
+			stored := secret()
+			Expect(string(stored.Data[ref.Name])).To(Equal(value))
+			Expect(stored.OwnerReferences).To(HaveLen(1))
+			Expect(stored.OwnerReferences[0].UID).To(Equal(tenant.UID))
+		})
+
+		It("should fail when the tenant is not found", func() {
+			Expect(c.Delete(ctx.Context, tenant)).To(Succeed())
+
+			_, err := newStore(false).Get(ref)
+			Expect(err).To(MatchError(ContainSubstring("failed to fetch owner of generated values")))
+
+			_, err = newStore(true).Get(ref)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should rotate values", func() {
+			s := newStore(false)
+			old, err := s.Get(ref)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = s.Get(corev1alpha1.GeneratedValueRef{Name: "api-key"})
+			Expect(err).NotTo(HaveOccurred())
+
+			rotated, err := newStore(true).Rotate([]string{"*"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rotated).To(Equal([]string{"api-key", "db-password"}))
+			Expect(secret().Data).To(HaveLen(2))
+
+			rotated, err = newStore(false).Rotate([]string{ref.Name, "unknown"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rotated).To(Equal([]string{ref.Name}))
+			Expect(secret().Data).To(HaveKey("api-key"))
+			Expect(secret().Data).NotTo(HaveKey(ref.Name))
+
+			value, err := newStore(false).Get(ref)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(value).NotTo(Equal(old))
+			Expect(value).To(HaveLen(generatedPasswordLength))
+		})
+
+		Describe("RotateGeneratedValues", func() {
+			var value string
+
+			annotate := func(names string) *corev1alpha1.Tenant {
+				var t corev1alpha1.Tenant
+				Expect(c.Get(ctx.Context, nn, &t)).To(Succeed())
+				t.Annotations = map[string]string{corev1alpha1.AnnotationRotateGeneratedValues: names}
+				Expect(c.Update(ctx.Context, &t)).To(Succeed())
+				return &t
+			}
+
+			BeforeEach(func() {
+				var err error
+				value, err = newStore(false).Get(ref)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should remove the annotation and rotate the named values", func() {
+				t := annotate(" db-password , ")
+
+				rotated, err := RotateGeneratedValues(ctx, kubernetes.NewClient(c, nil, nil), t)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(rotated).To(Equal([]string{ref.Name}))
+				Expect(t.Annotations).NotTo(HaveKey(corev1alpha1.AnnotationRotateGeneratedValues))
+				Expect(secret().Data).NotTo(HaveKey(ref.Name))
+
+				var stored corev1alpha1.Tenant
+				Expect(c.Get(ctx.Context, nn, &stored)).To(Succeed())
+				Expect(stored.Annotations).NotTo(HaveKey(corev1alpha1.AnnotationRotateGeneratedValues))
+			})
+
+			It("should not rotate values when the annotation could not be removed", func() {
+				stale := annotate("*")
+				annotate("*")
+
+				_, err := RotateGeneratedValues(ctx, kubernetes.NewClient(c, nil, nil), stale)
+				Expect(errors.IsConflict(err)).To(BeTrue())
+				Expect(string(secret().Data[ref.Name])).To(Equal(value))
+			})
+
+			It("should do nothing without the annotation", func() {
+				var t corev1alpha1.Tenant
+				Expect(c.Get(ctx.Context, nn, &t)).To(Succeed())
+
+				rotated, err := RotateGeneratedValues(ctx, kubernetes.NewClient(c, nil, nil), &t)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(rotated).To(BeEmpty())
+				Expect(string(secret().Data[ref.Name])).To(Equal(value))
+			})
+		})
+	})
+})
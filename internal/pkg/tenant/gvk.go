@@ -8,4 +8,9 @@ var (
 		Version: "v1",
 		Kind:    "Namespace",
 	}
+	jobGVK = schema.GroupVersionKind{
+		Group:   "batch",
+		Version: "v1",
+		Kind:    "Job",
+	}
 )
@@ -0,0 +1,142 @@
+package tenant
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+
+	corev1alpha1 "github.com/kristofferahl/aeto/apis/core/v1alpha1"
+	"github.com/kristofferahl/aeto/internal/pkg/reconcile"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	maxHookJobNameLength  = 63
+	hookJobNameHashLength = 8
+)
+
+const (
+	HookJobRunning   HookJobStatus = "Running"
+	HookJobSucceeded HookJobStatus = "Succeeded"
+	HookJobFailed    HookJobStatus = "Failed"
+)
+
+type HookJobStatus string
+
+// HookFailedError is returned when a hook with failure policy Abort has failed
+type HookFailedError struct {
+	Name   string
+	Phase  string
+	Reason string
+}
+
+func (e *HookFailedError) Error() string {
+	return fmt.Sprintf("%s hook %s failed, %s", e.Phase, e.Name, e.Reason)
+}
+
+type HookState struct {
+	Name         string
+	Phase        string
+	JobName      string
+	JobNamespace string
+	Completed    bool
+	Failed       bool
+	Reason       string
+}
+
+// Job returns the namespaced name of the hook job
+func (h HookState) Job() types.NamespacedName {
+	return types.NamespacedName{
+		Namespace: h.JobNamespace,
+		Name:      h.JobName,
+	}
+}
+
+func NewHookRunner(ctx reconcile.Context, services ResourceGeneratoreServices) HookRunner {
+	return HookRunner{
+		ctx:       ctx,
+		services:  services,
+		generator: NewResourceGenerator(ctx, services),
+	}
+}
+
+type HookRunner struct {
+	ctx       reconcile.Context
+	services  ResourceGeneratoreServices
+	generator ResourceGenerator
+}
+
+// Start generates and applies the Job of a blueprint hook
+func (r HookRunner) Start(state State, blueprint corev1alpha1.Blueprint, hook corev1alpha1.BlueprintHook) (types.NamespacedName, error) {
+	job, err := r.generator.GenerateHookJob(state, blueprint, hook)
+	if err != nil {
+		return types.NamespacedName{}, err
+	}
+
+	job.SetName(hookJobName(state.TenantPrefixedName, hook))
+	nn := types.NamespacedName{
+		Namespace: job.GetNamespace(),
+		Name:      job.GetName(),
+	}
+
+	r.ctx.Log.Info("starting hook job", "hook", hook.Name, "phase", hook.Phase, "job", nn.String())
+	if err := r.services.Client.ResolveSecretReferences(r.ctx, job.Object); err != nil {
+		return types.NamespacedName{}, err
+	}
+
+	bytes, err := job.MarshalJSON()
+	if err != nil {
+		return types.NamespacedName{}, err
+	}
+
+	if err := r.services.Client.DynamicApply(r.ctx, nn, string(bytes)); err != nil {
+		return types.NamespacedName{}, err
+	}
+
+	return nn, nil
+}
+
+// Status returns the status of a hook Job
+func (r HookRunner) Status(nn types.NamespacedName) (status HookJobStatus, reason string, err error) {
+	var job batchv1.Job
+	if err := r.services.Client.Get(r.ctx, nn, &job); err != nil {
+		if errors.IsNotFound(err) {
+			return HookJobFailed, fmt.Sprintf("job %s not found", nn.String()), nil
+		}
+		return "", "", err
+	}
+
+	for _, c := range job.Status.Conditions {
+		if c.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch c.Type {
+		case batchv1.JobComplete:
+			return HookJobSucceeded, "", nil
+		case batchv1.JobFailed:
+			return HookJobFailed, fmt.Sprintf("%s: %s", c.Reason, c.Message), nil
+		}
+	}
+
+	return HookJobRunning, "", nil
+}
+
+func hookKey(phase string, name string) string {
+	return fmt.Sprintf("%s/%s", phase, name)
+}
+
+// hookJobName returns the name of the Job of a hook, names longer than 63 characters are truncated and suffixed with a
+// hash of the full name to keep them unique
+func hookJobName(prefix string, hook corev1alpha1.BlueprintHook) string {
+	name := strings.ToLower(fmt.Sprintf("%s-%s-%s", prefix, hook.Phase, hook.Name))
+	name = strings.ReplaceAll(name, "_", "-")
+	if len(name) > maxHookJobNameLength {
+		hash := fmt.Sprintf("%x", sha256.Sum256([]byte(name)))[:hookJobNameHashLength]
+		name = strings.TrimRight(name[:maxHookJobNameLength-hookJobNameHashLength-1], "-.") + "-" + hash
+	}
+	return strings.TrimRight(name, "-.")
+}
@@ -0,0 +1,35 @@
+package tenant
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1alpha1 "github.com/kristofferahl/aeto/apis/core/v1alpha1"
+)
+
+func nameHash(name string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(name)))[:hookJobNameHashLength]
+}
+
+var _ = Describe("Hook runner", func() {
+	DescribeTable("hookJobName",
+		func(prefix string, hook corev1alpha1.BlueprintHook, expected string) {
+			Expect(hookJobName(prefix, hook)).To(Equal(expected))
+		},
+		Entry("short name", "acme", corev1alpha1.BlueprintHook{Name: "Migrate_DB", Phase: corev1alpha1.HookPhasePreProvision}, "acme-preprovision-migrate-db"),
+		Entry("long name", strings.Repeat("a", 50), corev1alpha1.BlueprintHook{Name: "migrate", Phase: corev1alpha1.HookPhasePreProvision}, strings.Repeat("a", 50)+"-pre-"+nameHash(strings.Repeat("a", 50)+"-preprovision-migrate")),
+	)
+
+	It("should keep long names unique and within the length limit", func() {
+		prefix := strings.Repeat("a", 60)
+		a := hookJobName(prefix, corev1alpha1.BlueprintHook{Name: "migrate", Phase: corev1alpha1.HookPhasePreProvision})
+		b := hookJobName(prefix, corev1alpha1.BlueprintHook{Name: "seed", Phase: corev1alpha1.HookPhasePreProvision})
+		Expect(len(a)).To(BeNumerically("<=", maxHookJobNameLength))
+		Expect(len(b)).To(BeNumerically("<=", maxHookJobNameLength))
+		Expect(a).NotTo(Equal(b))
+	})
+})
@@ -0,0 +1,99 @@
+package tenant
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/PaesslerAG/jsonpath"
+
+	corev1alpha1 "github.com/kristofferahl/aeto/apis/core/v1alpha1"
+	"github.com/kristofferahl/aeto/internal/pkg/kubernetes"
+	"github.com/kristofferahl/aeto/internal/pkg/reconcile"
+)
+
+// Validate returns an error when reading the output from the resource could expose secret values. Outputs are not read
+// from resources rendered from templates referencing secret parameters nor from live Secrets.
+func (o Output) Validate(r Resource) error {
+	if r.Sensitive {
+		return fmt.Errorf("outputs are not read from resources referencing secret parameters")
+	}
+
+	if corev1alpha1.OutputSource(o.Source) == corev1alpha1.OutputSourceLive {
+		ri, err := r.ResourceIdentifier()
+		if err != nil {
+			return err
+		}
+		if ri.GroupVersionKind.Group == "" && ri.GroupVersionKind.Kind == "Secret" {
+			return fmt.Errorf("outputs are not read from live Secrets")
+		}
+	}
+
+	return nil
+}
+
+// Resolve returns the value of the output read from the rendered or live resource, values that are not strings are
+// returned as JSON
+func (o Output) Resolve(ctx reconcile.Context, k8s kubernetes.Client, resources ResourceList) (string, error) {
+	_, resource := resources.Find(o.ResourceId)
+	if resource == nil {
+		return "", fmt.Errorf("resource %s not found", o.ResourceId)
+	}
+
+	if err := o.Validate(*resource); err != nil {
+		return "", err
+	}
+
+	var v interface{}
+	switch corev1alpha1.OutputSource(o.Source) {
+	case corev1alpha1.OutputSourceLive:
+		ri, err := resource.ResourceIdentifier()
+		if err != nil {
+			return "", err
+		}
+		live, err := k8s.DynamicGet(ctx, ri.NamespacedName, ri.GroupVersionKind)
+		if err != nil {
+			return "", err
+		}
+		if live == nil {
+			return "", fmt.Errorf("resource %s %s not found", ri.NamespacedName.String(), ri.GroupVersionKind.String())
+		}
+		v = live.Object
+	default:
+		if err := json.Unmarshal(resource.Embedded.Raw, &v); err != nil {
+			return "", err
+		}
+	}
+
+	value, err := jsonpath.Get(fmt.Sprintf("$%s", o.JsonPath), v)
+	if err != nil {
+		return "", fmt.Errorf("jsonpath error: %v", err)
+	}
+
+	s, err := outputValue(value)
+	if err != nil {
+		return "", err
+	}
+	if kubernetes.ContainsSecretReference(s) {
+		return "", fmt.Errorf("outputs are not read from values referencing secrets")
+	}
+
+	return s, nil
+}
+
+// outputValue returns strings as is, numbers and booleans formatted and other values as JSON
+func outputValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case map[string]interface{}, []interface{}:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	case nil:
+		return "", nil
+	default:
+		return fmt.Sprintf("%v", v), nil
+	}
+}
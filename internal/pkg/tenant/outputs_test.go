@@ -0,0 +1,117 @@
+package tenant
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	corev1alpha1 "github.com/kristofferahl/aeto/apis/core/v1alpha1"
+	"github.com/kristofferahl/aeto/internal/pkg/kubernetes"
+	"github.com/kristofferahl/aeto/internal/pkg/reconcile"
+)
+
+var _ = Describe("Output", func() {
+	resource := func(id string, sensitive bool, raw string) Resource {
+		r := Resource{Id: id, Sensitive: sensitive}
+		r.Embedded.Raw = []byte(raw)
+		return r
+	}
+
+	configMap := resource("cm", false, `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"app","namespace":"acme"},"data":{"host":"db.local","port":"5432"}}`)
+	secret := resource("secret", false, `{"apiVersion":"v1","kind":"Secret","metadata":{"name":"app","namespace":"acme"}}`)
+	sensitive := resource("sensitive", true, `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"db","namespace":"acme"}}`)
+	referencing := resource("referencing", false, `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"ref","namespace":"acme"},"data":{"url":"postgres://$(aeto:secret:acme/db/password)@db"}}`)
+
+	DescribeTable("Validate",
+		func(source corev1alpha1.OutputSource, r Resource, expected string) {
+			err := Output{Name: "value", ResourceId: r.Id, Source: string(source), JsonPath: ".metadata.name"}.Validate(r)
+			if expected == "" {
+				Expect(err).NotTo(HaveOccurred())
+			} else {
+				Expect(err).To(MatchError(expected))
+			}
+		},
+		Entry("rendered resource", corev1alpha1.OutputSourceRendered, configMap, ""),
+		Entry("live resource", corev1alpha1.OutputSourceLive, configMap, ""),
+		Entry("rendered Secret", corev1alpha1.OutputSourceRendered, secret, ""),
+		Entry("live Secret", corev1alpha1.OutputSourceLive, secret, "outputs are not read from live Secrets"),
+		Entry("rendered sensitive resource", corev1alpha1.OutputSourceRendered, sensitive, "outputs are not read from resources referencing secret parameters"),
+		Entry("live sensitive resource", corev1alpha1.OutputSourceLive, sensitive, "outputs are not read from resources referencing secret parameters"),
+	)
+
+	DescribeTable("outputValue",
+		func(value interface{}, expected string) {
+			s, err := outputValue(value)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(s).To(Equal(expected))
+		},
+		Entry("string", "a", "a"),
+		Entry("int", int64(3), "3"),
+		Entry("float", float64(1.5), "1.5"),
+		Entry("bool", true, "true"),
+		Entry("nil", nil, ""),
+		Entry("map", map[string]interface{}{"b": "2", "a": int64(1)}, `{"a":1,"b":"2"}`),
+		Entry("list", []interface{}{"a", int64(1)}, `["a",1]`),
+	)
+
+	Describe("Resolve", func() {
+		var (
+			ctx       reconcile.Context
+			k8s       kubernetes.Client
+			resources ResourceList
+		)
+
+		BeforeEach(func() {
+			scheme := runtime.NewScheme()
+			Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+
+			mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{corev1.SchemeGroupVersion})
+			mapper.Add(corev1.SchemeGroupVersion.WithKind("ConfigMap"), meta.RESTScopeNamespace)
+			mapper.Add(corev1.SchemeGroupVersion.WithKind("Secret"), meta.RESTScopeNamespace)
+
+			live := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "acme", Name: "app", Labels: map[string]string{"app": "db"}},
+				Data:       map[string]string{"host": "db.remote"},
+			}
+			dynamic := dynamicfake.NewSimpleDynamicClient(scheme, live)
+			k8s = kubernetes.NewClient(fake.NewClientBuilder().WithScheme(scheme).Build(), dynamic, nil).WithRESTMapper(mapper)
+			ctx = reconcile.NewContext("test", ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "aeto", Name: "acme"}}, logf.Log)
+			resources = ResourceList{configMap, secret, sensitive, referencing}
+		})
+
+		DescribeTable("values",
+			func(output Output, expected string) {
+				value, err := output.Resolve(ctx, k8s, resources)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(value).To(Equal(expected))
+			},
+			Entry("rendered value", Output{ResourceId: "cm", Source: "Rendered", JsonPath: ".data.host"}, "db.local"),
+			Entry("rendered object", Output{ResourceId: "cm", Source: "Rendered", JsonPath: ".data"}, `{"host":"db.local","port":"5432"}`),
+			Entry("live value", Output{ResourceId: "cm", Source: "Live", JsonPath: ".data.host"}, "db.remote"),
+			Entry("live object", Output{ResourceId: "cm", Source: "Live", JsonPath: ".metadata.labels"}, `{"app":"db"}`),
+		)
+
+		DescribeTable("errors",
+			func(output Output, expected string) {
+				_, err := output.Resolve(ctx, k8s, resources)
+				Expect(err).To(MatchError(ContainSubstring(expected)))
+			},
+			Entry("unknown resource", Output{ResourceId: "unknown", Source: "Rendered", JsonPath: ".data"}, "resource unknown not found"),
+			Entry("live Secret", Output{ResourceId: "secret", Source: "Live", JsonPath: ".data"}, "outputs are not read from live Secrets"),
+			Entry("sensitive resource", Output{ResourceId: "sensitive", Source: "Rendered", JsonPath: ".metadata.name"}, "outputs are not read from resources referencing secret parameters"),
+			Entry("secret reference", Output{ResourceId: "referencing", Source: "Rendered", JsonPath: ".data.url"}, "outputs are not read from values referencing secrets"),
+			Entry("invalid path", Output{ResourceId: "cm", Source: "Rendered", JsonPath: ".data.missing"}, "jsonpath error"),
+		)
+	})
+})
@@ -1,16 +1,23 @@
 package tenant
 
 import (
+	"encoding/base64"
 	"fmt"
 
+	yaml "github.com/ghodss/yaml"
+
 	corev1alpha1 "github.com/kristofferahl/aeto/apis/core/v1alpha1"
 	"github.com/kristofferahl/aeto/internal/pkg/config"
 	"github.com/kristofferahl/aeto/internal/pkg/convert"
+	"github.com/kristofferahl/aeto/internal/pkg/helm"
 	"github.com/kristofferahl/aeto/internal/pkg/kubernetes"
 	"github.com/kristofferahl/aeto/internal/pkg/reconcile"
 	"github.com/kristofferahl/aeto/internal/pkg/template"
 	"github.com/kristofferahl/aeto/internal/pkg/util"
 
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -25,9 +32,40 @@ func NewResourceGenerator(ctx reconcile.Context, services ResourceGeneratoreServ
 }
 
 type ResourceGenerator struct {
-	services ResourceGeneratoreServices
-	ctx      reconcile.Context
-	state    State
+	services           ResourceGeneratoreServices
+	ctx                reconcile.Context
+	state              State
+	templates          map[types.NamespacedName]corev1alpha1.ResourceTemplate
+	blueprintRevisions []string
+	revisions          []string
+	dryRun             bool
+	generated          *GeneratedValueStore
+	secrets            kubernetes.SecretValues
+}
+
+// DryRun returns a copy of the generator that does not store generated values
+func (r ResourceGenerator) DryRun() ResourceGenerator {
+	r.dryRun = true
+	return r
+}
+
+// WithBlueprintRevisions returns a copy of the generator that records the specified blueprint revisions as used when generating resources
+func (r ResourceGenerator) WithBlueprintRevisions(revisions ...string) ResourceGenerator {
+	r.blueprintRevisions = append([]string{}, revisions...)
+	return r
+}
+
+// WithTemplates returns a copy of the generator that uses the specified resource templates instead of the stored resource templates with the same namespace/name
+func (r ResourceGenerator) WithTemplates(templates ...corev1alpha1.ResourceTemplate) ResourceGenerator {
+	overrides := make(map[types.NamespacedName]corev1alpha1.ResourceTemplate)
+	for nn, rt := range r.templates {
+		overrides[nn] = rt
+	}
+	for _, rt := range templates {
+		overrides[rt.NamespacedName()] = rt
+	}
+	r.templates = overrides
+	return r
 }
 
 type ResourceGeneratoreServices struct {
@@ -37,6 +75,12 @@ type ResourceGeneratoreServices struct {
 type ResourceGenerationResult struct {
 	ResourceGroups ResourceGroupList
 	Sum            string
+	Revisions      []string
+	Deferred       []DeferredResourceGroup
+	Released       []string
+
+	// GeneratedValues is the Secret holding the generated values of the tenant, empty when no values are stored
+	GeneratedValues types.NamespacedName
 }
 
 type GenerateError struct {
@@ -68,43 +112,101 @@ type resourceSum struct {
 
 func (r *ResourceGenerator) Generate(state State, blueprint corev1alpha1.Blueprint) (result ResourceGenerationResult, err error) {
 	r.state = state
+	r.revisions = append([]string{}, r.blueprintRevisions...)
 
 	result.ResourceGroups = make([]ResourceGroup, 0)
 	errors := make([]error, 0)
 	resourceIndex := 0
+	resourceGroupNames := make(map[string]string)
+	outputNames := make(map[string]string)
+	skipped := make(map[string]bool)
+
+	resourceGroups, err := blueprint.OrderedResources()
+	if err != nil {
+		errors = append(errors, err)
+	}
+
+	for _, resourceGroup := range resourceGroups {
+		if resourceGroup.Condition != nil {
+			ok, err := r.conditionMatches(*resourceGroup.Condition, result.ResourceGroups)
+			if err != nil {
+				errors = append(errors, fmt.Errorf("invalid condition of resource group %s, %v", resourceGroup.Name, err))
+				continue
+			}
+			if !ok {
+				r.ctx.Log.V(1).Info("skipping resource group, condition not met", "group", resourceGroup.Name)
+				skipped[resourceGroup.Name] = true
+				continue
+			}
+		}
+
+		if len(resourceGroup.DependsOn) > 0 && !state.ResourceGroupsReleased[resourceGroup.Name] {
+			waitingFor := r.waitingFor(resourceGroup, result.ResourceGroups, skipped)
+			if len(waitingFor) > 0 {
+				r.ctx.Log.V(1).Info("deferring resource group, dependencies not ready", "group", resourceGroup.Name, "waiting-for", waitingFor)
+				result.Deferred = append(result.Deferred, DeferredResourceGroup{Name: resourceGroup.Name, WaitingFor: waitingFor})
+				continue
+			}
+			result.Released = append(result.Released, resourceGroup.Name)
+		}
 
-	for _, resourceGroup := range blueprint.Spec.Resources {
 		group := ResourceGroup{
 			Name:           resourceGroup.Name,
 			SourceTemplate: resourceGroup.Template,
 			Resources:      make([]Resource, 0),
 		}
 
-		unstructured, err := r.generateFromResourceGroup(resourceGroup, blueprint, result.ResourceGroups)
+		unstructured, outputs, source, err := r.generateFromResourceGroup(resourceGroup, blueprint, result.ResourceGroups)
 		if err != nil {
 			errors = append(errors, err)
 			continue
 		}
 
-		for _, resource := range unstructured {
+		for index, resource := range unstructured {
 			resourceIndex++
-			bytes, err := resource.MarshalJSON()
+			gvk := resource.GroupVersionKind()
+			uid := gvk.Group + "/* , Kind=" + gvk.Kind + " " + resource.GetNamespace() + "/" + resource.GetName()
+			id, err := util.AsSha256(uid)
 			if err != nil {
 				errors = append(errors, err)
 				continue
 			}
 
-			gvk := resource.GroupVersionKind()
-			uid := gvk.Group + "/* , Kind=" + gvk.Kind + " " + resource.GetNamespace() + "/" + resource.GetName()
-			id, err := util.AsSha256(uid)
+			if groupName, ok := resourceGroupNames[id]; ok {
+				errors = append(errors, fmt.Errorf("resource %s %s/%s of resource group %s conflicts with resource of resource group %s", gvk.Kind, resource.GetNamespace(), resource.GetName(), resourceGroup.Name, groupName))
+				continue
+			}
+			resourceGroupNames[id] = resourceGroup.Name
+
+			if state.Adopted[id] {
+				retainAdopted(resource)
+			}
+
+			bytes, err := resource.MarshalJSON()
 			if err != nil {
 				errors = append(errors, err)
 				continue
 			}
+			sum := r.resourceSum(bytes)
+
+			var resourceSource *corev1alpha1.ResourceSource
+			if source != nil {
+				// sensitive resources are stored without their content and rendered again when applied
+				resourceSource = source.DeepCopy()
+				resourceSource.Index = index
+				bytes, err = sensitivePlaceholder(resource, r.state)
+				if err != nil {
+					errors = append(errors, err)
+					continue
+				}
+			}
+
 			group.Resources = append(group.Resources, Resource{
-				Id:    id,
-				Order: resourceIndex,
-				Sum:   util.Sha256Sum(bytes),
+				Id:        id,
+				Order:     resourceIndex,
+				Sum:       sum,
+				Sensitive: source != nil,
+				Source:    resourceSource,
 				Embedded: EmbeddedResource{
 					RawExtension: runtime.RawExtension{
 						Raw: bytes,
@@ -113,6 +215,21 @@ func (r *ResourceGenerator) Generate(state State, blueprint corev1alpha1.Bluepri
 			})
 		}
 
+		for _, output := range outputs {
+			if groupName, ok := outputNames[output.Name]; ok {
+				errors = append(errors, fmt.Errorf("output %s of resource group %s conflicts with output of resource group %s", output.Name, resourceGroup.Name, groupName))
+				continue
+			}
+			outputNames[output.Name] = resourceGroup.Name
+
+			o, err := newOutput(output, group.Resources)
+			if err != nil {
+				errors = append(errors, fmt.Errorf("invalid output %s of resource group %s, %v", output.Name, resourceGroup.Name, err))
+				continue
+			}
+			group.Outputs = append(group.Outputs, o)
+		}
+
 		result.ResourceGroups = append(result.ResourceGroups, group)
 	}
 
@@ -135,6 +252,12 @@ func (r *ResourceGenerator) Generate(state State, blueprint corev1alpha1.Bluepri
 		result.Sum = sum
 	}
 
+	result.Revisions = r.revisions
+
+	if r.generated != nil && r.generated.Stored() {
+		result.GeneratedValues = r.generated.nn
+	}
+
 	if len(errors) > 0 {
 		ge := &GenerateError{
 			Errors: errors,
@@ -146,55 +269,84 @@ func (r *ResourceGenerator) Generate(state State, blueprint corev1alpha1.Bluepri
 	return result, err
 }
 
-func (r *ResourceGenerator) generateFromResourceGroup(resourceGroup corev1alpha1.BlueprintResourceGroup, blueprint corev1alpha1.Blueprint, resourceGroups []ResourceGroup) ([]*unstructured.Unstructured, error) {
+// GenerateHookJob generates the Job of a blueprint hook from the hook template
+func (r *ResourceGenerator) GenerateHookJob(state State, blueprint corev1alpha1.Blueprint, hook corev1alpha1.BlueprintHook) (*unstructured.Unstructured, error) {
+	r.state = state
+
+	resources, _, _, err := r.generateFromResourceGroup(corev1alpha1.BlueprintResourceGroup{
+		Name:       hook.Name,
+		Template:   hook.Template,
+		Parameters: hook.Parameters,
+	}, blueprint, make([]ResourceGroup, 0))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(resources) != 1 || resources[0].GroupVersionKind() != jobGVK {
+		return nil, fmt.Errorf("hook template %s must generate exactly 1 %s, got %d resource(s)", hook.Template, jobGVK.Kind, len(resources))
+	}
+
+	return resources[0], nil
+}
+
+// generateFromResourceGroup generates the resources of a resource group. When the template references parameters read
+// from Secrets or generated the source of the resources is returned, the resources are sensitive and errors of rendering
+// them are hidden as the rendered output may contain the values.
+func (r *ResourceGenerator) generateFromResourceGroup(resourceGroup corev1alpha1.BlueprintResourceGroup, blueprint corev1alpha1.Blueprint, resourceGroups []ResourceGroup) ([]*unstructured.Unstructured, []corev1alpha1.ResourceTemplateOutput, *corev1alpha1.ResourceSource, error) {
 	rtRef := types.NamespacedName{
 		Namespace: config.Operator.Namespace,
 		Name:      resourceGroup.Template,
 	}
-	rt, err := r.getResourceTemplate(r.ctx, rtRef)
+	rt, revision, err := r.getResourceTemplate(r.ctx, rtRef, resourceGroup.TemplateRevision)
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
 
-	resolver := ValueResolver{
-		TenantName:        r.state.TenantPrefixedName,
-		TenantNamespace:   r.state.TenantPrefixedNamespace,
-		OperatorNamespace: config.Operator.Namespace,
-		ResourceGroups:    resourceGroups,
-		Client:            r.services.Client,
-		Context:           r.ctx,
-	}
+	resolver := r.valueResolver(resourceGroups)
 
 	r.ctx.Log.V(1).Info("applying parameter overrides")
+	err = rt.Spec.Parameters.SetValues(r.state.Parameters, resolver.Func)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
 	err = rt.Spec.Parameters.SetValues(resourceGroup.Parameters, resolver.Func)
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
 
 	err = rt.Spec.Parameters.Validate()
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
 
-	templateData := r.newTemplateData(blueprint, rt.Spec.Parameters)
-
-	r.ctx.Log.V(1).Info("building resources from resource template", "template", resourceGroup.Template)
-	allResources := make([]*unstructured.Unstructured, 0)
+	params, err := rt.Spec.Parameters.TypedValues()
+	if err != nil {
+		return nil, nil, nil, err
+	}
 
-	for _, raw := range rt.Spec.Raw {
-		resources, err := r.generateUnstructureResources(raw, templateData)
-		if err != nil {
-			return nil, err
+	templateData := r.newTemplateData(blueprint, rt.Spec.Parameters, params)
+	sensitive := rt.Spec.Parameters.Sensitive()
+	renderError := func(err error) error {
+		if sensitive {
+			return fmt.Errorf("failed to render resource template %s, the error is hidden as the template references secret parameters", rt.Name)
 		}
-		allResources = append(allResources, resources...)
+		return err
 	}
 
-	for _, resource := range rt.Spec.Resources {
-		resources, err := r.generateUnstructureResources(string(resource.Raw), templateData)
+	r.ctx.Log.V(1).Info("building resources from resource template", "template", resourceGroup.Template)
+	documents := templateDocuments(rt.Spec)
+	allResources, err := r.render(rt.Name, documents, rt.Spec.Helm, revision, templateData)
+	if err != nil {
+		return nil, nil, nil, renderError(err)
+	}
+
+	var source *corev1alpha1.ResourceSource
+	if sensitive {
+		source, err = r.resourceSource(rt, revision, documents, resolver, r.state.Parameters, resourceGroup.Parameters)
 		if err != nil {
-			return nil, err
+			return nil, nil, nil, err
 		}
-		allResources = append(allResources, resources...)
 	}
 
 	r.ctx.Log.V(1).Info("applying name, namespace and common labels/annotations to resources", "template", resourceGroup.Template)
@@ -228,23 +380,306 @@ func (r *ResourceGenerator) generateFromResourceGroup(resourceGroup corev1alpha1
 			resource.SetNamespace(resourceNamespace)
 		}
 
+		retainPolicy := retainPolicy(resource.GetAnnotations(), rt.Annotations, resourceGroup.RetainPolicy)
+
 		// TODO: Override existing labels and annotations if they exist?
 		labels := merge(resource.GetLabels(), r.state.Labels)
 		annotations := merge(resource.GetAnnotations(), r.state.Annotations)
+		if retainPolicy != "" {
+			annotations[corev1alpha1.AnnotationRetainPolicy] = string(retainPolicy)
+		}
 		resource.SetLabels(labels)
 		resource.SetAnnotations(annotations)
 
 		var content interface{} = resource.UnstructuredContent()
-		if util.SliceContainsString(config.NonLoggableKinds(), resource.GetKind()) {
+		if sensitive || util.SliceContainsString(config.NonLoggableKinds(), resource.GetKind()) {
 			content = resource.GroupVersionKind().String()
 		}
 		r.ctx.Log.V(1).Info("all changes applied to resource", "template", resourceGroup.Template, "resource", content)
 	}
 
+	return allResources, rt.Spec.Outputs, source, nil
+}
+
+// render renders the templates and helm chart of a resource template
+func (r *ResourceGenerator) render(name string, documents []string, helm *corev1alpha1.HelmChartSource, revision string, templateData template.Data) ([]*unstructured.Unstructured, error) {
+	allResources := make([]*unstructured.Unstructured, 0)
+
+	for _, document := range documents {
+		resources, err := r.generateUnstructureResources(document, templateData)
+		if err != nil {
+			return nil, err
+		}
+		allResources = append(allResources, resources...)
+	}
+
+	if helm != nil {
+		resources, err := r.generateFromHelmChart(*helm, revision, templateData)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate resources from helm chart of template %s, %v", name, err)
+		}
+		allResources = append(allResources, resources...)
+	}
+
 	return allResources, nil
 }
 
-func (r *ResourceGenerator) newTemplateData(blueprint corev1alpha1.Blueprint, parameters []*corev1alpha1.Parameter) template.Data {
+// resourceSource returns the source sensitive resources of the template are rendered from when applied. The values of
+// parameters read from Secrets or generated are replaced with references to the Secret keys holding them, values are
+// applied in order as when generating the resources.
+func (r *ResourceGenerator) resourceSource(rt corev1alpha1.ResourceTemplate, revision string, documents []string, resolver ValueResolver, values ...[]corev1alpha1.ParameterValue) (*corev1alpha1.ResourceSource, error) {
+	markers := make(map[string]string)
+	for _, pvs := range values {
+		for _, pv := range pvs {
+			delete(markers, pv.Name)
+			if pv.Value == "" && pv.ValueFrom != nil {
+				if ref, ok := resolver.Reference(*pv.ValueFrom); ok {
+					markers[pv.Name] = ref.Marker()
+				}
+			}
+		}
+	}
+
+	pvs := make([]corev1alpha1.ParameterValue, 0)
+	for _, p := range rt.Spec.Parameters {
+		if marker, ok := markers[p.Name]; ok {
+			pvs = append(pvs, corev1alpha1.ParameterValue{Name: p.Name, Value: marker})
+			continue
+		}
+		value, err := p.Value()
+		if err != nil {
+			return nil, err
+		}
+		pvs = append(pvs, corev1alpha1.ParameterValue{Name: p.Name, Value: value})
+	}
+
+	return &corev1alpha1.ResourceSource{
+		Template:  rt.Name,
+		Revision:  revision,
+		Documents: documents,
+		Helm:      rt.Spec.Helm.DeepCopy(),
+		Tenant: corev1alpha1.ResourceSourceTenant{
+			Name:              r.state.TenantName,
+			PrefixedName:      r.state.TenantPrefixedName,
+			FullName:          r.state.TenantFullName,
+			Namespace:         r.state.TenantPrefixedNamespace,
+			OperatorNamespace: config.Operator.Namespace,
+			Labels:            r.state.Labels,
+			Annotations:       r.state.Annotations,
+		},
+		Parameters: rt.Spec.Parameters.DeepCopy(),
+		Values:     pvs,
+	}, nil
+}
+
+// RenderResource renders a sensitive resource from its source, resolving the values of the Secrets referenced by the
+// source. The name, namespace, labels and annotations of the stored resource are applied to the rendered resource.
+func RenderResource(ctx reconcile.Context, services ResourceGeneratoreServices, stored *unstructured.Unstructured, source corev1alpha1.ResourceSource) (*unstructured.Unstructured, error) {
+	r := NewResourceGenerator(ctx, services)
+	r.state = State{
+		TenantName:              source.Tenant.Name,
+		TenantFullName:          source.Tenant.FullName,
+		TenantPrefixedName:      source.Tenant.PrefixedName,
+		TenantPrefixedNamespace: source.Tenant.Namespace,
+		Labels:                  source.Tenant.Labels,
+		Annotations:             source.Tenant.Annotations,
+	}
+
+	values := make([]corev1alpha1.ParameterValue, 0)
+	for _, pv := range source.Values {
+		if ref, ok := kubernetes.ParseSecretReference(pv.Value); ok {
+			values = append(values, corev1alpha1.ParameterValue{
+				Name: pv.Name,
+				ValueFrom: &corev1alpha1.ValueRef{
+					SecretKeyRef: &corev1alpha1.KeyValueRef{Namespace: ref.Namespace, Name: ref.Name, Key: ref.Key},
+				},
+			})
+			continue
+		}
+		values = append(values, pv)
+	}
+
+	// values are validated when generated, all parameters have a value and none are required
+	parameters := source.Parameters.DeepCopy()
+	for _, p := range parameters {
+		p.Required = false
+	}
+
+	resolver := ValueResolver{
+		OperatorNamespace: source.Tenant.OperatorNamespace,
+		Client:            services.Client,
+		Context:           ctx,
+	}
+	if err := parameters.SetValues(values, resolver.Func); err != nil {
+		return nil, err
+	}
+	params, err := parameters.TypedValues()
+	if err != nil {
+		return nil, err
+	}
+
+	templateData := template.Data{
+		Name:         source.Tenant.Name,
+		PrefixedName: source.Tenant.PrefixedName,
+		FullName:     source.Tenant.FullName,
+		Namespaces: template.Namespaces{
+			Tenant:   source.Tenant.Namespace,
+			Operator: source.Tenant.OperatorNamespace,
+		},
+		Labels:      source.Tenant.Labels,
+		Annotations: source.Tenant.Annotations,
+		Parameters:  parameters,
+		Params:      params,
+		Utils:       template.UtilityFunctions{},
+	}
+
+	resources, err := r.render(source.Template, source.Documents, source.Helm, source.Revision, templateData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render resource template %s, the error is hidden as the template references secret parameters", source.Template)
+	}
+	if source.Index >= len(resources) || resources[source.Index].GroupVersionKind() != stored.GroupVersionKind() {
+		return nil, fmt.Errorf("resource template %s no longer renders %s %s at position %d", source.Template, stored.GetKind(), stored.GetName(), source.Index)
+	}
+
+	resource := resources[source.Index]
+	resource.SetName(stored.GetName())
+	resource.SetNamespace(stored.GetNamespace())
+	resource.SetLabels(merge(resource.GetLabels(), stored.GetLabels()))
+	resource.SetAnnotations(merge(resource.GetAnnotations(), stored.GetAnnotations()))
+	return resource, nil
+}
+
+// templateDocuments returns the raw templates and resources of a resource template, in order
+func templateDocuments(spec corev1alpha1.ResourceTemplateSpec) []string {
+	documents := make([]string, 0)
+	documents = append(documents, spec.Raw...)
+	for _, resource := range spec.Resources {
+		documents = append(documents, string(resource.Raw))
+	}
+	return documents
+}
+
+// sensitivePlaceholder returns the stored form of a sensitive resource, its kind, name, namespace and the common labels
+// and annotations of the tenant. Labels and annotations set by the template are left out as they may hold secret values.
+func sensitivePlaceholder(resource *unstructured.Unstructured, state State) ([]byte, error) {
+	placeholder := &unstructured.Unstructured{}
+	placeholder.SetAPIVersion(resource.GetAPIVersion())
+	placeholder.SetKind(resource.GetKind())
+	placeholder.SetName(resource.GetName())
+	if namespace := resource.GetNamespace(); namespace != "" {
+		placeholder.SetNamespace(namespace)
+	}
+
+	if len(state.Labels) > 0 {
+		placeholder.SetLabels(merge(nil, state.Labels))
+	}
+
+	annotations := merge(nil, state.Annotations)
+	if policy, ok := resource.GetAnnotations()[corev1alpha1.AnnotationRetainPolicy]; ok {
+		annotations[corev1alpha1.AnnotationRetainPolicy] = policy
+	}
+	if len(annotations) > 0 {
+		placeholder.SetAnnotations(annotations)
+	}
+
+	return placeholder.MarshalJSON()
+}
+
+// waitingFor returns the dependencies of the resource group that are not ready. Dependencies skipped by their condition
+// are ready, dependencies not generated are not ready and generated dependencies are ready once all of their resources
+// exist and none of them reports that it is not ready.
+func (r *ResourceGenerator) waitingFor(resourceGroup corev1alpha1.BlueprintResourceGroup, resourceGroups ResourceGroupList, skipped map[string]bool) []string {
+	waiting := make([]string, 0)
+	for _, name := range resourceGroup.DependsOn {
+		if skipped[name] {
+			continue
+		}
+
+		var dependency *ResourceGroup
+		for i := range resourceGroups {
+			if resourceGroups[i].Name == name {
+				dependency = &resourceGroups[i]
+				break
+			}
+		}
+		if dependency == nil || !r.resourcesReady(dependency.Resources) {
+			waiting = append(waiting, name)
+		}
+	}
+	return waiting
+}
+
+// resourcesReady returns true when all resources exist and none of them reports that it is not ready
+func (r *ResourceGenerator) resourcesReady(resources ResourceList) bool {
+	for _, resource := range resources {
+		ri, err := resource.ResourceIdentifier()
+		if err != nil {
+			return false
+		}
+
+		live, err := r.services.Client.DynamicGet(r.ctx, ri.NamespacedName, ri.GroupVersionKind)
+		if err != nil {
+			r.ctx.Log.V(1).Info("failed to check resource readiness", "nn", ri.NamespacedName.String(), "gvk", ri.GroupVersionKind.String(), "error", err.Error())
+			return false
+		}
+		if live == nil {
+			return false
+		}
+
+		if checked, ready, _ := kubernetes.ResourceReady(live); checked && !ready {
+			return false
+		}
+	}
+	return true
+}
+
+// conditionMatches evaluates the condition of a resource group against the labels, annotations and parameter values of the tenant,
+// only the parameters referenced by the condition are resolved
+func (r *ResourceGenerator) conditionMatches(condition corev1alpha1.BlueprintResourceGroupCondition, resourceGroups []ResourceGroup) (bool, error) {
+	resolver := r.valueResolver(resourceGroups)
+
+	referenced := make(map[string]bool)
+	for _, name := range condition.ParameterNames() {
+		referenced[name] = true
+	}
+
+	parameters := make(map[string]string)
+	for _, pv := range r.state.Parameters {
+		if !referenced[pv.Name] {
+			continue
+		}
+
+		value := pv.Value
+		if value == "" && pv.ValueFrom != nil {
+			v, err := resolver.Func(*pv.ValueFrom)
+			if err != nil {
+				return false, err
+			}
+			value = v
+		}
+		if value != "" {
+			parameters[pv.Name] = value
+		}
+	}
+
+	return condition.Matches(r.state.TenantLabels, r.state.TenantAnnotations, parameters)
+}
+
+// valueResolver returns the resolver of parameter value references
+func (r *ResourceGenerator) valueResolver(resourceGroups []ResourceGroup) ValueResolver {
+	return ValueResolver{
+		TenantName:        r.state.TenantPrefixedName,
+		TenantNamespace:   r.state.TenantPrefixedNamespace,
+		OperatorNamespace: config.Operator.Namespace,
+		ResourceGroups:    resourceGroups,
+		Client:            r.services.Client,
+		Context:           r.ctx,
+		Secrets:           r.secretValues(),
+		Generated:         r.generatedValues(),
+	}
+}
+
+func (r *ResourceGenerator) newTemplateData(blueprint corev1alpha1.Blueprint, parameters []*corev1alpha1.Parameter, params map[string]interface{}) template.Data {
 	return template.Data{
 		Name:         r.state.TenantName,
 		PrefixedName: r.state.TenantPrefixedName,
@@ -256,19 +691,93 @@ func (r *ResourceGenerator) newTemplateData(blueprint corev1alpha1.Blueprint, pa
 		Labels:      r.state.Labels,
 		Annotations: r.state.Annotations,
 		Parameters:  parameters,
+		Params:      params,
 		Utils:       template.UtilityFunctions{},
 	}
 }
 
-func (r *ResourceGenerator) getResourceTemplate(ctx reconcile.Context, nn types.NamespacedName) (corev1alpha1.ResourceTemplate, error) {
-	var rt corev1alpha1.ResourceTemplate
-	if err := r.services.Client.Get(ctx, client.ObjectKey{
+// generatedValues returns the store of generated values of the tenant
+func (r *ResourceGenerator) generatedValues() *GeneratedValueStore {
+	if r.generated == nil {
+		r.generated = NewGeneratedValueStore(r.ctx, r.services.Client, types.NamespacedName{
+			Namespace: r.state.TenantNamespace,
+			Name:      r.state.TenantName,
+		})
+		r.generated.dryRun = r.dryRun
+	}
+	return r.generated
+}
+
+// secretValues returns the values read from secrets while generating resources
+func (r *ResourceGenerator) secretValues() kubernetes.SecretValues {
+	if r.secrets == nil {
+		r.secrets = make(kubernetes.SecretValues)
+	}
+	return r.secrets
+}
+
+// resourceSum returns the sum of a resource including the values of the secrets it references, resources referencing
+// secrets are replaced when the values change, e.g. when generated values are rotated
+func (r *ResourceGenerator) resourceSum(bytes []byte) string {
+	content := string(bytes)
+	for _, ref := range r.secrets.Referenced(content) {
+		content += fmt.Sprintf("\n%s=%s", ref.String(), r.secrets[ref])
+	}
+	return util.Sha256Sum([]byte(content))
+}
+
+// getResourceTemplate returns the resource template, or the snapshot of the resource template when pinned to a revision, and records and returns the revision used
+func (r *ResourceGenerator) getResourceTemplate(ctx reconcile.Context, nn types.NamespacedName, revision string) (corev1alpha1.ResourceTemplate, string, error) {
+	if revision != "" {
+		var rtr corev1alpha1.ResourceTemplateRevision
+		if err := r.services.Client.Get(ctx, client.ObjectKey{
+			Name:      revision,
+			Namespace: nn.Namespace,
+		}, &rtr); err != nil {
+			return corev1alpha1.ResourceTemplate{}, "", err
+		}
+		if rtr.Spec.ResourceTemplate != nn.Name {
+			return corev1alpha1.ResourceTemplate{}, "", fmt.Errorf("revision %s is a revision of resource template %s, not %s", revision, rtr.Spec.ResourceTemplate, nn.Name)
+		}
+		if err := VerifyRevision(rtr.Name, rtr.Spec.Hash, rtr.Spec.Snapshot); err != nil {
+			return corev1alpha1.ResourceTemplate{}, "", err
+		}
+
+		r.addRevision(rtr.Name)
+		return corev1alpha1.ResourceTemplate{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        nn.Name,
+				Namespace:   nn.Namespace,
+				Annotations: rtr.Annotations,
+			},
+			Spec: *rtr.Spec.Snapshot.DeepCopy(),
+		}, rtr.Name, nil
+	}
+
+	rt, ok := r.templates[nn]
+	if ok {
+		rt = *rt.DeepCopy()
+	} else if err := r.services.Client.Get(ctx, client.ObjectKey{
 		Name:      nn.Name,
 		Namespace: nn.Namespace,
 	}, &rt); err != nil {
-		return corev1alpha1.ResourceTemplate{}, err
+		return corev1alpha1.ResourceTemplate{}, "", err
+	}
+
+	hash, err := RevisionHash(rt.Spec)
+	if err != nil {
+		return corev1alpha1.ResourceTemplate{}, "", err
+	}
+	revision = RevisionName(rt.Name, hash)
+	r.addRevision(revision)
+
+	return rt, revision, nil
+}
+
+func (r *ResourceGenerator) addRevision(revision string) {
+	if !util.SliceContainsString(r.revisions, revision) {
+		r.revisions = append(r.revisions, revision)
 	}
-	return rt, nil
 }
 
 func (r *ResourceGenerator) generateUnstructureResources(json string, templateData template.Data) ([]*unstructured.Unstructured, error) {
@@ -301,6 +810,168 @@ func (r *ResourceGenerator) generateUnstructureResources(json string, templateDa
 	return allResources, nil
 }
 
+// generateFromHelmChart renders a helm chart with the values of the chart source, the values are templated using the
+// template data of the resource group
+func (r *ResourceGenerator) generateFromHelmChart(source corev1alpha1.HelmChartSource, revision string, templateData template.Data) ([]*unstructured.Unstructured, error) {
+	archive, err := r.helmChartArchive(source, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	chart, err := helm.LoadArchive(archive)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]interface{})
+	if source.Values != "" {
+		tmpl, err := template.NewYamlTemplate(source.Values, template.InputFormatYaml)
+		if err != nil {
+			return nil, err
+		}
+		templated, err := tmpl.Execute(templateData)
+		if err != nil {
+			return nil, err
+		}
+		if err := yaml.Unmarshal([]byte(templated), &values); err != nil {
+			return nil, fmt.Errorf("invalid values, %v", err)
+		}
+	}
+
+	releaseName := source.ReleaseName
+	if releaseName == "" {
+		releaseName = r.state.TenantPrefixedName
+	}
+
+	r.ctx.Log.V(1).Info("rendering helm chart", "chart", chart.Name(), "version", chart.Metadata.Version, "release", releaseName)
+	res, err := helm.Render(chart, helm.RenderOptions{
+		ReleaseName: releaseName,
+		Namespace:   r.state.TenantPrefixedNamespace,
+		Values:      values,
+		IncludeCRDs: source.IncludeCRDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(res.SkippedHooks) > 0 {
+		r.ctx.Log.V(1).Info("skipping helm hooks of chart", "chart", chart.Name(), "hooks", res.SkippedHooks)
+	}
+
+	resources := make([]*unstructured.Unstructured, 0)
+	for _, manifest := range res.Manifests {
+		docs, err := convert.YamlToStringSlice(manifest)
+		if err != nil {
+			return nil, err
+		}
+		for _, doc := range docs {
+			u, err := convert.YamlToUnstructuredSlice(doc)
+			if err != nil {
+				return nil, err
+			}
+			resources = append(resources, u...)
+		}
+	}
+
+	return resources, nil
+}
+
+// helmChartArchive returns the packaged chart from a ConfigMap in the operator namespace or a chart repository. Charts
+// from a repository are read from the snapshot of the template revision, and only fetched from the repository when the
+// revision has no snapshot of the chart.
+func (r *ResourceGenerator) helmChartArchive(source corev1alpha1.HelmChartSource, revision string) ([]byte, error) {
+	if source.ConfigMap != nil && source.Repository != nil {
+		return nil, fmt.Errorf("invalid helm chart source, configMap and repository are mutually exclusive")
+	}
+
+	if source.ConfigMap != nil {
+		nn := types.NamespacedName{
+			Namespace: config.Operator.Namespace,
+			Name:      source.ConfigMap.Name,
+		}
+		var cm corev1.ConfigMap
+		if err := r.services.Client.Get(r.ctx, nn, &cm); err != nil {
+			return nil, fmt.Errorf("failed to get chart configmap %s, %v", nn.String(), err)
+		}
+		if archive, ok := cm.BinaryData[source.ConfigMap.Key]; ok {
+			return archive, nil
+		}
+		if encoded, ok := cm.Data[source.ConfigMap.Key]; ok {
+			return base64.StdEncoding.DecodeString(encoded)
+		}
+		return nil, fmt.Errorf("key %s not found in chart configmap %s", source.ConfigMap.Key, nn.String())
+	}
+
+	if source.Repository != nil {
+		nn := types.NamespacedName{
+			Namespace: config.Operator.Namespace,
+			Name:      ChartArchiveName(revision),
+		}
+		var cm corev1.ConfigMap
+		if err := r.services.Client.Get(r.ctx, nn, &cm); err == nil {
+			if archive, ok := cm.BinaryData[ChartArchiveKey]; ok {
+				return archive, nil
+			}
+		} else if !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to get chart snapshot %s, %v", nn.String(), err)
+		}
+		return helm.FetchArchive(source.Repository.URL, source.Repository.Chart, source.Repository.Version)
+	}
+
+	return nil, fmt.Errorf("invalid helm chart source, configMap or repository is required")
+}
+
+// retainPolicy returns the first retain policy set on the resource, the template or the resource group
+func retainPolicy(resourceAnnotations map[string]string, templateAnnotations map[string]string, groupPolicy corev1alpha1.RetainPolicy) corev1alpha1.RetainPolicy {
+	if p, ok := resourceAnnotations[corev1alpha1.AnnotationRetainPolicy]; ok && p != "" {
+		return corev1alpha1.RetainPolicy(p)
+	}
+	if p, ok := templateAnnotations[corev1alpha1.AnnotationRetainPolicy]; ok && p != "" {
+		return corev1alpha1.RetainPolicy(p)
+	}
+	return groupPolicy
+}
+
+// newOutput creates an output reading the value from the first resource matching the kind of the template output
+func newOutput(output corev1alpha1.ResourceTemplateOutput, resources ResourceList) (Output, error) {
+	source := output.Source
+	if source == "" {
+		source = corev1alpha1.OutputSourceRendered
+	}
+
+	for _, resource := range resources {
+		u, err := convert.RawExtensionToUnstructured(resource.Embedded.RawExtension)
+		if err != nil {
+			return Output{}, err
+		}
+		if u.GetKind() == output.Kind {
+			o := Output{
+				Name:       output.Name,
+				ResourceId: resource.Id,
+				Source:     string(source),
+				JsonPath:   output.JsonPath,
+			}
+			if err := o.Validate(resource); err != nil {
+				return Output{}, err
+			}
+			return o, nil
+		}
+	}
+
+	return Output{}, fmt.Errorf("no resource of kind %s found", output.Kind)
+}
+
+// retainAdopted sets the retain policy of an adopted resource unless a policy has been configured
+func retainAdopted(resource *unstructured.Unstructured) {
+	annotations := resource.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	if _, ok := annotations[corev1alpha1.AnnotationRetainPolicy]; !ok {
+		annotations[corev1alpha1.AnnotationRetainPolicy] = string(corev1alpha1.RetainPolicyRetain)
+		resource.SetAnnotations(annotations)
+	}
+}
+
 func merge(m1 map[string]string, m2 map[string]string) map[string]string {
 	m := map[string]string{}
 	for k, v := range m1 {
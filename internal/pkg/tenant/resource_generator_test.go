@@ -0,0 +1,217 @@
+package tenant
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	corev1alpha1 "github.com/kristofferahl/aeto/apis/core/v1alpha1"
+	"github.com/kristofferahl/aeto/internal/pkg/config"
+	"github.com/kristofferahl/aeto/internal/pkg/convert"
+	"github.com/kristofferahl/aeto/internal/pkg/kubernetes"
+	"github.com/kristofferahl/aeto/internal/pkg/reconcile"
+)
+
+var _ = Describe("ResourceGenerator", func() {
+	Describe("sensitive resources", func() {
+		var (
+			ctx       reconcile.Context
+			c         client.Client
+			services  ResourceGeneratoreServices
+			secret    *corev1.Secret
+			state     State
+			blueprint corev1alpha1.Blueprint
+		)
+
+		generate := func() Resource {
+			g := NewResourceGenerator(ctx, services).DryRun()
+			res, err := g.Generate(state, blueprint)
+			Expect(err).NotTo(HaveOccurred())
+			resources := res.ResourceGroups.Resources()
+			Expect(resources).To(HaveLen(1))
+			return resources[0]
+		}
+
+		render := func(r Resource) map[string]interface{} {
+			Expect(r.Source).NotTo(BeNil())
+			stored, err := convert.RawExtensionToUnstructured(r.Embedded.RawExtension)
+			Expect(err).NotTo(HaveOccurred())
+			rendered, err := RenderResource(ctx, services, stored, *r.Source)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rendered.GetName()).To(Equal("app"))
+			Expect(rendered.GetNamespace()).To(Equal("acme"))
+			Expect(rendered.GetLabels()).To(HaveKeyWithValue("aeto.net/tenant", "acme"))
+			return rendered.Object["data"].(map[string]interface{})
+		}
+
+		BeforeEach(func() {
+			config.Operator.Namespace = "aeto"
+
+			scheme := runtime.NewScheme()
+			Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+			Expect(corev1alpha1.AddToScheme(scheme)).To(Succeed())
+
+			secret = &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "aeto", Name: "database"},
+				Data:       map[string][]byte{"password": []byte("admin")},
+			}
+			rt := &corev1alpha1.ResourceTemplate{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "aeto", Name: "app"},
+				Spec: corev1alpha1.ResourceTemplateSpec{
+					Rules: corev1alpha1.ResourceTemplateRules{
+						Name:      corev1alpha1.ResourceNameKeep,
+						Namespace: corev1alpha1.ResourceNamespaceTenant,
+					},
+					Parameters: corev1alpha1.ResourceTemplateParameterList{
+						{Name: "password", Type: corev1alpha1.ParameterTypeString},
+					},
+					Raw: []string{`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app
+data:
+  user: cluster-admin
+  password: "{{ .Params.password }}"
+  upper: "{{ .Params.password | upper }}"
+`},
+				},
+			}
+			c = fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret, rt).Build()
+			ctx = reconcile.NewContext("test", ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "aeto", Name: "acme"}}, logf.Log)
+			services = ResourceGeneratoreServices{Client: kubernetes.NewClient(c, nil, nil)}
+
+			state = State{
+				TenantName:              "acme",
+				TenantNamespace:         "aeto",
+				TenantFullName:          "Acme",
+				TenantPrefixedName:      "acme",
+				TenantPrefixedNamespace: "acme",
+				Labels:                  map[string]string{"aeto.net/tenant": "acme"},
+			}
+			blueprint = corev1alpha1.Blueprint{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "aeto", Name: "default"},
+				Spec: corev1alpha1.BlueprintSpec{
+					Resources: []corev1alpha1.BlueprintResourceGroup{
+						{
+							Name:     "app",
+							Template: "app",
+							Parameters: []corev1alpha1.ParameterValue{
+								{Name: "password", ValueFrom: &corev1alpha1.ValueRef{SecretKeyRef: &corev1alpha1.KeyValueRef{Namespace: "aeto", Name: "database", Key: "password"}}},
+							},
+						},
+					},
+				},
+			}
+		})
+
+		It("should store a placeholder and the source of the resource", func() {
+			r := generate()
+			Expect(r.Sensitive).To(BeTrue())
+			Expect(string(r.Embedded.Raw)).NotTo(ContainSubstring("admin"))
+			Expect(string(r.Embedded.Raw)).NotTo(ContainSubstring("ADMIN"))
+			Expect(r.Source).NotTo(BeNil())
+			Expect(r.Source.Template).To(Equal("app"))
+			Expect(r.Source.Values).To(ConsistOf(corev1alpha1.ParameterValue{Name: "password", Value: "$(aeto:secret:aeto/database/password)"}))
+		})
+
+		It("should render the resource with the values of the referenced Secret", func() {
+			data := render(generate())
+			Expect(data).To(Equal(map[string]interface{}{
+				"user":     "cluster-admin",
+				"password": "admin",
+				"upper":    "ADMIN",
+			}))
+		})
+
+		It("should render the rotated value of the referenced Secret", func() {
+			r := generate()
+
+			secret.Data["password"] = []byte("s3cr3t")
+			Expect(c.Update(ctx.Context, secret)).To(Succeed())
+
+			Expect(render(r)).To(Equal(map[string]interface{}{
+				"user":     "cluster-admin",
+				"password": "s3cr3t",
+				"upper":    "S3CR3T",
+			}))
+			Expect(generate().Sum).NotTo(Equal(r.Sum))
+		})
+
+		It("should fail when the template no longer renders the resource", func() {
+			r := generate()
+			r.Source.Index = 1
+			stored, err := convert.RawExtensionToUnstructured(r.Embedded.RawExtension)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = RenderResource(ctx, services, stored, *r.Source)
+			Expect(err).To(MatchError("resource template app no longer renders ConfigMap app at position 1"))
+		})
+	})
+
+	DescribeTable("retainPolicy",
+		func(resource map[string]string, template map[string]string, group corev1alpha1.RetainPolicy, expected corev1alpha1.RetainPolicy) {
+			Expect(retainPolicy(resource, template, group)).To(Equal(expected))
+		},
+		Entry("no policy", nil, nil, corev1alpha1.RetainPolicy(""), corev1alpha1.RetainPolicy("")),
+		Entry("policy of the resource group", nil, nil, corev1alpha1.RetainPolicyRetain, corev1alpha1.RetainPolicyRetain),
+		Entry("template overrides the resource group", nil, map[string]string{corev1alpha1.AnnotationRetainPolicy: "Delete"}, corev1alpha1.RetainPolicyRetain, corev1alpha1.RetainPolicyDelete),
+		Entry("resource overrides the template", map[string]string{corev1alpha1.AnnotationRetainPolicy: "Retain"}, map[string]string{corev1alpha1.AnnotationRetainPolicy: "Delete"}, corev1alpha1.RetainPolicyDelete, corev1alpha1.RetainPolicyRetain),
+		Entry("empty annotations are ignored", map[string]string{corev1alpha1.AnnotationRetainPolicy: ""}, map[string]string{corev1alpha1.AnnotationRetainPolicy: ""}, corev1alpha1.RetainPolicyRetain, corev1alpha1.RetainPolicyRetain),
+	)
+
+	Describe("retained resources", func() {
+		generate := func(group corev1alpha1.RetainPolicy, annotations map[string]string) Resource {
+			config.Operator.Namespace = "aeto"
+
+			scheme := runtime.NewScheme()
+			Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+			Expect(corev1alpha1.AddToScheme(scheme)).To(Succeed())
+
+			rt := &corev1alpha1.ResourceTemplate{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "aeto", Name: "app", Annotations: annotations},
+				Spec: corev1alpha1.ResourceTemplateSpec{
+					Rules: corev1alpha1.ResourceTemplateRules{
+						Name:      corev1alpha1.ResourceNameKeep,
+						Namespace: corev1alpha1.ResourceNamespaceTenant,
+					},
+					Raw: []string{"apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\n"},
+				},
+			}
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(rt).Build()
+			ctx := reconcile.NewContext("test", ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "aeto", Name: "acme"}}, logf.Log)
+			g := NewResourceGenerator(ctx, ResourceGeneratoreServices{Client: kubernetes.NewClient(c, nil, nil)}).DryRun()
+
+			res, err := g.Generate(State{TenantName: "acme", TenantPrefixedNamespace: "acme"}, corev1alpha1.Blueprint{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "aeto", Name: "default"},
+				Spec: corev1alpha1.BlueprintSpec{
+					Resources: []corev1alpha1.BlueprintResourceGroup{{Name: "app", Template: "app", RetainPolicy: group}},
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			resources := res.ResourceGroups.Resources()
+			Expect(resources).To(HaveLen(1))
+			return resources[0]
+		}
+
+		DescribeTable("Retained",
+			func(group corev1alpha1.RetainPolicy, annotations map[string]string, expected bool) {
+				retained, err := generate(group, annotations).Retained()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(retained).To(Equal(expected))
+			},
+			Entry("deleted by default", corev1alpha1.RetainPolicy(""), nil, false),
+			Entry("retained by the resource group", corev1alpha1.RetainPolicyRetain, nil, true),
+			Entry("retained by the template", corev1alpha1.RetainPolicy(""), map[string]string{corev1alpha1.AnnotationRetainPolicy: "Retain"}, true),
+			Entry("deleted by the template", corev1alpha1.RetainPolicyRetain, map[string]string{corev1alpha1.AnnotationRetainPolicy: "Delete"}, false),
+		)
+	})
+})
@@ -0,0 +1,84 @@
+package tenant
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+
+	"github.com/kristofferahl/aeto/internal/pkg/convert"
+	"github.com/kristofferahl/aeto/internal/pkg/kubernetes"
+	"github.com/kristofferahl/aeto/internal/pkg/reconcile"
+	"github.com/kristofferahl/aeto/internal/pkg/util"
+)
+
+const (
+	maxDiffEntries = 20
+)
+
+// ExistingResource describes a pre-existing resource, not managed by aeto, that matches a generated resource
+type ExistingResource struct {
+	Kind      string
+	Namespace string
+	Name      string
+	Diff      []string
+}
+
+func NewResourceInspector(ctx reconcile.Context, services ResourceGeneratoreServices) ResourceInspector {
+	return ResourceInspector{
+		ctx:      ctx,
+		services: services,
+	}
+}
+
+type ResourceInspector struct {
+	ctx      reconcile.Context
+	services ResourceGeneratoreServices
+}
+
+// Existing returns the pre-existing resource matching the generated resource or nil when there is none
+func (i ResourceInspector) Existing(r Resource) (*ExistingResource, error) {
+	desired, err := convert.RawExtensionToUnstructured(r.Embedded.RawExtension)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Source != nil {
+		desired, err = RenderResource(i.ctx, i.services, desired, *r.Source)
+		if err != nil {
+			return nil, err
+		}
+	} else if err := i.services.ResolveSecretReferences(i.ctx, desired.Object); err != nil {
+		return nil, err
+	}
+
+	ri, err := r.ResourceIdentifier()
+	if err != nil {
+		return nil, err
+	}
+
+	live, err := i.services.DynamicGet(i.ctx, ri.NamespacedName, ri.GroupVersionKind)
+	if meta.IsNoMatchError(err) {
+		// the kind is not served, e.g. the CRD is created by the same resource set, no resource exists to adopt
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if live == nil {
+		return nil, nil
+	}
+
+	for _, mf := range live.GetManagedFields() {
+		if mf.Manager == kubernetes.FieldManagerName {
+			// The resource is already managed by aeto
+			return nil, nil
+		}
+	}
+
+	diff := util.LimitStrings(util.DiffFields(desired.Object, desired.Object, live.Object), maxDiffEntries)
+
+	return &ExistingResource{
+		Kind:      desired.GetKind(),
+		Namespace: desired.GetNamespace(),
+		Name:      desired.GetName(),
+		Diff:      diff,
+	}, nil
+}
@@ -0,0 +1,72 @@
+package tenant
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/kristofferahl/aeto/internal/pkg/util"
+)
+
+const (
+	revisionHashLength = 10
+
+	// ChartArchiveKey is the key of the packaged chart in the ConfigMap holding the chart of a ResourceTemplateRevision
+	ChartArchiveKey = "chart.tgz"
+)
+
+// RevisionHash returns the hash of a Blueprint or ResourceTemplate spec
+func RevisionHash(spec interface{}) (string, error) {
+	return util.AsSha256(spec)
+}
+
+// RevisionName returns the name of the revision of a Blueprint or ResourceTemplate with the specified hash
+func RevisionName(name string, hash string) string {
+	if len(hash) > revisionHashLength {
+		hash = hash[:revisionHashLength]
+	}
+	return fmt.Sprintf("%s-%s", name, hash)
+}
+
+// ChartArchiveName returns the name of the ConfigMap holding the packaged helm chart of a ResourceTemplateRevision
+func ChartArchiveName(revision string) string {
+	return fmt.Sprintf("%s-chart", revision)
+}
+
+// VerifyRevision returns an error when the snapshot of a revision no longer matches the hash it was created with,
+// revisions are immutable
+func VerifyRevision(name string, hash string, snapshot interface{}) error {
+	actual, err := RevisionHash(snapshot)
+	if err != nil {
+		return err
+	}
+	if actual != hash {
+		return fmt.Errorf("revision %s has been modified, the snapshot no longer matches hash %s", name, hash)
+	}
+	return nil
+}
+
+// ExpiredRevisions returns the names of the revisions, keyed by name with their sequence number, beyond the history
+// limit. The newest revisions and the revisions to keep are never expired, nothing expires when the limit is zero.
+func ExpiredRevisions(revisions map[string]int64, keep []string, limit int) []string {
+	expired := make([]string, 0)
+	if limit <= 0 {
+		return expired
+	}
+
+	names := make([]string, 0, len(revisions))
+	for name := range revisions {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return revisions[names[i]] > revisions[names[j]]
+	})
+
+	for i, name := range names {
+		if i < limit || util.SliceContainsString(keep, name) {
+			continue
+		}
+		expired = append(expired, name)
+	}
+	sort.Strings(expired)
+	return expired
+}
@@ -0,0 +1,103 @@
+package tenant
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	corev1alpha1 "github.com/kristofferahl/aeto/apis/core/v1alpha1"
+	"github.com/kristofferahl/aeto/internal/pkg/config"
+	"github.com/kristofferahl/aeto/internal/pkg/kubernetes"
+	"github.com/kristofferahl/aeto/internal/pkg/reconcile"
+)
+
+var _ = Describe("Revisions", func() {
+	spec := corev1alpha1.ResourceTemplateSpec{
+		Raw: []string{"apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\n"},
+	}
+
+	Describe("VerifyRevision", func() {
+		It("should accept an unmodified snapshot", func() {
+			hash, err := RevisionHash(spec)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(VerifyRevision("app-1", hash, spec)).To(Succeed())
+		})
+
+		It("should reject a modified snapshot", func() {
+			hash, err := RevisionHash(spec)
+			Expect(err).NotTo(HaveOccurred())
+
+			modified := *spec.DeepCopy()
+			modified.Raw = append(modified.Raw, "apiVersion: v1\nkind: Secret\nmetadata:\n  name: app\n")
+			Expect(VerifyRevision("app-1", hash, modified)).To(MatchError("revision app-1 has been modified, the snapshot no longer matches hash " + hash))
+		})
+	})
+
+	DescribeTable("ExpiredRevisions",
+		func(keep []string, limit int, expected []string) {
+			revisions := map[string]int64{"a": 1, "b": 2, "c": 3, "d": 4, "e": 5}
+			Expect(ExpiredRevisions(revisions, keep, limit)).To(Equal(expected))
+		},
+		Entry("keeps all without a limit", nil, 0, []string{}),
+		Entry("keeps all within the limit", nil, 5, []string{}),
+		Entry("expires the oldest", nil, 2, []string{"a", "b", "c"}),
+		Entry("keeps pinned revisions", []string{"a", "c"}, 2, []string{"b"}),
+		Entry("keeps the current revision", []string{"b"}, 1, []string{"a", "c", "d"}),
+	)
+
+	Describe("pinned template revisions", func() {
+		var (
+			revision  *corev1alpha1.ResourceTemplateRevision
+			generator func() ResourceGenerator
+		)
+
+		BeforeEach(func() {
+			config.Operator.Namespace = "aeto"
+
+			scheme := runtime.NewScheme()
+			Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+			Expect(corev1alpha1.AddToScheme(scheme)).To(Succeed())
+
+			hash, err := RevisionHash(spec)
+			Expect(err).NotTo(HaveOccurred())
+			revision = &corev1alpha1.ResourceTemplateRevision{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "aeto", Name: RevisionName("app", hash)},
+				Spec: corev1alpha1.ResourceTemplateRevisionSpec{
+					ResourceTemplate: "app",
+					Revision:         1,
+					Hash:             hash,
+					Snapshot:         *spec.DeepCopy(),
+				},
+			}
+
+			generator = func() ResourceGenerator {
+				c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(revision).Build()
+				ctx := reconcile.NewContext("test", ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "aeto", Name: "acme"}}, logf.Log)
+				return NewResourceGenerator(ctx, ResourceGeneratoreServices{Client: kubernetes.NewClient(c, nil, nil)}).DryRun()
+			}
+		})
+
+		It("should render the snapshot of the revision", func() {
+			g := generator()
+			rt, name, err := g.getResourceTemplate(g.ctx, types.NamespacedName{Namespace: "aeto", Name: "app"}, revision.Name)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(name).To(Equal(revision.Name))
+			Expect(rt.Spec).To(Equal(spec))
+		})
+
+		It("should fail when the snapshot of the revision has been modified", func() {
+			revision.Spec.Snapshot.Raw = []string{"apiVersion: v1\nkind: Secret\nmetadata:\n  name: app\n"}
+
+			g := generator()
+			_, _, err := g.getResourceTemplate(g.ctx, types.NamespacedName{Namespace: "aeto", Name: "app"}, revision.Name)
+			Expect(err).To(MatchError(ContainSubstring("revision " + revision.Name + " has been modified")))
+		})
+	})
+})
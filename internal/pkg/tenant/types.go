@@ -5,28 +5,71 @@ import (
 	"fmt"
 
 	"github.com/PaesslerAG/jsonpath"
+	corev1alpha1 "github.com/kristofferahl/aeto/apis/core/v1alpha1"
 	"github.com/kristofferahl/aeto/internal/pkg/common"
 	"github.com/kristofferahl/aeto/internal/pkg/convert"
+	"github.com/kristofferahl/aeto/internal/pkg/util"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// SensitiveDiff replaces the diff of a resource referencing secret parameters
+const SensitiveDiff = "changed, values are hidden as the resource references secret parameters"
+
 type ResourceGroup struct {
 	Name           string       `json:"name"`
 	SourceTemplate string       `json:"sourceTemplate"`
 	Resources      ResourceList `json:"resources"`
+	Outputs        OutputList   `json:"outputs,omitempty"`
 }
 
 type ResourceGroupList []ResourceGroup
 
+// DeferredResourceGroup describes a resource group waiting for the resource groups it depends on to become ready
+type DeferredResourceGroup struct {
+	Name       string   `json:"name"`
+	WaitingFor []string `json:"waitingFor"`
+}
+
 type Resource struct {
-	Id       string           `json:"id"`
-	Order    int              `json:"order"`
-	Sum      string           `json:"sum"`
-	Embedded EmbeddedResource `json:"embedded"`
+	Id    string `json:"id"`
+	Order int    `json:"order"`
+	Sum   string `json:"sum"`
+
+	// Sensitive is true when the resource is rendered from a template referencing secret parameters
+	Sensitive bool `json:"sensitive,omitempty"`
+	// Source is the template a sensitive resource is rendered from when applied
+	Source   *corev1alpha1.ResourceSource `json:"source,omitempty"`
+	Embedded EmbeddedResource             `json:"embedded"`
 }
 
 type ResourceList []Resource
 
+// Output defines a named value read from a resource
+type Output struct {
+	Name       string `json:"name"`
+	ResourceId string `json:"resourceId"`
+	Source     string `json:"source"`
+	JsonPath   string `json:"jsonPath"`
+}
+
+type OutputList []Output
+
+// ResourcePreview describes the changes to the resources of a tenant
+type ResourcePreview struct {
+	Added   []ResourceChange
+	Updated []ResourceChange
+	Removed []ResourceChange
+}
+
+// ResourceChange describes a change to a resource of a tenant
+type ResourceChange struct {
+	Id        string
+	Kind      string
+	Namespace string
+	Name      string
+	Diff      []string
+}
+
 type EmbeddedResource struct {
 	runtime.RawExtension `json:",inline"`
 }
@@ -39,6 +82,14 @@ func (rgl ResourceGroupList) Resources() ResourceList {
 	return rl
 }
 
+func (rgl ResourceGroupList) Outputs() OutputList {
+	ol := OutputList{}
+	for _, rg := range rgl {
+		ol = append(ol, rg.Outputs...)
+	}
+	return ol
+}
+
 func (g *ResourceGroup) JsonPath(path string) (string, error) {
 	bytes, err := json.Marshal(g)
 	if err != nil {
@@ -79,3 +130,48 @@ func (rl ResourceList) Remove(index int) ResourceList {
 func (r Resource) ResourceIdentifier() (common.ResourceIdentifier, error) {
 	return convert.RawExtensionToResourceIdentifier(r.Embedded.RawExtension)
 }
+
+// Retained returns true when the resource should be orphaned instead of deleted
+func (r Resource) Retained() (bool, error) {
+	u, err := convert.RawExtensionToUnstructured(r.Embedded.RawExtension)
+	if err != nil {
+		return false, err
+	}
+	return u.GetAnnotations()[corev1alpha1.AnnotationRetainPolicy] == string(corev1alpha1.RetainPolicyRetain), nil
+}
+
+func newResourceChange(r Resource, existing *Resource) (ResourceChange, error) {
+	desired, err := convert.RawExtensionToUnstructured(r.Embedded.RawExtension)
+	if err != nil {
+		return ResourceChange{}, err
+	}
+
+	current := map[string]interface{}{}
+	if existing != nil {
+		u, err := convert.RawExtensionToUnstructured(existing.Embedded.RawExtension)
+		if err != nil {
+			return ResourceChange{}, err
+		}
+		current = u.Object
+	}
+
+	return ResourceChange{
+		Id:        r.Id,
+		Kind:      desired.GetKind(),
+		Namespace: desired.GetNamespace(),
+		Name:      desired.GetName(),
+		Diff:      sensitiveDiff(r, existing, util.DiffValues(current, desired.Object)),
+	}, nil
+}
+
+// sensitiveDiff hides the values of the diff of a resource referencing secret parameters. Such resources are stored
+// without their content, the sum tells whether the rendered resource changed.
+func sensitiveDiff(r Resource, existing *Resource, diff []string) []string {
+	if !r.Sensitive {
+		return diff
+	}
+	if existing == nil || existing.Sum != r.Sum || len(diff) > 0 {
+		return []string{SensitiveDiff}
+	}
+	return diff
+}
@@ -0,0 +1,247 @@
+package tenant
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	corev1alpha1 "github.com/kristofferahl/aeto/apis/core/v1alpha1"
+	"github.com/kristofferahl/aeto/internal/pkg/template"
+	"github.com/kristofferahl/aeto/internal/pkg/util"
+)
+
+// TemplateSpecFunc returns the spec of a resource template or, when pinned to a revision, the snapshot of the revision
+type TemplateSpecFunc func(name string, revision string) (corev1alpha1.ResourceTemplateSpec, error)
+
+// ValidateResourceTemplate returns the validation errors of a resource template, duplicate or invalid parameters,
+// templates that fails to parse and invalid helm chart sources
+func ValidateResourceTemplate(spec corev1alpha1.ResourceTemplateSpec) []string {
+	errors := make([]string, 0)
+
+	names := make([]string, 0)
+	for _, p := range spec.Parameters {
+		if util.SliceContainsString(names, p.Name) {
+			errors = append(errors, fmt.Sprintf("parameter %s is defined more than once", p.Name))
+		}
+		names = append(names, p.Name)
+		errors = append(errors, validateParameter(*p)...)
+	}
+
+	sources := append([]string{}, spec.Raw...)
+	for _, r := range spec.Resources {
+		sources = append(sources, string(r.Raw))
+	}
+	for i, source := range sources {
+		tmpl, err := template.NewYamlTemplate(source, template.InputFormatJson)
+		if err == nil {
+			err = tmpl.Parse()
+		}
+		if err != nil {
+			errors = append(errors, fmt.Sprintf("template %d is invalid, %v", i+1, err))
+		}
+	}
+
+	if spec.Helm != nil {
+		if (spec.Helm.ConfigMap == nil) == (spec.Helm.Repository == nil) {
+			errors = append(errors, "helm chart source requires one of configMap or repository")
+		}
+		if spec.Helm.Values != "" {
+			tmpl, err := template.NewYamlTemplate(spec.Helm.Values, template.InputFormatYaml)
+			if err == nil {
+				err = tmpl.Parse()
+			}
+			if err != nil {
+				errors = append(errors, fmt.Sprintf("helm values are invalid, %v", err))
+			}
+		}
+	}
+
+	return errors
+}
+
+// ValidateBlueprint validates the templates, parameters and dependencies of the resolved blueprint and returns the
+// parameters each resource group resolves to. Required parameters not set by the blueprint must be set by all tenants.
+func ValidateBlueprint(blueprint corev1alpha1.Blueprint, tenants []corev1alpha1.Tenant, templateSpec TemplateSpecFunc) ([]corev1alpha1.BlueprintResourceGroupStatus, []string) {
+	groups := make([]corev1alpha1.BlueprintResourceGroupStatus, 0)
+	errors := make([]string, 0)
+
+	if _, err := blueprint.OrderedResources(); err != nil {
+		errors = append(errors, err.Error())
+	}
+
+	for _, rg := range blueprint.Spec.Resources {
+		status := corev1alpha1.BlueprintResourceGroupStatus{
+			Name:     rg.Name,
+			Template: rg.Template,
+		}
+
+		spec, err := templateSpec(rg.Template, rg.TemplateRevision)
+		if err != nil {
+			errors = append(errors, fmt.Sprintf("resource group %s: %v", rg.Name, err))
+			groups = append(groups, status)
+			continue
+		}
+
+		for _, e := range ValidateResourceTemplate(spec) {
+			errors = append(errors, fmt.Sprintf("resource group %s: %s", rg.Name, e))
+		}
+
+		parameters, missing := ResolveParameters(spec.Parameters, rg.Parameters)
+		status.Parameters = parameters
+		for _, name := range missing {
+			for _, tenant := range tenants {
+				if !hasParameter(tenant.Spec.Parameters, name) {
+					errors = append(errors, fmt.Sprintf("resource group %s: required parameter %s is not set by tenant %s", rg.Name, name, tenant.NamespacedName().String()))
+				}
+			}
+		}
+
+		for _, pv := range rg.Parameters {
+			p := templateParameter(spec.Parameters, pv.Name)
+			if p == nil {
+				errors = append(errors, fmt.Sprintf("resource group %s: parameter %s is not defined by template %s", rg.Name, pv.Name, rg.Template))
+				continue
+			}
+			if pv.Value != "" {
+				if _, err := p.Parse(pv.Value); err != nil {
+					errors = append(errors, fmt.Sprintf("resource group %s: %v", rg.Name, err))
+				}
+			}
+		}
+
+		groups = append(groups, status)
+	}
+
+	for _, h := range blueprint.Spec.Hooks {
+		spec, err := templateSpec(h.Template, "")
+		if err != nil {
+			errors = append(errors, fmt.Sprintf("hook %s: %v", h.Name, err))
+			continue
+		}
+		for _, e := range ValidateResourceTemplate(spec) {
+			errors = append(errors, fmt.Sprintf("hook %s: %s", h.Name, e))
+		}
+	}
+
+	return groups, errors
+}
+
+// ResolveParameters returns the parameters of a template with the values set by the resource group or the defaults of
+// the template, and the names of required parameters that must be set by tenants
+func ResolveParameters(parameters corev1alpha1.ResourceTemplateParameterList, values []corev1alpha1.ParameterValue) ([]corev1alpha1.BlueprintParameterStatus, []string) {
+	resolved := make([]corev1alpha1.BlueprintParameterStatus, 0)
+	missing := make([]string, 0)
+
+	for _, p := range parameters {
+		status := corev1alpha1.BlueprintParameterStatus{
+			Name:   p.Name,
+			Source: corev1alpha1.ParameterSourceTenant,
+		}
+
+		for _, pv := range values {
+			if pv.Name != p.Name {
+				continue
+			}
+			if pv.Value != "" {
+				status.Value = pv.Value
+				status.Source = corev1alpha1.ParameterSourceBlueprint
+			} else if pv.ValueFrom != nil {
+				status.Source = corev1alpha1.ParameterSourceValueFrom
+			}
+		}
+
+		if status.Source == corev1alpha1.ParameterSourceTenant && p.Default != "" {
+			status.Value = p.Default
+			status.Source = corev1alpha1.ParameterSourceDefault
+		}
+
+		if status.Source == corev1alpha1.ParameterSourceTenant && p.Required {
+			missing = append(missing, p.Name)
+		}
+
+		resolved = append(resolved, status)
+	}
+
+	return resolved, missing
+}
+
+// InheritedTenants returns the tenants with the labels, annotations and parameters of their parent tenants merged into them
+func InheritedTenants(tenants []corev1alpha1.Tenant) []corev1alpha1.Tenant {
+	byName := make(map[types.NamespacedName]corev1alpha1.Tenant)
+	for _, t := range tenants {
+		byName[t.NamespacedName()] = t
+	}
+
+	inherited := make([]corev1alpha1.Tenant, 0)
+	for _, t := range tenants {
+		tenant := t
+		visited := map[string]bool{t.Name: true}
+		for name := t.Spec.Parent; name != "" && !visited[name]; {
+			visited[name] = true
+			parent, ok := byName[types.NamespacedName{Namespace: t.Namespace, Name: name}]
+			if !ok {
+				break
+			}
+			tenant = tenant.InheritFrom(parent)
+			name = parent.Spec.Parent
+		}
+		inherited = append(inherited, tenant)
+	}
+
+	sort.Slice(inherited, func(i, j int) bool {
+		return inherited[i].NamespacedName().String() < inherited[j].NamespacedName().String()
+	})
+	return inherited
+}
+
+func hasParameter(values []corev1alpha1.ParameterValue, name string) bool {
+	for _, pv := range values {
+		if pv.Name == name && (pv.Value != "" || pv.ValueFrom != nil) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateParameter returns the validation errors of a parameter definition, an invalid pattern and enum values or a
+// default value not matching the type and constraints of the parameter
+func validateParameter(p corev1alpha1.Parameter) []string {
+	errors := make([]string, 0)
+
+	if p.Pattern != "" {
+		if _, err := regexp.Compile(p.Pattern); err != nil {
+			return append(errors, fmt.Sprintf("parameter %s has an invalid pattern, %v", p.Name, err))
+		}
+	}
+
+	if p.Minimum != nil && p.Maximum != nil && *p.Minimum > *p.Maximum {
+		errors = append(errors, fmt.Sprintf("parameter %s has a minimum greater than the maximum", p.Name))
+	}
+
+	if p.Type != corev1alpha1.ParameterTypeList && p.Type != corev1alpha1.ParameterTypeObject {
+		for _, e := range p.Enum {
+			if _, err := p.Parse(e); err != nil {
+				errors = append(errors, fmt.Sprintf("enum value %q is invalid, %v", e, err))
+			}
+		}
+	}
+
+	if p.Default != "" {
+		if _, err := p.Parse(p.Default); err != nil {
+			errors = append(errors, fmt.Sprintf("default value is invalid, %v", err))
+		}
+	}
+
+	return errors
+}
+
+func templateParameter(parameters corev1alpha1.ResourceTemplateParameterList, name string) *corev1alpha1.Parameter {
+	for _, p := range parameters {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
@@ -0,0 +1,278 @@
+package tenant
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	corev1alpha1 "github.com/kristofferahl/aeto/apis/core/v1alpha1"
+)
+
+var _ = Describe("Validation", func() {
+	int64Ptr := func(i int64) *int64 {
+		return &i
+	}
+
+	DescribeTable("ValidateResourceTemplate",
+		func(spec corev1alpha1.ResourceTemplateSpec, expected []string) {
+			Expect(ValidateResourceTemplate(spec)).To(Equal(expected))
+		},
+		Entry("valid template",
+			corev1alpha1.ResourceTemplateSpec{
+				Parameters: corev1alpha1.ResourceTemplateParameterList{{Name: "name"}, {Name: "replicas", Type: corev1alpha1.ParameterTypeInt, Default: "2"}},
+				Raw:        []string{"apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: '{{ .String \"name\" }}'\n"},
+			},
+			[]string{},
+		),
+		Entry("duplicate parameter",
+			corev1alpha1.ResourceTemplateSpec{
+				Parameters: corev1alpha1.ResourceTemplateParameterList{{Name: "name"}, {Name: "name"}},
+			},
+			[]string{"parameter name is defined more than once"},
+		),
+		Entry("invalid parameters",
+			corev1alpha1.ResourceTemplateSpec{
+				Parameters: corev1alpha1.ResourceTemplateParameterList{
+					{Name: "pattern", Pattern: "[a-z"},
+					{Name: "range", Type: corev1alpha1.ParameterTypeInt, Minimum: int64Ptr(5), Maximum: int64Ptr(1)},
+					{Name: "enum", Type: corev1alpha1.ParameterTypeInt, Enum: []string{"1", "two"}},
+					{Name: "default", Type: corev1alpha1.ParameterTypeBool, Default: "maybe"},
+				},
+			},
+			[]string{
+				"parameter pattern has an invalid pattern, error parsing regexp: missing closing ]: `[a-z`",
+				"parameter range has a minimum greater than the maximum",
+				"enum value \"two\" is invalid, parameter enum must be an int, got \"two\"",
+				"default value is invalid, parameter default must be a bool, got \"maybe\"",
+			},
+		),
+		Entry("default not matching the constraints",
+			corev1alpha1.ResourceTemplateSpec{
+				Parameters: corev1alpha1.ResourceTemplateParameterList{{Name: "size", Enum: []string{"small", "large"}, Default: "medium"}},
+			},
+			[]string{"default value is invalid, parameter size has value \"medium\" which is not one of [small, large]"},
+		),
+		Entry("template that fails to parse",
+			corev1alpha1.ResourceTemplateSpec{
+				Raw: []string{"apiVersion: v1\nkind: ConfigMap\n", "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: '{{ end }}'\n"},
+			},
+			[]string{"template 2 is invalid, failed to parse template, template: resource:4: unexpected {{end}}"},
+		),
+		Entry("helm chart without source",
+			corev1alpha1.ResourceTemplateSpec{
+				Helm: &corev1alpha1.HelmChartSource{},
+			},
+			[]string{"helm chart source requires one of configMap or repository"},
+		),
+		Entry("helm chart with both sources",
+			corev1alpha1.ResourceTemplateSpec{
+				Helm: &corev1alpha1.HelmChartSource{
+					ConfigMap:  &corev1alpha1.HelmChartConfigMapRef{Name: "chart", Key: "chart.tgz"},
+					Repository: &corev1alpha1.HelmChartRepositoryRef{URL: "https://charts.example.com", Chart: "app", Version: "1.0.0"},
+				},
+			},
+			[]string{"helm chart source requires one of configMap or repository"},
+		),
+		Entry("helm values that fails to parse",
+			corev1alpha1.ResourceTemplateSpec{
+				Helm: &corev1alpha1.HelmChartSource{
+					ConfigMap: &corev1alpha1.HelmChartConfigMapRef{Name: "chart", Key: "chart.tgz"},
+					Values:    "replicas: {{ .Int \"replicas\"",
+				},
+			},
+			[]string{"helm values are invalid, failed to parse template, template: resource:1: unclosed action"},
+		),
+	)
+
+	DescribeTable("ResolveParameters",
+		func(parameters corev1alpha1.ResourceTemplateParameterList, values []corev1alpha1.ParameterValue, expected []corev1alpha1.BlueprintParameterStatus, missing []string) {
+			resolved, m := ResolveParameters(parameters, values)
+			Expect(resolved).To(Equal(expected))
+			Expect(m).To(Equal(missing))
+		},
+		Entry("no parameters", corev1alpha1.ResourceTemplateParameterList{}, []corev1alpha1.ParameterValue{}, []corev1alpha1.BlueprintParameterStatus{}, []string{}),
+		Entry("value set by the blueprint",
+			corev1alpha1.ResourceTemplateParameterList{{Name: "size", Required: true, Default: "small"}},
+			[]corev1alpha1.ParameterValue{{Name: "size", Value: "large"}},
+			[]corev1alpha1.BlueprintParameterStatus{{Name: "size", Value: "large", Source: corev1alpha1.ParameterSourceBlueprint}},
+			[]string{},
+		),
+		Entry("value from",
+			corev1alpha1.ResourceTemplateParameterList{{Name: "password", Required: true}},
+			[]corev1alpha1.ParameterValue{{Name: "password", ValueFrom: &corev1alpha1.ValueRef{SecretKeyRef: &corev1alpha1.KeyValueRef{Name: "db", Namespace: "aeto", Key: "password"}}}},
+			[]corev1alpha1.BlueprintParameterStatus{{Name: "password", Source: corev1alpha1.ParameterSourceValueFrom}},
+			[]string{},
+		),
+		Entry("default value",
+			corev1alpha1.ResourceTemplateParameterList{{Name: "size", Required: true, Default: "small"}},
+			[]corev1alpha1.ParameterValue{{Name: "other", Value: "value"}},
+			[]corev1alpha1.BlueprintParameterStatus{{Name: "size", Value: "small", Source: corev1alpha1.ParameterSourceDefault}},
+			[]string{},
+		),
+		Entry("values set by tenants",
+			corev1alpha1.ResourceTemplateParameterList{{Name: "team", Required: true}, {Name: "owner"}},
+			[]corev1alpha1.ParameterValue{{Name: "team"}},
+			[]corev1alpha1.BlueprintParameterStatus{
+				{Name: "team", Source: corev1alpha1.ParameterSourceTenant},
+				{Name: "owner", Source: corev1alpha1.ParameterSourceTenant},
+			},
+			[]string{"team"},
+		),
+	)
+
+	Describe("ValidateBlueprint", func() {
+		templates := map[string]corev1alpha1.ResourceTemplateSpec{
+			"namespace": {
+				Parameters: corev1alpha1.ResourceTemplateParameterList{
+					{Name: "team", Required: true},
+					{Name: "size", Enum: []string{"small", "large"}, Default: "small"},
+				},
+				Raw: []string{"apiVersion: v1\nkind: Namespace\nmetadata:\n  name: '{{ .String \"team\" }}'\n"},
+			},
+			"invalid": {
+				Raw: []string{"apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: '{{ end }}'\n"},
+			},
+		}
+		templateSpec := func(name string, revision string) (corev1alpha1.ResourceTemplateSpec, error) {
+			if revision != "" {
+				return corev1alpha1.ResourceTemplateSpec{}, fmt.Errorf("template revision %s not found", revision)
+			}
+			spec, ok := templates[name]
+			if !ok {
+				return corev1alpha1.ResourceTemplateSpec{}, fmt.Errorf("template %s not found", name)
+			}
+			return spec, nil
+		}
+
+		tenant := func(name string, parameters ...corev1alpha1.ParameterValue) corev1alpha1.Tenant {
+			return corev1alpha1.Tenant{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "aeto", Name: name},
+				Spec:       corev1alpha1.TenantSpec{Parameters: parameters},
+			}
+		}
+
+		blueprint := func(resources []corev1alpha1.BlueprintResourceGroup, hooks ...corev1alpha1.BlueprintHook) corev1alpha1.Blueprint {
+			return corev1alpha1.Blueprint{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "aeto", Name: "default"},
+				Spec:       corev1alpha1.BlueprintSpec{Resources: resources, Hooks: hooks},
+			}
+		}
+
+		It("should resolve the parameters of valid resource groups", func() {
+			groups, errors := ValidateBlueprint(blueprint([]corev1alpha1.BlueprintResourceGroup{
+				{Name: "namespace", Template: "namespace", Parameters: []corev1alpha1.ParameterValue{{Name: "size", Value: "large"}}},
+			}), []corev1alpha1.Tenant{tenant("a", corev1alpha1.ParameterValue{Name: "team", Value: "a-team"})}, templateSpec)
+
+			Expect(errors).To(BeEmpty())
+			Expect(groups).To(Equal([]corev1alpha1.BlueprintResourceGroupStatus{
+				{
+					Name:     "namespace",
+					Template: "namespace",
+					Parameters: []corev1alpha1.BlueprintParameterStatus{
+						{Name: "team", Source: corev1alpha1.ParameterSourceTenant},
+						{Name: "size", Value: "large", Source: corev1alpha1.ParameterSourceBlueprint},
+					},
+				},
+			}))
+		})
+
+		It("should report the tenants not setting required parameters", func() {
+			_, errors := ValidateBlueprint(blueprint([]corev1alpha1.BlueprintResourceGroup{
+				{Name: "namespace", Template: "namespace"},
+			}), []corev1alpha1.Tenant{
+				tenant("a", corev1alpha1.ParameterValue{Name: "team", Value: "a-team"}),
+				tenant("b"),
+				tenant("c", corev1alpha1.ParameterValue{Name: "team"}),
+			}, templateSpec)
+
+			Expect(errors).To(Equal([]string{
+				"resource group namespace: required parameter team is not set by tenant aeto/b",
+				"resource group namespace: required parameter team is not set by tenant aeto/c",
+			}))
+		})
+
+		It("should report invalid resource groups and hooks", func() {
+			groups, errors := ValidateBlueprint(blueprint([]corev1alpha1.BlueprintResourceGroup{
+				{Name: "missing", Template: "missing"},
+				{Name: "pinned", Template: "namespace", TemplateRevision: "namespace-1"},
+				{Name: "invalid", Template: "invalid"},
+				{Name: "parameters", Template: "namespace", Parameters: []corev1alpha1.ParameterValue{
+					{Name: "team", Value: "a-team"},
+					{Name: "size", Value: "medium"},
+					{Name: "unknown", Value: "value"},
+				}},
+			}, corev1alpha1.BlueprintHook{Name: "cleanup", Phase: corev1alpha1.HookPhasePreDelete, Template: "invalid"}), []corev1alpha1.Tenant{}, templateSpec)
+
+			Expect(errors).To(Equal([]string{
+				"resource group missing: template missing not found",
+				"resource group pinned: template revision namespace-1 not found",
+				"resource group invalid: template 1 is invalid, failed to parse template, template: resource:4: unexpected {{end}}",
+				"resource group parameters: parameter size has value \"medium\" which is not one of [small, large]",
+				"resource group parameters: parameter unknown is not defined by template namespace",
+				"hook cleanup: template 1 is invalid, failed to parse template, template: resource:4: unexpected {{end}}",
+			}))
+			Expect(groups).To(HaveLen(4))
+			Expect(groups[0]).To(Equal(corev1alpha1.BlueprintResourceGroupStatus{Name: "missing", Template: "missing"}))
+		})
+
+		It("should report resource groups that can not be ordered", func() {
+			cyclic := blueprint([]corev1alpha1.BlueprintResourceGroup{
+				{Name: "a", Template: "namespace", DependsOn: []string{"b"}},
+				{Name: "b", Template: "namespace", DependsOn: []string{"a"}},
+			})
+			_, err := cyclic.OrderedResources()
+			Expect(err).To(HaveOccurred())
+
+			_, errors := ValidateBlueprint(cyclic, []corev1alpha1.Tenant{}, templateSpec)
+			Expect(errors).To(Equal([]string{err.Error()}))
+		})
+	})
+
+	Describe("InheritedTenants", func() {
+		tenant := func(name string, parent string, parameters ...corev1alpha1.ParameterValue) corev1alpha1.Tenant {
+			return corev1alpha1.Tenant{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "aeto", Name: name},
+				Spec:       corev1alpha1.TenantSpec{Parent: parent, Parameters: parameters},
+			}
+		}
+		parameters := func(tenants []corev1alpha1.Tenant) map[string][]corev1alpha1.ParameterValue {
+			p := make(map[string][]corev1alpha1.ParameterValue)
+			for _, t := range tenants {
+				p[t.Name] = t.Spec.Parameters
+			}
+			return p
+		}
+
+		It("should merge the parameters of parents and sort the tenants", func() {
+			inherited := InheritedTenants([]corev1alpha1.Tenant{
+				tenant("team", "org", corev1alpha1.ParameterValue{Name: "team", Value: "a-team"}),
+				tenant("org", "", corev1alpha1.ParameterValue{Name: "org", Value: "acme"}, corev1alpha1.ParameterValue{Name: "team", Value: "none"}),
+				tenant("app", "team", corev1alpha1.ParameterValue{Name: "app", Value: "web"}),
+			})
+
+			Expect([]string{inherited[0].Name, inherited[1].Name, inherited[2].Name}).To(Equal([]string{"app", "org", "team"}))
+			Expect(parameters(inherited)).To(Equal(map[string][]corev1alpha1.ParameterValue{
+				"app":  {{Name: "app", Value: "web"}, {Name: "team", Value: "a-team"}, {Name: "org", Value: "acme"}},
+				"org":  {{Name: "org", Value: "acme"}, {Name: "team", Value: "none"}},
+				"team": {{Name: "team", Value: "a-team"}, {Name: "org", Value: "acme"}},
+			}))
+		})
+
+		It("should stop at missing parents and cycles", func() {
+			inherited := InheritedTenants([]corev1alpha1.Tenant{
+				tenant("orphan", "missing", corev1alpha1.ParameterValue{Name: "a", Value: "1"}),
+				tenant("x", "y", corev1alpha1.ParameterValue{Name: "x", Value: "1"}),
+				tenant("y", "x", corev1alpha1.ParameterValue{Name: "y", Value: "2"}),
+			})
+
+			Expect(parameters(inherited)).To(Equal(map[string][]corev1alpha1.ParameterValue{
+				"orphan": {{Name: "a", Value: "1"}},
+				"x":      {{Name: "x", Value: "1"}, {Name: "y", Value: "2"}},
+				"y":      {{Name: "y", Value: "2"}, {Name: "x", Value: "1"}},
+			}))
+		})
+	})
+})
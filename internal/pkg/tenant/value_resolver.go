@@ -10,6 +10,7 @@ import (
 	"github.com/kristofferahl/aeto/internal/pkg/kubernetes"
 	"github.com/kristofferahl/aeto/internal/pkg/reconcile"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 )
@@ -21,6 +22,12 @@ type ValueResolver struct {
 	ResourceGroups    []ResourceGroup
 	Client            kubernetes.Client
 	Context           reconcile.Context
+
+	// Secrets records the values read from Secrets so that resources referencing them are replaced when they change
+	Secrets kubernetes.SecretValues
+
+	// Generated stores the generated values of the tenant
+	Generated *GeneratedValueStore
 }
 
 func (r ValueResolver) Func(vr corev1alpha1.ValueRef) (string, error) {
@@ -34,8 +41,20 @@ func (r ValueResolver) Func(vr corev1alpha1.ValueRef) (string, error) {
 		resolvers = append(resolvers, resolveFromResource)
 	}
 
+	if vr.SecretKeyRef != nil {
+		resolvers = append(resolvers, resolveFromSecret)
+	}
+
+	if vr.ConfigMapKeyRef != nil {
+		resolvers = append(resolvers, resolveFromConfigMap)
+	}
+
+	if vr.Generated != nil {
+		resolvers = append(resolvers, resolveFromGenerated)
+	}
+
 	if len(resolvers) == 0 {
-		return "", fmt.Errorf("invalid value reference, blueprint or resource is required")
+		return "", fmt.Errorf("invalid value reference, blueprint, resource, secretKeyRef, configMapKeyRef or generated is required")
 	}
 
 	if len(resolvers) > 1 {
@@ -46,6 +65,19 @@ func (r ValueResolver) Func(vr corev1alpha1.ValueRef) (string, error) {
 	return resolve(r, vr)
 }
 
+// Reference returns the reference of the Secret key a value is read from, false when the value is neither read from a
+// Secret nor generated
+func (r ValueResolver) Reference(vr corev1alpha1.ValueRef) (kubernetes.SecretReference, bool) {
+	if vr.SecretKeyRef != nil {
+		nn := r.namespacedName(vr.SecretKeyRef.Namespace, vr.SecretKeyRef.Name)
+		return kubernetes.SecretReference{Namespace: nn.Namespace, Name: nn.Name, Key: vr.SecretKeyRef.Key}, true
+	}
+	if vr.Generated != nil && r.Generated != nil {
+		return r.Generated.Reference(*vr.Generated), true
+	}
+	return kubernetes.SecretReference{}, false
+}
+
 // Group returns a resource group matching the specified name
 func (r *ValueResolver) Group(name string) (*ResourceGroup, error) {
 	for _, group := range r.ResourceGroups {
@@ -73,23 +105,9 @@ func resolveFromBlueprint(r ValueResolver, vr corev1alpha1.ValueRef) (string, er
 
 func resolveFromResource(r ValueResolver, vr corev1alpha1.ValueRef) (string, error) {
 	ref := vr.Resource
-	resourceRef := types.NamespacedName{
-		Namespace: ref.Namespace,
-		Name:      ref.Name,
-	}
+	resourceRef := r.namespacedName(ref.Namespace, ref.Name)
 	resourceGvk := schema.FromAPIVersionAndKind(ref.ApiVersion, ref.Kind)
 
-	if resourceRef.Name == "$TENANT_NAME" {
-		resourceRef.Name = r.TenantName
-	}
-
-	if resourceRef.Namespace == "$TENANT_NAMESPACE" {
-		resourceRef.Namespace = r.TenantNamespace
-	}
-	if resourceRef.Namespace == "$OPERATOR_NAMESPACE" {
-		resourceRef.Namespace = r.OperatorNamespace
-	}
-
 	resource, err := r.Client.DynamicGet(r.Context, resourceRef, resourceGvk)
 	if err != nil {
 		return "", fmt.Errorf("invalid value refrence, error fetching resource \"%s\" %s: %v", resourceRef.String(), resourceGvk.String(), err)
@@ -110,3 +128,82 @@ func resolveFromResource(r ValueResolver, vr corev1alpha1.ValueRef) (string, err
 
 	return fmt.Sprintf("%s", value), nil
 }
+
+func resolveFromSecret(r ValueResolver, vr corev1alpha1.ValueRef) (string, error) {
+	ref := vr.SecretKeyRef
+	nn := r.namespacedName(ref.Namespace, ref.Name)
+
+	var secret corev1.Secret
+	if err := r.Client.Get(r.Context, nn, &secret); err != nil {
+		return "", fmt.Errorf("invalid value refrence, error fetching secret \"%s\": %v", nn.String(), err)
+	}
+
+	value, ok := secret.Data[ref.Key]
+	if !ok {
+		return "", fmt.Errorf("invalid value refrence, key \"%s\" not found in secret \"%s\"", ref.Key, nn.String())
+	}
+
+	if r.Secrets != nil {
+		r.Secrets[kubernetes.SecretReference{Namespace: nn.Namespace, Name: nn.Name, Key: ref.Key}] = string(value)
+	}
+
+	return string(value), nil
+}
+
+func resolveFromConfigMap(r ValueResolver, vr corev1alpha1.ValueRef) (string, error) {
+	ref := vr.ConfigMapKeyRef
+	nn := r.namespacedName(ref.Namespace, ref.Name)
+
+	var configMap corev1.ConfigMap
+	if err := r.Client.Get(r.Context, nn, &configMap); err != nil {
+		return "", fmt.Errorf("invalid value refrence, error fetching configmap \"%s\": %v", nn.String(), err)
+	}
+
+	if value, ok := configMap.Data[ref.Key]; ok {
+		return value, nil
+	}
+
+	if value, ok := configMap.BinaryData[ref.Key]; ok {
+		return string(value), nil
+	}
+
+	return "", fmt.Errorf("invalid value refrence, key \"%s\" not found in configmap \"%s\"", ref.Key, nn.String())
+}
+
+func resolveFromGenerated(r ValueResolver, vr corev1alpha1.ValueRef) (string, error) {
+	if r.Generated == nil {
+		return "", fmt.Errorf("invalid value refrence, generated values are not available")
+	}
+
+	value, err := r.Generated.Get(*vr.Generated)
+	if err != nil {
+		return "", err
+	}
+
+	if r.Secrets != nil {
+		r.Secrets[r.Generated.Reference(*vr.Generated)] = value
+	}
+
+	return value, nil
+}
+
+// namespacedName returns a namespaced name with $TENANT_NAME, $TENANT_NAMESPACE and $OPERATOR_NAMESPACE replaced
+func (r ValueResolver) namespacedName(namespace string, name string) types.NamespacedName {
+	nn := types.NamespacedName{
+		Namespace: namespace,
+		Name:      name,
+	}
+
+	if nn.Name == "$TENANT_NAME" {
+		nn.Name = r.TenantName
+	}
+
+	if nn.Namespace == "$TENANT_NAMESPACE" {
+		nn.Namespace = r.TenantNamespace
+	}
+	if nn.Namespace == "$OPERATOR_NAMESPACE" {
+		nn.Namespace = r.OperatorNamespace
+	}
+
+	return nn
+}
@@ -0,0 +1,97 @@
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// DiffFields returns the sorted paths of the fields present in fields where the values of a and b are different
+func DiffFields(fields interface{}, a interface{}, b interface{}) []string {
+	diff := make([]string, 0)
+	diffFields("", fields, a, b, &diff)
+	sort.Strings(diff)
+	return diff
+}
+
+// LimitStrings returns the first max items of the slice, followed by a count of the items left out
+func LimitStrings(slice []string, max int) []string {
+	if len(slice) <= max {
+		return slice
+	}
+	return append(slice[:max:max], fmt.Sprintf("... %d more", len(slice)-max))
+}
+
+// DiffValues returns the sorted changes from a to b, formatted as "+ path: value", "- path: value" or "~ path: old -> new"
+func DiffValues(a interface{}, b interface{}) []string {
+	diff := make([]string, 0)
+	diffValues("", a, b, &diff)
+	sort.Slice(diff, func(i, j int) bool {
+		return diff[i][2:] < diff[j][2:]
+	})
+	return diff
+}
+
+func diffFields(path string, fields interface{}, a interface{}, b interface{}, diff *[]string) {
+	fm, ok := fields.(map[string]interface{})
+	if !ok || len(fm) == 0 {
+		if !reflect.DeepEqual(a, b) {
+			*diff = append(*diff, path)
+		}
+		return
+	}
+
+	am, aok := a.(map[string]interface{})
+	bm, bok := b.(map[string]interface{})
+	if !aok || !bok {
+		if !reflect.DeepEqual(a, b) {
+			*diff = append(*diff, path)
+		}
+		return
+	}
+
+	for k, fv := range fm {
+		av, afound := am[k]
+		bv, bfound := bm[k]
+		if afound != bfound {
+			*diff = append(*diff, path+"."+k)
+			continue
+		}
+		diffFields(path+"."+k, fv, av, bv, diff)
+	}
+}
+
+func diffValues(path string, a interface{}, b interface{}, diff *[]string) {
+	am, aok := a.(map[string]interface{})
+	bm, bok := b.(map[string]interface{})
+	if !aok || !bok {
+		if !reflect.DeepEqual(a, b) {
+			*diff = append(*diff, fmt.Sprintf("~ %s: %s -> %s", path, formatValue(a), formatValue(b)))
+		}
+		return
+	}
+
+	for k, av := range am {
+		bv, found := bm[k]
+		if !found {
+			*diff = append(*diff, fmt.Sprintf("- %s.%s: %s", path, k, formatValue(av)))
+			continue
+		}
+		diffValues(path+"."+k, av, bv, diff)
+	}
+
+	for k, bv := range bm {
+		if _, found := am[k]; !found {
+			*diff = append(*diff, fmt.Sprintf("+ %s.%s: %s", path, k, formatValue(bv)))
+		}
+	}
+}
+
+func formatValue(v interface{}) string {
+	bytes, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(bytes)
+}
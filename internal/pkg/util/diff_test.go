@@ -0,0 +1,41 @@
+package util
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type obj = map[string]interface{}
+
+var _ = Describe("Diff", func() {
+	DescribeTable("DiffFields",
+		func(fields interface{}, a interface{}, b interface{}, expected []string) {
+			Expect(DiffFields(fields, a, b)).To(Equal(expected))
+		},
+		Entry("equal values", obj{"spec": obj{"replicas": 1}}, obj{"spec": obj{"replicas": 1}}, obj{"spec": obj{"replicas": 1}}, []string{}),
+		Entry("changed value", obj{"spec": obj{"replicas": 1}}, obj{"spec": obj{"replicas": 1}}, obj{"spec": obj{"replicas": 2}}, []string{".spec.replicas"}),
+		Entry("fields not in the field set are ignored", obj{"spec": obj{"replicas": 1}}, obj{"spec": obj{"replicas": 1}}, obj{"spec": obj{"replicas": 1, "paused": true}}, []string{}),
+		Entry("missing field", obj{"spec": obj{"replicas": 1}}, obj{"spec": obj{"replicas": 1}}, obj{"spec": obj{}}, []string{".spec.replicas"}),
+		Entry("lists are compared as a whole", obj{"args": []interface{}{"a"}}, obj{"args": []interface{}{"a"}}, obj{"args": []interface{}{"a", "b"}}, []string{".args"}),
+		Entry("sorted paths", obj{"b": 1, "a": 1}, obj{"b": 1, "a": 1}, obj{"b": 2, "a": 2}, []string{".a", ".b"}),
+	)
+
+	DescribeTable("DiffValues",
+		func(a interface{}, b interface{}, expected []string) {
+			Expect(DiffValues(a, b)).To(Equal(expected))
+		},
+		Entry("equal values", obj{"a": "x"}, obj{"a": "x"}, []string{}),
+		Entry("added value", obj{}, obj{"a": "x"}, []string{`+ .a: "x"`}),
+		Entry("removed value", obj{"a": "x"}, obj{}, []string{`- .a: "x"`}),
+		Entry("changed value", obj{"a": obj{"b": 1}}, obj{"a": obj{"b": 2}}, []string{"~ .a.b: 1 -> 2"}),
+		Entry("sorted by path", obj{"b": 1, "c": 1}, obj{"a": 1, "b": 2}, []string{"+ .a: 1", "~ .b: 1 -> 2", "- .c: 1"}),
+	)
+
+	DescribeTable("LimitStrings",
+		func(slice []string, max int, expected []string) {
+			Expect(LimitStrings(slice, max)).To(Equal(expected))
+		},
+		Entry("within the limit", []string{"a", "b"}, 2, []string{"a", "b"}),
+		Entry("exceeding the limit", []string{"a", "b", "c"}, 1, []string{"a", "... 2 more"}),
+	)
+})
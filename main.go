@@ -39,6 +39,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
+	"github.com/kristofferahl/aeto/internal/pkg/api"
 	"github.com/kristofferahl/aeto/internal/pkg/aws"
 	"github.com/kristofferahl/aeto/internal/pkg/config"
 	"github.com/kristofferahl/aeto/internal/pkg/kubernetes"
@@ -82,6 +83,11 @@ func main() {
 	var operatorReconcileInterval time.Duration
 	var operatorEnabledControllers string
 	var operatorMaxTenantResourceSets int
+	var operatorMaxRevisionHistory int
+	var operatorTenantExpiryWarning time.Duration
+	var operatorChartRoot string
+	var operatorTenantApiAddr string
+	var operatorTenantApiTLS api.TLSOptions
 
 	// Kubebuilder flags
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
@@ -96,6 +102,13 @@ func main() {
 	flag.StringVar(&operatorNamespace, "operator-namespace", "aeto", "The operator namespace.")
 	flag.DurationVar(&operatorReconcileInterval, "operator-reconcile-interval", 30*time.Minute, "The interval of the reconciliation loop")
 	flag.IntVar(&operatorMaxTenantResourceSets, "operator-max-tenant-resourcesets", 3, "The maximum number of resourcesets kept for each tenant")
+	flag.IntVar(&operatorMaxRevisionHistory, "operator-max-revision-history", 10, "The maximum number of revisions kept for each blueprint and resource template, pinned revisions are always kept. Set to 0 to keep all revisions.")
+	flag.DurationVar(&operatorTenantExpiryWarning, "operator-tenant-expiry-warning", 24*time.Hour, "The time before expiry when a tenant is marked as expiring")
+	flag.StringVar(&operatorChartRoot, "operator-chart-root", "", "The directory file chart repositories are read from. Leave empty to disable file chart repositories.")
+	flag.StringVar(&operatorTenantApiAddr, "operator-tenant-api-bind-address", "0", "The address the self-service tenant api binds to. Set to 0 to disable the tenant api.")
+	flag.StringVar(&operatorTenantApiTLS.CertFile, "operator-tenant-api-tls-cert-file", "", "The certificate file served by the self-service tenant api.")
+	flag.StringVar(&operatorTenantApiTLS.KeyFile, "operator-tenant-api-tls-key-file", "", "The private key file of the certificate served by the self-service tenant api.")
+	flag.BoolVar(&operatorTenantApiTLS.Insecure, "operator-tenant-api-insecure", false, "Serve the self-service tenant api over plain http, bearer tokens are sent in clear text.")
 
 	// Parse flags
 	flag.Parse()
@@ -105,10 +118,15 @@ func main() {
 	operatorNamespace = config.StringEnvVar("OPERATOR_NAMESPACE", operatorNamespace)
 	operatorReconcileInterval = config.DurationEnvVar("OPERATOR_RECONCILE_INTERVAL", operatorReconcileInterval)
 	operatorMaxTenantResourceSets = config.IntEnvVar("OPERATOR_MAX_TENANT_RESOURCESETS", operatorMaxTenantResourceSets)
+	operatorMaxRevisionHistory = config.IntEnvVar("OPERATOR_MAX_REVISION_HISTORY", operatorMaxRevisionHistory)
+	operatorTenantExpiryWarning = config.DurationEnvVar("OPERATOR_TENANT_EXPIRY_WARNING", operatorTenantExpiryWarning)
+	operatorChartRoot = config.StringEnvVar("OPERATOR_CHART_ROOT", operatorChartRoot)
+	operatorTenantApiAddr = config.StringEnvVar("OPERATOR_TENANT_API_BIND_ADDRESS", operatorTenantApiAddr)
 	operatorEnabledControllers = config.StringEnvVar("OPERATOR_ENABLED_CONTROLLERS", strings.Join([]string{
 		"Tenant",
 		"ResourceTemplate",
 		"Blueprint",
+		"BlueprintPreview",
 		"ResourceSet",
 		"HostedZone",
 		"Certificate",
@@ -130,6 +148,9 @@ func main() {
 		ReconcileInterval:     operatorReconcileInterval,
 		Namespace:             operatorNamespace,
 		MaxTenantResourceSets: operatorMaxTenantResourceSets,
+		MaxRevisionHistory:    operatorMaxRevisionHistory,
+		TenantExpiryWarning:   operatorTenantExpiryWarning,
+		ChartRoot:             operatorChartRoot,
 	}
 
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
@@ -204,6 +225,15 @@ func main() {
 			os.Exit(1)
 		}
 	}
+	if util.SliceContainsString(enabledControllers, "BlueprintPreview") {
+		if err = (&corecontrollers.BlueprintPreviewReconciler{
+			Scheme: mgr.GetScheme(),
+			Client: k8sClient,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "BlueprintPreview")
+			os.Exit(1)
+		}
+	}
 	if util.SliceContainsString(enabledControllers, "ResourceSet") {
 		if err = (&corecontrollers.ResourceSetReconciler{
 			Scheme: mgr.GetScheme(),
@@ -263,6 +293,18 @@ func main() {
 
 	//+kubebuilder:scaffold:builder
 
+	if operatorTenantApiAddr != "0" {
+		srv, err := api.NewServer(operatorTenantApiAddr, operatorTenantApiTLS, mgr.GetClient(), ctrl.Log.WithName("tenant-api"))
+		if err != nil {
+			setupLog.Error(err, "unable to create tenant api")
+			os.Exit(1)
+		}
+		if err := mgr.Add(srv); err != nil {
+			setupLog.Error(err, "unable to create tenant api")
+			os.Exit(1)
+		}
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)